@@ -0,0 +1,80 @@
+package clickhouse
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const clickhouseTimeFmt = "2006-01-02 15:04:05"
+
+// BaseGenerator contains settings specific for ClickHouse
+type BaseGenerator struct {
+	UseTags bool
+}
+
+// GenerateEmptyQuery returns an empty query.ClickHouse.
+func (g *BaseGenerator) GenerateEmptyQuery() query.Query {
+	return query.NewClickHouse()
+}
+
+// fillInQuery fills the query struct with data.
+func (g *BaseGenerator) fillInQuery(qi query.Query, humanLabel, humanDesc, table, sql string) {
+	q := qi.(*query.ClickHouse)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(humanDesc)
+	q.Table = []byte(table)
+	q.SqlQuery = []byte(sql)
+}
+
+// withTags returns the FROM clause that makes the tag columns available next
+// to the columns of table. With a separate tags table it is joined on tags_id,
+// otherwise the tags are already part of table.
+func (g *BaseGenerator) withTags(table string) string {
+	if g.UseTags {
+		return fmt.Sprintf("%[1]s INNER JOIN tags ON %[1]s.tags_id = tags.id", table)
+	}
+	return table
+}
+
+// toDateTime returns a DateTime literal for t. The time zone is explicit so
+// the query does not depend on the time zone of the server.
+func toDateTime(t time.Time) string {
+	return fmt.Sprintf("toDateTime('%s', 'UTC')", t.UTC().Format(clickhouseTimeFmt))
+}
+
+// NewDevops creates a new devops use case query generator.
+func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := devops.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	devops := &Devops{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return devops, nil
+}
+
+// NewIoT creates a new iot use case query generator.
+func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := iot.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	iot := &IoT{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return iot, nil
+}
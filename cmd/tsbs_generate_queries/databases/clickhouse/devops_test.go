@@ -0,0 +1,216 @@
+package clickhouse
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestDevops(t *testing.T, useTags bool, s, e time.Time) *Devops {
+	b := BaseGenerator{UseTags: useTags}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	return dq.(*Devops)
+}
+
+func verifyQuery(t *testing.T, q query.Query, humanLabel, humanDesc, table, sql string) {
+	cq, ok := q.(*query.ClickHouse)
+	if !ok {
+		t.Fatal("Filled query is not *query.ClickHouse type")
+	}
+
+	if got := string(cq.HumanLabel); got != humanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, humanLabel)
+	}
+	if got := string(cq.HumanDescription); got != humanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, humanDesc)
+	}
+	if got := string(cq.Table); got != table {
+		t.Errorf("incorrect table:\ngot\n%s\nwant\n%s", got, table)
+	}
+	if got := string(cq.SqlQuery); got != sql {
+		t.Errorf("incorrect SQL query:\ndiff\n%s\ngot\n%s\nwant\n%s", diff.CharacterDiff(got, sql), got, sql)
+	}
+}
+
+func TestDevopsGetHostWhereWithHostnames(t *testing.T) {
+	cases := []struct {
+		desc      string
+		hostnames []string
+		useTags   bool
+		want      string
+	}{
+		{
+			desc:      "single host - no tags",
+			hostnames: []string{"foo1"},
+			want:      "hostname IN ('foo1')",
+		},
+		{
+			desc:      "multi host - no tags",
+			hostnames: []string{"foo1", "foo2"},
+			want:      "hostname IN ('foo1','foo2')",
+		},
+		{
+			desc:      "multi host - w/ tags",
+			hostnames: []string{"foo1", "foo2"},
+			useTags:   true,
+			want:      "tags_id IN (SELECT id FROM tags WHERE hostname IN ('foo1','foo2'))",
+		},
+	}
+
+	for _, c := range cases {
+		d := newTestDevops(t, c.useTags, time.Now(), time.Now())
+		if got := d.getHostWhereWithHostnames(c.hostnames); got != c.want {
+			t.Errorf("%s: incorrect output: got %s want %s", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestToDateTime(t *testing.T) {
+	ts := time.Date(2016, 1, 1, 1, 2, 3, 500, time.FixedZone("CET", 3600))
+	if got, want := toDateTime(ts), "toDateTime('2016-01-01 00:02:03', 'UTC')"; got != want {
+		t.Errorf("incorrect DateTime literal: got %s want %s", got, want)
+	}
+}
+
+func TestDevopsGroupByTime(t *testing.T) {
+	expectedHumanLabel := "ClickHouse 1 cpu metric(s), random    1 hosts, random 1s by 1m"
+	expectedHumanDesc := "ClickHouse 1 cpu metric(s), random    1 hosts, random 1s by 1m: 1970-01-01T00:05:58Z"
+	expectedSQLQuery := `SELECT toStartOfMinute(time) AS minute,
+        max(usage_user) AS max_usage_user
+        FROM cpu
+        WHERE tags_id IN (SELECT id FROM tags WHERE hostname IN ('host_9')) AND time >= toDateTime('1970-01-01 00:05:58', 'UTC') AND time < toDateTime('1970-01-01 00:05:59', 'UTC')
+        GROUP BY minute ORDER BY minute ASC`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, true, s, s.Add(time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTime(q, 1, 1, time.Second)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, "cpu", expectedSQLQuery)
+}
+
+func TestDevopsGroupByTimeAndPrimaryTag(t *testing.T) {
+	cases := []struct {
+		desc             string
+		useTags          bool
+		expectedSQLQuery string
+	}{
+		{
+			desc:    "w/ tags",
+			useTags: true,
+			expectedSQLQuery: `
+        SELECT hour, hostname, mean_usage_user
+        FROM (
+          SELECT toStartOfHour(time) AS hour, tags_id,
+          avg(usage_user) AS mean_usage_user
+          FROM cpu
+          WHERE time >= toDateTime('1970-01-01 00:16:22', 'UTC') AND time < toDateTime('1970-01-01 12:16:22', 'UTC')
+          GROUP BY hour, tags_id
+        ) AS cpu_avg
+        INNER JOIN tags ON cpu_avg.tags_id = tags.id
+        ORDER BY hour, hostname`,
+		},
+		{
+			desc: "no tags",
+			expectedSQLQuery: `
+        SELECT toStartOfHour(time) AS hour, hostname,
+        avg(usage_user) AS mean_usage_user
+        FROM cpu
+        WHERE time >= toDateTime('1970-01-01 00:16:22', 'UTC') AND time < toDateTime('1970-01-01 12:16:22', 'UTC')
+        GROUP BY hour, hostname
+        ORDER BY hour, hostname`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			s := time.Unix(0, 0)
+			d := newTestDevops(t, c.useTags, s, s.Add(13*time.Hour))
+
+			q := d.GenerateEmptyQuery()
+			d.GroupByTimeAndPrimaryTag(q, 1)
+
+			verifyQuery(t, q,
+				"ClickHouse mean of 1 metrics, all hosts, random 12h0m0s by 1h",
+				"ClickHouse mean of 1 metrics, all hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z",
+				"cpu", c.expectedSQLQuery)
+		})
+	}
+}
+
+func TestDevopsLastPointPerHost(t *testing.T) {
+	cases := []struct {
+		desc             string
+		useTags          bool
+		expectedSQLQuery string
+	}{
+		{
+			desc:             "w/ tags",
+			useTags:          true,
+			expectedSQLQuery: "SELECT * FROM (SELECT * FROM cpu ORDER BY tags_id, time DESC LIMIT 1 BY tags_id) AS c INNER JOIN tags ON c.tags_id = tags.id ORDER BY hostname",
+		},
+		{
+			desc:             "no tags",
+			expectedSQLQuery: "SELECT * FROM cpu ORDER BY hostname, time DESC LIMIT 1 BY hostname",
+		},
+	}
+
+	for _, c := range cases {
+		s := time.Unix(0, 0)
+		d := newTestDevops(t, c.useTags, s, s.Add(time.Hour))
+
+		q := d.GenerateEmptyQuery()
+		d.LastPointPerHost(q)
+
+		verifyQuery(t, q, "ClickHouse last row per host", "ClickHouse last row per host", "cpu", c.expectedSQLQuery)
+	}
+}
+
+func TestDevopsHighCPUForHosts(t *testing.T) {
+	cases := []struct {
+		desc               string
+		nHosts             int
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedSQLQuery   string
+	}{
+		{
+			desc:               "zero hosts",
+			nHosts:             0,
+			expectedHumanLabel: "ClickHouse CPU over threshold, all hosts",
+			expectedHumanDesc:  "ClickHouse CPU over threshold, all hosts: 1970-01-01T00:16:22Z",
+			expectedSQLQuery: "SELECT * FROM cpu WHERE usage_user > 90.0 AND " +
+				"time >= toDateTime('1970-01-01 00:16:22', 'UTC') AND time < toDateTime('1970-01-01 12:16:22', 'UTC')",
+		},
+		{
+			desc:               "one host",
+			nHosts:             1,
+			expectedHumanLabel: "ClickHouse CPU over threshold, 1 host(s)",
+			expectedHumanDesc:  "ClickHouse CPU over threshold, 1 host(s): 1970-01-01T00:54:10Z",
+			expectedSQLQuery: "SELECT * FROM cpu WHERE usage_user > 90.0 AND " +
+				"time >= toDateTime('1970-01-01 00:54:10', 'UTC') AND time < toDateTime('1970-01-01 12:54:10', 'UTC') AND hostname IN ('host_5')",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			s := time.Unix(0, 0)
+			d := newTestDevops(t, false, s, s.Add(13*time.Hour))
+
+			q := d.GenerateEmptyQuery()
+			d.HighCPUForHosts(q, c.nHosts)
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, "cpu", c.expectedSQLQuery)
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// inFrameWindow is the window of lagInFrame/leadInFrame. The whole partition
+// is used as frame so both behave like lag/lead in PostgreSQL.
+const inFrameWindow = "(PARTITION BY name ORDER BY ten_minutes ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)"
+
+// IoT produces ClickHouse-specific queries for all the iot query types.
+type IoT struct {
+	*iot.Core
+	*BaseGenerator
+}
+
+// NewIoT makes an IoT object ready to generate Queries.
+func NewIoT(start, end time.Time, scale int, g *BaseGenerator) *IoT {
+	c, err := iot.NewCore(start, end, scale)
+	panicIfErr(err)
+	return &IoT{
+		Core:          c,
+		BaseGenerator: g,
+	}
+}
+
+func (i *IoT) getTrucksWhereWithNames(names []string) string {
+	nameClauses := make([]string, len(names))
+	for j, s := range names {
+		nameClauses[j] = fmt.Sprintf("'%s'", s)
+	}
+	return fmt.Sprintf("name IN (%s)", strings.Join(nameClauses, ","))
+}
+
+// getTruckWhereString gets multiple random truck names and creates a WHERE SQL statement for these names.
+func (i *IoT) getTruckWhereString(nTrucks int) string {
+	names, err := i.GetRandomTrucks(nTrucks)
+	panicIfErr(err)
+	return i.getTrucksWhereWithNames(names)
+}
+
+// LastLocByTruck finds the truck location for nTrucks.
+func (i *IoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	sql := fmt.Sprintf(`SELECT name, driver, longitude, latitude
+		FROM %s
+		WHERE %s
+		ORDER BY time DESC
+		LIMIT 1 BY name`,
+		i.withTags(iot.ReadingsTableName),
+		i.getTruckWhereString(nTrucks))
+
+	humanLabel := "ClickHouse last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// LastLocPerTruck finds all the truck locations along with truck and driver names.
+func (i *IoT) LastLocPerTruck(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT name, driver, longitude, latitude
+		FROM %s
+		WHERE name != '' AND fleet = '%s'
+		ORDER BY time DESC
+		LIMIT 1 BY name`,
+		i.withTags(iot.ReadingsTableName),
+		i.GetRandomFleet())
+
+	humanLabel := "ClickHouse last location per truck"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLowFuel finds all trucks with low fuel (less than 10%).
+func (i *IoT) TrucksWithLowFuel(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT name, driver, fuel_state
+		FROM (
+			SELECT name, driver, fuel_state
+			FROM %s
+			WHERE name != '' AND fleet = '%s'
+			ORDER BY time DESC
+			LIMIT 1 BY name)
+		WHERE fuel_state < 0.1`,
+		i.withTags(iot.DiagnosticsTableName),
+		i.GetRandomFleet())
+
+	humanLabel := "ClickHouse trucks with low fuel"
+	humanDesc := fmt.Sprintf("%s: under 10 percent", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// TrucksWithHighLoad finds all trucks that have load over 90%.
+func (i *IoT) TrucksWithHighLoad(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT name, driver, current_load, load_capacity
+		FROM (
+			SELECT name, driver, current_load, load_capacity
+			FROM %s
+			WHERE name != '' AND fleet = '%s'
+			ORDER BY time DESC
+			LIMIT 1 BY name)
+		WHERE current_load / load_capacity > 0.9`,
+		i.withTags(iot.DiagnosticsTableName),
+		i.GetRandomFleet())
+
+	humanLabel := "ClickHouse trucks with high load"
+	humanDesc := fmt.Sprintf("%s: over 90 percent", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// StationaryTrucks finds all trucks that have low average velocity in a time window.
+func (i *IoT) StationaryTrucks(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	sql := fmt.Sprintf(`SELECT name, driver
+		FROM %s
+		WHERE time >= %s AND time < %s
+		AND name != '' AND fleet = '%s'
+		GROUP BY name, driver
+		HAVING avg(velocity) < 1`,
+		i.withTags(iot.ReadingsTableName),
+		toDateTime(interval.Start()),
+		toDateTime(interval.End()),
+		i.GetRandomFleet())
+
+	humanLabel := "ClickHouse stationary trucks"
+	humanDesc := fmt.Sprintf("%s: with low avg velocity in last 10 minutes", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// drivingSessionsSQL returns the names and drivers of the trucks of a random
+// fleet that were driving in more than tenMinutes 10 minute periods within
+// the duration.
+func (i *IoT) drivingSessionsSQL(duration time.Duration, tenMinutes int) string {
+	interval := i.Interval.MustRandWindow(duration)
+	return fmt.Sprintf(`SELECT name, driver
+		FROM (
+			SELECT name, driver, toStartOfTenMinutes(time) AS ten_minutes
+			FROM %s
+			WHERE time >= %s AND time < %s
+			AND name != '' AND fleet = '%s'
+			GROUP BY name, driver, ten_minutes
+			HAVING avg(velocity) > 1)
+		GROUP BY name, driver
+		HAVING count(ten_minutes) > %d`,
+		i.withTags(iot.ReadingsTableName),
+		toDateTime(interval.Start()),
+		toDateTime(interval.End()),
+		i.GetRandomFleet(),
+		tenMinutes)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks that have not stopped at least 20 mins in the last 4 hours.
+func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+	sql := i.drivingSessionsSQL(iot.LongDrivingSessionDuration, tenMinutePeriods(5, iot.LongDrivingSessionDuration))
+
+	humanLabel := "ClickHouse trucks with longer driving sessions"
+	humanDesc := fmt.Sprintf("%s: stopped less than 20 mins in 4 hour period", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TrucksWithLongDailySessions finds all trucks that have driven more than 10 hours in the last 24 hours.
+func (i *IoT) TrucksWithLongDailySessions(qi query.Query) {
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+	sql := i.drivingSessionsSQL(iot.DailyDrivingDuration, tenMinutePeriods(35, iot.DailyDrivingDuration))
+
+	humanLabel := "ClickHouse trucks with longer daily sessions"
+	humanDesc := fmt.Sprintf("%s: drove more than 10 hours in the last 24 hours", humanLabel)
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet.
+func (i *IoT) AvgVsProjectedFuelConsumption(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT fleet, avg(fuel_consumption) AS avg_fuel_consumption,
+		avg(nominal_fuel_consumption) AS projected_fuel_consumption
+		FROM %s
+		WHERE velocity > 1
+		AND name != '' AND fleet != ''
+		AND nominal_fuel_consumption IS NOT NULL
+		GROUP BY fleet`,
+		i.withTags(iot.ReadingsTableName))
+
+	humanLabel := "ClickHouse average vs projected fuel consumption per fleet"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgDailyDrivingDuration finds the average driving duration per driver.
+func (i *IoT) AvgDailyDrivingDuration(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT fleet, name, driver, avg(hours) AS avg_daily_hours
+		FROM (
+			SELECT fleet, name, driver, toStartOfDay(ten_minutes) AS day, count(*) / 6 AS hours
+			FROM (
+				SELECT fleet, name, driver, toStartOfTenMinutes(time) AS ten_minutes
+				FROM %s
+				GROUP BY fleet, name, driver, ten_minutes
+				HAVING avg(velocity) > 1)
+			GROUP BY fleet, name, driver, day)
+		GROUP BY fleet, name, driver`,
+		i.withTags(iot.ReadingsTableName))
+
+	humanLabel := "ClickHouse average driver driving duration per day"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgDailyDrivingSession finds the average driving session without stopping per driver per day.
+func (i *IoT) AvgDailyDrivingSession(qi query.Query) {
+	// The last status change of a truck has no following change and thus
+	// no stop, leadInFrame returns the epoch for it, which is filtered out.
+	sql := fmt.Sprintf(`SELECT name, toStartOfDay(start) AS day, avg(stop - start) AS duration
+		FROM (
+			SELECT name, ten_minutes AS start, leadInFrame(ten_minutes) OVER %[2]s AS stop, driving
+			FROM (
+				SELECT name, ten_minutes, driving, lagInFrame(driving, 1, driving) OVER %[2]s AS prev_driving
+				FROM (
+					SELECT name, toStartOfTenMinutes(time) AS ten_minutes, avg(velocity) > 5 AS driving
+					FROM %[1]s
+					WHERE name != ''
+					GROUP BY name, ten_minutes))
+			WHERE driving != prev_driving)
+		WHERE driving = 1 AND stop > start
+		GROUP BY name, day
+		ORDER BY name, day`,
+		i.withTags(iot.ReadingsTableName),
+		inFrameWindow)
+
+	humanLabel := "ClickHouse average driver driving session without stopping per day"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// AvgLoad finds the average load per truck model per fleet.
+func (i *IoT) AvgLoad(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT fleet, model, load_capacity, avg(avg_load / load_capacity) AS avg_load_percentage
+		FROM (
+			SELECT fleet, model, load_capacity, name, avg(current_load) AS avg_load
+			FROM %s
+			WHERE name != ''
+			GROUP BY fleet, model, load_capacity, name)
+		GROUP BY fleet, model, load_capacity`,
+		i.withTags(iot.DiagnosticsTableName))
+
+	humanLabel := "ClickHouse average load per truck model per fleet"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// DailyTruckActivity returns the number of hours trucks has been active (not out-of-commission) per day per fleet per model.
+func (i *IoT) DailyTruckActivity(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT fleet, model, day, sum(ten_mins_per_day) / 144 AS daily_activity
+		FROM (
+			SELECT fleet, model, name, toStartOfDay(time) AS day, toStartOfTenMinutes(time) AS ten_minutes, count(*) AS ten_mins_per_day
+			FROM %s
+			WHERE name != ''
+			GROUP BY fleet, model, name, day, ten_minutes
+			HAVING avg(status) < 1)
+		GROUP BY fleet, model, day
+		ORDER BY day`,
+		i.withTags(iot.DiagnosticsTableName))
+
+	humanLabel := "ClickHouse daily truck activity per fleet per model"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
+
+// TruckBreakdownFrequency calculates the amount of times a truck model broke down in the last period.
+func (i *IoT) TruckBreakdownFrequency(qi query.Query) {
+	sql := fmt.Sprintf(`SELECT model, count(*)
+		FROM (
+			SELECT model, broken_down, leadInFrame(broken_down) OVER %[2]s AS next_broken_down
+			FROM (
+				SELECT model, name, toStartOfTenMinutes(time) AS ten_minutes, countIf(status = 0) / count(*) >= 0.5 AS broken_down
+				FROM %[1]s
+				WHERE name != ''
+				GROUP BY model, name, ten_minutes))
+		WHERE broken_down = 0 AND next_broken_down = 1
+		GROUP BY model`,
+		i.withTags(iot.DiagnosticsTableName),
+		inFrameWindow)
+
+	humanLabel := "ClickHouse truck breakdown frequency per model"
+	humanDesc := humanLabel
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.DiagnosticsTableName, sql)
+}
+
+// tenMinutePeriods calculates the number of 10 minute periods that can fit in
+// the time duration if we subtract the minutes specified by minutesPerHour value.
+// E.g.: 4 hours - 5 minutes per hour = 3 hours and 40 minutes = 22 ten minute periods
+func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
+	durationMinutes := duration.Minutes()
+	leftover := minutesPerHour * duration.Hours()
+	return int((durationMinutes - leftover) / 10)
+}
@@ -0,0 +1,76 @@
+package clickhouse
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func newTestIoT(t *testing.T, useTags bool) *IoT {
+	b := BaseGenerator{UseTags: useTags}
+	s := time.Unix(0, 0)
+	iq, err := b.NewIoT(s, s.Add(25*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("Error while creating iot generator")
+	}
+	return iq.(*IoT)
+}
+
+func TestWithTags(t *testing.T) {
+	if got, want := newTestIoT(t, true).withTags("readings"), "readings INNER JOIN tags ON readings.tags_id = tags.id"; got != want {
+		t.Errorf("incorrect FROM clause with tags: got %s want %s", got, want)
+	}
+	if got, want := newTestIoT(t, false).withTags("readings"), "readings"; got != want {
+		t.Errorf("incorrect FROM clause without tags: got %s want %s", got, want)
+	}
+}
+
+func TestLastLocByTruck(t *testing.T) {
+	expectedSQLQuery := `SELECT name, driver, longitude, latitude
+		FROM readings INNER JOIN tags ON readings.tags_id = tags.id
+		WHERE name IN ('truck_5')
+		ORDER BY time DESC
+		LIMIT 1 BY name`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	i := newTestIoT(t, true)
+	q := i.GenerateEmptyQuery()
+	i.LastLocByTruck(q, 1)
+
+	verifyQuery(t, q,
+		"ClickHouse last location by specific truck",
+		"ClickHouse last location by specific truck: random    1 trucks",
+		"readings", expectedSQLQuery)
+}
+
+func TestTrucksWithLongDrivingSessions(t *testing.T) {
+	expectedSQLQuery := `SELECT name, driver
+		FROM (
+			SELECT name, driver, toStartOfTenMinutes(time) AS ten_minutes
+			FROM readings
+			WHERE time >= toDateTime('1970-01-01 00:16:22', 'UTC') AND time < toDateTime('1970-01-01 04:16:22', 'UTC')
+			AND name != '' AND fleet = 'West'
+			GROUP BY name, driver, ten_minutes
+			HAVING avg(velocity) > 1)
+		GROUP BY name, driver
+		HAVING count(ten_minutes) > 22`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	i := newTestIoT(t, false)
+	q := i.GenerateEmptyQuery()
+	i.TrucksWithLongDrivingSessions(q)
+
+	verifyQuery(t, q,
+		"ClickHouse trucks with longer driving sessions",
+		"ClickHouse trucks with longer driving sessions: stopped less than 20 mins in 4 hour period",
+		"readings", expectedSQLQuery)
+}
+
+func TestTenMinutePeriods(t *testing.T) {
+	if got := tenMinutePeriods(5, 4*time.Hour); got != 22 {
+		t.Errorf("incorrect number of periods: got %d want 22", got)
+	}
+	if got := tenMinutePeriods(35, 24*time.Hour); got != 60 {
+		t.Errorf("incorrect number of periods: got %d want 60", got)
+	}
+}
@@ -3,16 +3,25 @@ package influx
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	internalutils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
+// fluxQueryPath is the InfluxDB 2.x endpoint Flux queries are sent to.
+const fluxQueryPath = "/api/v2/query"
+
 // BaseGenerator contains settings specific for Influx database.
 type BaseGenerator struct {
+	// UseFlux makes the generator produce Flux instead of InfluxQL queries.
+	UseFlux bool
+	// Bucket is the bucket Flux queries read from.
+	Bucket string
 }
 
 // GenerateEmptyQuery returns an empty query.HTTP.
@@ -33,6 +42,36 @@ func (g *BaseGenerator) fillInQuery(qi query.Query, humanLabel, humanDesc, influ
 	q.Body = nil
 }
 
+// fillInFluxQuery fills the query struct with a Flux query that is sent as the
+// body of a POST to the /api/v2/query endpoint.
+func (g *BaseGenerator) fillInFluxQuery(qi query.Query, humanLabel, humanDesc, flux string) {
+	q := qi.(*query.HTTP)
+	q.HumanLabel = []byte(humanLabel)
+	q.RawQuery = []byte(flux)
+	q.HumanDescription = []byte(humanDesc)
+	q.Method = []byte("POST")
+	q.Path = []byte(fluxQueryPath)
+	q.Body = []byte(flux)
+}
+
+// fluxFromInterval returns the common head of every query: the bucket, the
+// time range of the given interval and the measurement filter. Flux requires a
+// range, so queries without a time bound in InfluxQL use the whole dataset.
+func (g *BaseGenerator) fluxFromInterval(measurement string, interval *internalutils.TimeInterval) string {
+	return fmt.Sprintf(`from(bucket: "%s")
+	|> range(start: %s, stop: %s)
+	|> filter(fn: (r) => r._measurement == "%s")`, g.Bucket, interval.StartString(), interval.EndString(), measurement)
+}
+
+// fluxOrFilter returns a filter step matching any of the given values of column.
+func fluxOrFilter(column string, values []string) string {
+	clauses := make([]string, len(values))
+	for i, v := range values {
+		clauses[i] = fmt.Sprintf(`r.%s == "%s"`, column, v)
+	}
+	return fmt.Sprintf("\n\t|> filter(fn: (r) => %s)", strings.Join(clauses, " or "))
+}
+
 // NewDevops creates a new devops use case query generator.
 func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
 	core, err := devops.NewCore(start, end, scale)
@@ -41,6 +80,13 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 		return nil, err
 	}
 
+	if g.UseFlux {
+		return &FluxDevops{
+			BaseGenerator: g,
+			Core:          core,
+		}, nil
+	}
+
 	devops := &Devops{
 		BaseGenerator: g,
 		Core:          core,
@@ -57,6 +103,13 @@ func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGene
 		return nil, err
 	}
 
+	if g.UseFlux {
+		return &FluxIoT{
+			BaseGenerator: g,
+			Core:          core,
+		}, nil
+	}
+
 	devops := &IoT{
 		BaseGenerator: g,
 		Core:          core,
@@ -0,0 +1,158 @@
+package influx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// FluxDevops produces Flux queries for all the devops query types.
+type FluxDevops struct {
+	*BaseGenerator
+	*devops.Core
+}
+
+func (d *FluxDevops) getHostFilterWithHostnames(hostnames []string) string {
+	return fluxOrFilter("hostname", hostnames)
+}
+
+func (d *FluxDevops) getHostFilter(nHosts int) string {
+	hostnames, err := d.GetRandomHosts(nHosts)
+	databases.PanicIfErr(err)
+	return d.getHostFilterWithHostnames(hostnames)
+}
+
+// GroupByTime selects the MAX for numMetrics metrics under 'cpu',
+// per minute for nhosts hosts,
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $HOUR_START, stop: $HOUR_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu")
+//	  |> filter(fn: (r) => r._field == "$METRIC_1" or ... or r._field == "$METRIC_N")
+//	  |> filter(fn: (r) => r.hostname == "$HOSTNAME_1" or ... or r.hostname == "$HOSTNAME_N")
+//	  |> group(columns: ["_field"])
+//	  |> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+func (d *FluxDevops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
+	interval := d.Interval.MustRandWindow(timeRange)
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+
+	humanLabel := fmt.Sprintf("Influx Flux %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := d.fluxFromInterval(devops.TableName, interval) +
+		fluxOrFilter("_field", metrics) +
+		d.getHostFilter(nHosts) + `
+	|> group(columns: ["_field"])
+	|> aggregateWindow(every: 1m, fn: max, createEmpty: false)`
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// GroupByOrderByLimit benchmarks a query that has a time WHERE clause, that groups by a truncated date, orders by that date, and takes a limit:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $TIME - 1h, stop: $TIME)
+//	  |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage_user")
+//	  |> group()
+//	  |> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+//	  |> sort(columns: ["_time"], desc: true)
+//	  |> limit(n: 5)
+func (d *FluxDevops) GroupByOrderByLimit(qi query.Query) {
+	interval := d.Interval.MustRandWindow(time.Hour)
+
+	humanLabel := "Influx Flux max cpu over last 5 min-intervals (random end)"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := d.fluxFromInterval(devops.TableName, interval) + `
+	|> filter(fn: (r) => r._field == "usage_user")
+	|> group()
+	|> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+	|> sort(columns: ["_time"], desc: true)
+	|> limit(n: 5)`
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// GroupByTimeAndPrimaryTag selects the AVG of numMetrics metrics under 'cpu' per device per hour for a day,
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $HOUR_START, stop: $HOUR_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu")
+//	  |> filter(fn: (r) => r._field == "$METRIC_1" or ... or r._field == "$METRIC_N")
+//	  |> group(columns: ["_field", "hostname"])
+//	  |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+func (d *FluxDevops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+
+	humanLabel := devops.GetDoubleGroupByLabel("Influx Flux", numMetrics)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := d.fluxFromInterval(devops.TableName, interval) +
+		fluxOrFilter("_field", metrics) + `
+	|> group(columns: ["_field", "hostname"])
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)`
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// MaxAllCPU selects the MAX of all metrics under 'cpu' per hour for nhosts hosts,
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $HOUR_START, stop: $HOUR_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu")
+//	  |> filter(fn: (r) => r.hostname == "$HOSTNAME_1" or ... or r.hostname == "$HOSTNAME_N")
+//	  |> group(columns: ["_field"])
+//	  |> aggregateWindow(every: 1h, fn: max, createEmpty: false)
+func (d *FluxDevops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+
+	humanLabel := devops.GetMaxAllLabel("Influx Flux", nHosts)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := d.fluxFromInterval(devops.TableName, interval) +
+		d.getHostFilter(nHosts) + `
+	|> group(columns: ["_field"])
+	|> aggregateWindow(every: 1h, fn: max, createEmpty: false)`
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// LastPointPerHost finds the last row for every host in the dataset
+func (d *FluxDevops) LastPointPerHost(qi query.Query) {
+	humanLabel := "Influx Flux last row per host"
+	humanDesc := humanLabel + ": cpu"
+	flux := d.fluxFromInterval(devops.TableName, d.Interval) + `
+	|> group(columns: ["hostname", "_field"])
+	|> last()
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")`
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// HighCPUForHosts populates a query that gets CPU metrics when the CPU has high
+// usage between a time period for a number of hosts (if 0, it will search all hosts),
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $TIME_START, stop: $TIME_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu")
+//	  |> filter(fn: (r) => r.hostname == "$HOST" or r.hostname == "$HOST2"...)
+//	  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+//	  |> filter(fn: (r) => r.usage_user > 90.0)
+func (d *FluxDevops) HighCPUForHosts(qi query.Query, nHosts int) {
+	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+
+	var hostFilter string
+	if nHosts > 0 {
+		hostFilter = d.getHostFilter(nHosts)
+	}
+
+	humanLabel, err := devops.GetHighCPULabel("Influx Flux", nHosts)
+	databases.PanicIfErr(err)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := d.fluxFromInterval(devops.TableName, interval) +
+		hostFilter + `
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> filter(fn: (r) => r.usage_user > 90.0)`
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
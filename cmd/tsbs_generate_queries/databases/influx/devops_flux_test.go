@@ -0,0 +1,116 @@
+package influx
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestFluxDevops(t *testing.T, s, e time.Time) *FluxDevops {
+	b := BaseGenerator{UseFlux: true, Bucket: "benchmark"}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	d, ok := dq.(*FluxDevops)
+	if !ok {
+		t.Fatalf("UseFlux generator is not *FluxDevops: %T", dq)
+	}
+	return d
+}
+
+func verifyFluxQuery(t *testing.T, q query.Query, humanLabel, humanDesc, flux string) {
+	fq, ok := q.(*query.HTTP)
+	if !ok {
+		t.Fatal("Filled query is not *query.HTTP type")
+	}
+
+	if got := string(fq.HumanLabel); got != humanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, humanLabel)
+	}
+	if got := string(fq.HumanDescription); got != humanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, humanDesc)
+	}
+	if got := string(fq.Method); got != "POST" {
+		t.Errorf("incorrect method:\ngot\n%s\nwant POST", got)
+	}
+	if got := string(fq.Path); got != fluxQueryPath {
+		t.Errorf("incorrect path:\ngot\n%s\nwant\n%s", got, fluxQueryPath)
+	}
+	if got := string(fq.Body); got != flux {
+		t.Errorf("incorrect body:\ngot\n%s\nwant\n%s", got, flux)
+	}
+	if got := string(fq.RawQuery); got != flux {
+		t.Errorf("incorrect raw query:\ngot\n%s\nwant\n%s", got, flux)
+	}
+}
+
+func TestFluxDevopsGroupByOrderByLimit(t *testing.T) {
+	expectedHumanLabel := "Influx Flux max cpu over last 5 min-intervals (random end)"
+	expectedHumanDesc := "Influx Flux max cpu over last 5 min-intervals (random end): 1970-01-01T00:16:22Z"
+	expectedFlux := `from(bucket: "benchmark")
+	|> range(start: 1970-01-01T00:16:22Z, stop: 1970-01-01T01:16:22Z)
+	|> filter(fn: (r) => r._measurement == "cpu")
+	|> filter(fn: (r) => r._field == "usage_user")
+	|> group()
+	|> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+	|> sort(columns: ["_time"], desc: true)
+	|> limit(n: 5)`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestFluxDevops(t, s, s.Add(2*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByOrderByLimit(q)
+
+	verifyFluxQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedFlux)
+}
+
+func TestFluxDevopsGroupByTimeAndPrimaryTag(t *testing.T) {
+	expectedHumanLabel := "Influx Flux mean of 2 metrics, all hosts, random 12h0m0s by 1h"
+	expectedHumanDesc := "Influx Flux mean of 2 metrics, all hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z"
+	expectedFlux := `from(bucket: "benchmark")
+	|> range(start: 1970-01-01T00:16:22Z, stop: 1970-01-01T12:16:22Z)
+	|> filter(fn: (r) => r._measurement == "cpu")
+	|> filter(fn: (r) => r._field == "usage_user" or r._field == "usage_system")
+	|> group(columns: ["_field", "hostname"])
+	|> aggregateWindow(every: 1h, fn: mean, createEmpty: false)`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestFluxDevops(t, s, s.Add(13*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTimeAndPrimaryTag(q, 2)
+
+	verifyFluxQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedFlux)
+}
+
+func TestFluxDevopsLastPointPerHost(t *testing.T) {
+	expectedHumanLabel := "Influx Flux last row per host"
+	expectedHumanDesc := "Influx Flux last row per host: cpu"
+	expectedFlux := `from(bucket: "benchmark")
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T01:00:00Z)
+	|> filter(fn: (r) => r._measurement == "cpu")
+	|> group(columns: ["hostname", "_field"])
+	|> last()
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")`
+
+	s := time.Unix(0, 0).UTC()
+	d := newTestFluxDevops(t, s, s.Add(time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.LastPointPerHost(q)
+
+	verifyFluxQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedFlux)
+}
+
+func TestFluxOrFilter(t *testing.T) {
+	want := "\n\t|> filter(fn: (r) => r.hostname == \"host_1\" or r.hostname == \"host_2\")"
+	if got := fluxOrFilter("hostname", []string{"host_1", "host_2"}); got != want {
+		t.Errorf("incorrect filter:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,255 @@
+package influx
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// FluxIoT produces Flux queries for all the iot query types.
+type FluxIoT struct {
+	*iot.Core
+	*BaseGenerator
+}
+
+func (i *FluxIoT) getTruckFilter(nTrucks int) string {
+	names, err := i.GetRandomTrucks(nTrucks)
+	databases.PanicIfErr(err)
+	return fluxOrFilter("name", names)
+}
+
+func (i *FluxIoT) getFleetFilter() string {
+	return fluxOrFilter("fleet", []string{i.GetRandomFleet()})
+}
+
+// fluxLastLocation is the tail shared by the last location queries.
+const fluxLastLocation = `
+	|> filter(fn: (r) => r._field == "latitude" or r._field == "longitude")
+	|> last()
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> keep(columns: ["name", "driver", "latitude", "longitude"])`
+
+// LastLocByTruck finds the truck location for nTrucks.
+func (i *FluxIoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	flux := i.fluxFromInterval(iot.ReadingsTableName, i.Interval) +
+		i.getTruckFilter(nTrucks) +
+		fluxLastLocation
+
+	humanLabel := "Influx Flux last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// LastLocPerTruck finds all the truck locations along with truck and driver names.
+func (i *FluxIoT) LastLocPerTruck(qi query.Query) {
+	flux := i.fluxFromInterval(iot.ReadingsTableName, i.Interval) +
+		i.getFleetFilter() +
+		fluxLastLocation
+
+	humanLabel := "Influx Flux last location per truck"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TrucksWithLowFuel finds all trucks with low fuel (less than 10%).
+func (i *FluxIoT) TrucksWithLowFuel(qi query.Query) {
+	flux := i.fluxFromInterval(iot.DiagnosticsTableName, i.Interval) +
+		i.getFleetFilter() + `
+	|> filter(fn: (r) => r._field == "fuel_state")
+	|> last()
+	|> filter(fn: (r) => r._value <= 0.1)
+	|> rename(columns: {_value: "fuel_state"})
+	|> keep(columns: ["name", "driver", "fuel_state"])`
+
+	humanLabel := "Influx Flux trucks with low fuel"
+	humanDesc := fmt.Sprintf("%s: under 10 percent", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TrucksWithHighLoad finds all trucks that have load over 90%.
+func (i *FluxIoT) TrucksWithHighLoad(qi query.Query) {
+	flux := i.fluxFromInterval(iot.DiagnosticsTableName, i.Interval) +
+		i.getFleetFilter() + `
+	|> filter(fn: (r) => r._field == "current_load" or r._field == "load_capacity")
+	|> last()
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> filter(fn: (r) => r.current_load >= 0.9 * r.load_capacity)
+	|> keep(columns: ["name", "driver", "current_load", "load_capacity"])`
+
+	humanLabel := "Influx Flux trucks with high load"
+	humanDesc := fmt.Sprintf("%s: over 90 percent", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// StationaryTrucks finds all trucks that have low average velocity in a time window.
+func (i *FluxIoT) StationaryTrucks(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	flux := i.fluxFromInterval(iot.ReadingsTableName, interval) +
+		i.getFleetFilter() + `
+	|> filter(fn: (r) => r._field == "velocity")
+	|> group(columns: ["name", "driver"])
+	|> mean()
+	|> filter(fn: (r) => r._value < 1.0)
+	|> keep(columns: ["name", "driver"])`
+
+	humanLabel := "Influx Flux stationary trucks"
+	humanDesc := fmt.Sprintf("%s: with low avg velocity in last 10 minutes", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// fluxDrivingPeriods is the tail of the driving session queries: it counts the
+// 10 minute periods in which a truck was moving and keeps those above limit.
+func fluxDrivingPeriods(limit int) string {
+	return fmt.Sprintf(`
+	|> filter(fn: (r) => r._field == "velocity")
+	|> group(columns: ["name", "driver"])
+	|> aggregateWindow(every: 10m, fn: mean, createEmpty: false)
+	|> filter(fn: (r) => r._value > 1.0)
+	|> count()
+	|> filter(fn: (r) => r._value > %d)
+	|> keep(columns: ["name", "driver"])`, limit)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks that have not stopped at least 20 mins in the last 4 hours.
+func (i *FluxIoT) TrucksWithLongDrivingSessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	flux := i.fluxFromInterval(iot.ReadingsTableName, interval) +
+		i.getFleetFilter() +
+		// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+		fluxDrivingPeriods(tenMinutePeriods(5, iot.LongDrivingSessionDuration))
+
+	humanLabel := "Influx Flux trucks with longer driving sessions"
+	humanDesc := fmt.Sprintf("%s: stopped less than 20 mins in 4 hour period", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TrucksWithLongDailySessions finds all trucks that have driven more than 10 hours in the last 24 hours.
+func (i *FluxIoT) TrucksWithLongDailySessions(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	flux := i.fluxFromInterval(iot.ReadingsTableName, interval) +
+		i.getFleetFilter() +
+		// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+		fluxDrivingPeriods(tenMinutePeriods(35, iot.DailyDrivingDuration))
+
+	humanLabel := "Influx Flux trucks with longer daily sessions"
+	humanDesc := fmt.Sprintf("%s: drove more than 10 hours in the last 24 hours", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet.
+func (i *FluxIoT) AvgVsProjectedFuelConsumption(qi query.Query) {
+	flux := i.fluxFromInterval(iot.ReadingsTableName, i.Interval) + `
+	|> filter(fn: (r) => r._field == "velocity" or r._field == "fuel_consumption" or r._field == "nominal_fuel_consumption")
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> filter(fn: (r) => r.velocity > 1.0)
+	|> group(columns: ["fleet"])
+	|> reduce(
+		identity: {n: 0.0, fuel: 0.0, nominal: 0.0},
+		fn: (r, accumulator) => ({
+			n: accumulator.n + 1.0,
+			fuel: accumulator.fuel + r.fuel_consumption,
+			nominal: accumulator.nominal + r.nominal_fuel_consumption,
+		}))
+	|> map(fn: (r) => ({fleet: r.fleet, mean_fuel_consumption: r.fuel / r.n, nominal_fuel_consumption: r.nominal / r.n}))`
+
+	humanLabel := "Influx Flux average vs projected fuel consumption per fleet"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgDailyDrivingDuration finds the average driving duration per driver.
+func (i *FluxIoT) AvgDailyDrivingDuration(qi query.Query) {
+	flux := i.fluxFromInterval(iot.ReadingsTableName, i.Interval) + `
+	|> filter(fn: (r) => r._field == "velocity")
+	|> group(columns: ["fleet", "name", "driver"])
+	|> aggregateWindow(every: 10m, fn: mean, createEmpty: false)
+	|> aggregateWindow(every: 1d, fn: count, createEmpty: false)
+	|> map(fn: (r) => ({r with hours_driven: float(v: r._value) / 6.0}))`
+
+	humanLabel := "Influx Flux average driver driving duration per day"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgDailyDrivingSession finds the average driving session without stopping per driver per day.
+func (i *FluxIoT) AvgDailyDrivingSession(qi query.Query) {
+	flux := i.fluxFromInterval(iot.ReadingsTableName, i.Interval) + `
+	|> filter(fn: (r) => r._field == "velocity")
+	|> group(columns: ["name"])
+	|> aggregateWindow(every: 10m, fn: mean, createEmpty: true)
+	|> map(fn: (r) => ({r with _value: if exists r._value and r._value > 1.0 then 1.0 else 0.0}))
+	|> stateDuration(fn: (r) => r._value == 1.0, column: "driving", unit: 1m)
+	|> difference(columns: ["_value"])
+	|> filter(fn: (r) => r._value == -1.0)
+	|> aggregateWindow(every: 1d, fn: mean, column: "driving", createEmpty: false)`
+
+	humanLabel := "Influx Flux average driver driving session without stopping per day"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgLoad finds the average load per truck model per fleet.
+func (i *FluxIoT) AvgLoad(qi query.Query) {
+	flux := i.fluxFromInterval(iot.DiagnosticsTableName, i.Interval) + `
+	|> filter(fn: (r) => r._field == "current_load" or r._field == "load_capacity")
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> map(fn: (r) => ({fleet: r.fleet, model: r.model, _value: r.current_load / r.load_capacity}))
+	|> group(columns: ["fleet", "model"])
+	|> mean()
+	|> rename(columns: {_value: "mean_load_percentage"})`
+
+	humanLabel := "Influx Flux average load per truck model per fleet"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// DailyTruckActivity returns the number of hours trucks has been active (not out-of-commission) per day per fleet per model.
+func (i *FluxIoT) DailyTruckActivity(qi query.Query) {
+	flux := i.fluxFromInterval(iot.DiagnosticsTableName, i.Interval) + `
+	|> filter(fn: (r) => r._field == "status")
+	|> group(columns: ["model", "fleet"])
+	|> aggregateWindow(every: 10m, fn: mean, createEmpty: false)
+	|> filter(fn: (r) => r._value < 1.0)
+	|> aggregateWindow(every: 1d, fn: count, createEmpty: false)
+	|> map(fn: (r) => ({r with _value: float(v: r._value) / 144.0}))`
+
+	humanLabel := "Influx Flux daily truck activity per fleet per model"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TruckBreakdownFrequency calculates the amount of times a truck model broke down in the last period.
+func (i *FluxIoT) TruckBreakdownFrequency(qi query.Query) {
+	flux := i.fluxFromInterval(iot.DiagnosticsTableName, i.Interval) + `
+	|> filter(fn: (r) => r._field == "status")
+	|> group(columns: ["model"])
+	|> aggregateWindow(
+		every: 10m,
+		fn: (tables=<-, column) => tables
+			|> map(fn: (r) => ({r with _value: if r._value != 0.0 then 1.0 else 0.0}))
+			|> mean(column: column),
+		createEmpty: false)
+	|> map(fn: (r) => ({r with _value: if r._value >= 0.5 then 1.0 else 0.0}))
+	|> difference()
+	|> filter(fn: (r) => r._value == 1.0)
+	|> count()`
+
+	humanLabel := "Influx Flux truck breakdown frequency per model"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
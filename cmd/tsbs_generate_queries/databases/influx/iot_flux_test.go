@@ -0,0 +1,66 @@
+package influx
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func newTestFluxIoT(t *testing.T, s, e time.Time) *FluxIoT {
+	b := BaseGenerator{UseFlux: true, Bucket: "benchmark"}
+	ig, err := b.NewIoT(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating iot generator")
+	}
+	i, ok := ig.(*FluxIoT)
+	if !ok {
+		t.Fatalf("UseFlux generator is not *FluxIoT: %T", ig)
+	}
+	return i
+}
+
+func TestFluxStationaryTrucks(t *testing.T) {
+	expectedHumanLabel := "Influx Flux stationary trucks"
+	expectedHumanDesc := "Influx Flux stationary trucks: with low avg velocity in last 10 minutes"
+	expectedFlux := `from(bucket: "benchmark")
+	|> range(start: 1970-01-01T00:36:22Z, stop: 1970-01-01T00:46:22Z)
+	|> filter(fn: (r) => r._measurement == "readings")
+	|> filter(fn: (r) => r.fleet == "West")
+	|> filter(fn: (r) => r._field == "velocity")
+	|> group(columns: ["name", "driver"])
+	|> mean()
+	|> filter(fn: (r) => r._value < 1.0)
+	|> keep(columns: ["name", "driver"])`
+
+	s := time.Unix(0, 0)
+	i := newTestFluxIoT(t, s, s.Add(time.Hour))
+
+	q := i.GenerateEmptyQuery()
+	rand.Seed(123)
+	i.StationaryTrucks(q)
+
+	verifyFluxQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedFlux)
+}
+
+func TestFluxTrucksWithLowFuel(t *testing.T) {
+	expectedHumanLabel := "Influx Flux trucks with low fuel"
+	expectedHumanDesc := "Influx Flux trucks with low fuel: under 10 percent"
+	expectedFlux := `from(bucket: "benchmark")
+	|> range(start: 1970-01-01T00:00:00Z, stop: 1970-01-01T01:00:00Z)
+	|> filter(fn: (r) => r._measurement == "diagnostics")
+	|> filter(fn: (r) => r.fleet == "South")
+	|> filter(fn: (r) => r._field == "fuel_state")
+	|> last()
+	|> filter(fn: (r) => r._value <= 0.1)
+	|> rename(columns: {_value: "fuel_state"})
+	|> keep(columns: ["name", "driver", "fuel_state"])`
+
+	rand.Seed(123)
+	s := time.Unix(0, 0).UTC()
+	i := newTestFluxIoT(t, s, s.Add(time.Hour))
+
+	q := i.GenerateEmptyQuery()
+	i.TrucksWithLowFuel(q)
+
+	verifyFluxQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedFlux)
+}
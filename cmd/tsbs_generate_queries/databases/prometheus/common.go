@@ -0,0 +1,117 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	internalutils "github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	rangeQueryPath   = "/api/v1/query_range"
+	instantQueryPath = "/api/v1/query"
+	// metricLabel holds the metric name in queries combining several metrics
+	metricLabel = "metric"
+)
+
+// BaseGenerator contains settings specific for Prometheus.
+type BaseGenerator struct{}
+
+// GenerateEmptyQuery returns an empty query.HTTP.
+func (g *BaseGenerator) GenerateEmptyQuery() query.Query {
+	return query.NewHTTP()
+}
+
+// fillInRangeQuery fills the query struct with a PromQL range query evaluated
+// every step over the given interval.
+func (g *BaseGenerator) fillInRangeQuery(qi query.Query, humanLabel, humanDesc, promql string, interval *internalutils.TimeInterval, step time.Duration) {
+	v := url.Values{}
+	v.Set("query", promql)
+	v.Set("start", formatTime(interval.Start()))
+	v.Set("end", formatTime(interval.End()))
+	v.Set("step", formatDuration(step))
+	g.fillIn(qi, humanLabel, humanDesc, promql, rangeQueryPath+"?"+v.Encode(), interval)
+}
+
+// fillInInstantQuery fills the query struct with a PromQL instant query
+// evaluated at the end of the given interval.
+func (g *BaseGenerator) fillInInstantQuery(qi query.Query, humanLabel, humanDesc, promql string, interval *internalutils.TimeInterval) {
+	v := url.Values{}
+	v.Set("query", promql)
+	v.Set("time", formatTime(interval.End()))
+	g.fillIn(qi, humanLabel, humanDesc, promql, instantQueryPath+"?"+v.Encode(), interval)
+}
+
+func (g *BaseGenerator) fillIn(qi query.Query, humanLabel, humanDesc, promql, path string, interval *internalutils.TimeInterval) {
+	q := qi.(*query.HTTP)
+	q.HumanLabel = []byte(humanLabel)
+	q.RawQuery = []byte(promql)
+	q.HumanDescription = []byte(humanDesc)
+	q.Method = []byte("GET")
+	q.Path = []byte(path)
+	q.Body = nil
+	q.StartTimestamp = interval.StartUnixNano()
+	q.EndTimestamp = interval.EndUnixNano()
+}
+
+// formatTime formats t as a unix timestamp in seconds as accepted by the HTTP API.
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+// formatDuration formats d as a PromQL duration in whole seconds.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
+
+// metricName returns the name of the series the remote-write loader creates
+// for a field of a measurement.
+func metricName(measurement, field string) string {
+	return measurement + "_" + field
+}
+
+// regexMatcher returns a label matcher matching any of the given values, or an
+// empty string when there are no values to match.
+func regexMatcher(label string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`%s=~"%s"`, label, strings.Join(values, "|"))
+}
+
+// perMetric applies expr to the series of every field of the measurement and
+// combines the results. Range vector functions and aggregations drop the metric
+// name, and set operators ignore it anyway, so with several fields the name is
+// copied to the metricLabel label to keep the results apart.
+func perMetric(measurement string, fields []string, expr func(field string) string) string {
+	if len(fields) == 1 {
+		return expr(fields[0])
+	}
+	exprs := make([]string, len(fields))
+	for i, f := range fields {
+		exprs[i] = fmt.Sprintf(`label_replace(%s, "%s", "%s", "", "")`, expr(f), metricLabel, metricName(measurement, f))
+	}
+	return strings.Join(exprs, " or ")
+}
+
+// NewDevops creates a new devops use case query generator.
+func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := devops.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	devops := &Devops{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return devops, nil
+}
@@ -0,0 +1,153 @@
+package prometheus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	internalutils "github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// highCPUStep is the resolution of the high-cpu query; it matches the
+	// default interval between readings so every reading is evaluated.
+	highCPUStep = 10 * time.Second
+	// groupByOrderByLimitWindows is the number of 1m windows returned by the
+	// groupby-orderby-limit query.
+	groupByOrderByLimitWindows = 5
+)
+
+// Devops produces PromQL queries for all the devops query types.
+type Devops struct {
+	*BaseGenerator
+	*devops.Core
+}
+
+func (d *Devops) getHostMatcher(nHosts int) string {
+	hostnames, err := d.GetRandomHosts(nHosts)
+	databases.PanicIfErr(err)
+	return regexMatcher("hostname", hostnames)
+}
+
+// selector returns an instant vector selector for the cpu metric, optionally
+// restricted by matcher.
+func selector(metric, matcher string) string {
+	name := metricName(devops.TableName, metric)
+	if matcher == "" {
+		return name
+	}
+	return fmt.Sprintf("%s{%s}", name, matcher)
+}
+
+// GroupByTime selects the MAX for numMetrics metrics under 'cpu',
+// per minute for nhosts hosts,
+// e.g. in PromQL with step=60s:
+//
+//	max(max_over_time(cpu_usage_user{hostname=~"$HOSTNAME_1|...|$HOSTNAME_N"}[1m]))
+func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
+	interval := d.Interval.MustRandWindow(timeRange)
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+	hostMatcher := d.getHostMatcher(nHosts)
+
+	humanLabel := fmt.Sprintf("Prometheus %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	promql := perMetric(devops.TableName, metrics, func(m string) string {
+		return fmt.Sprintf("max(max_over_time(%s[1m]))", selector(m, hostMatcher))
+	})
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, promql, interval, time.Minute)
+}
+
+// GroupByOrderByLimit benchmarks a query that returns the max of a metric in
+// the last 5 one minute windows before a random end time,
+// e.g. in PromQL with start=$TIME-4m, end=$TIME and step=60s:
+//
+//	max(max_over_time(cpu_usage_user[1m]))
+func (d *Devops) GroupByOrderByLimit(qi query.Query) {
+	interval := d.Interval.MustRandWindow(time.Hour)
+	end := interval.End()
+	limited, err := internalutils.NewTimeInterval(end.Add(-(groupByOrderByLimitWindows-1)*time.Minute), end)
+	databases.PanicIfErr(err)
+
+	humanLabel := "Prometheus max cpu over last 5 min-intervals (random end)"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	promql := fmt.Sprintf("max(max_over_time(%s[1m]))", selector("usage_user", ""))
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, promql, limited, time.Minute)
+}
+
+// GroupByTimeAndPrimaryTag selects the AVG of numMetrics metrics under 'cpu' per device per hour for a day,
+// e.g. in PromQL with step=1h:
+//
+//	avg by (hostname) (avg_over_time(cpu_usage_user[1h]))
+func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+
+	humanLabel := devops.GetDoubleGroupByLabel("Prometheus", numMetrics)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	promql := perMetric(devops.TableName, metrics, func(m string) string {
+		return fmt.Sprintf("avg by (hostname) (avg_over_time(%s[1h]))", selector(m, ""))
+	})
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, promql, interval, time.Hour)
+}
+
+// MaxAllCPU selects the MAX of all metrics under 'cpu' per hour for nhosts hosts,
+// e.g. in PromQL with step=1h:
+//
+//	label_replace(max(max_over_time(cpu_usage_user{hostname=~"$HOSTNAME_1|..."}[1h])), "metric", "cpu_usage_user", "", "")
+//	or ...
+//	or label_replace(max(max_over_time(cpu_usage_guest_nice{hostname=~"$HOSTNAME_1|..."}[1h])), "metric", "cpu_usage_guest_nice", "", "")
+func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hostMatcher := d.getHostMatcher(nHosts)
+
+	humanLabel := devops.GetMaxAllLabel("Prometheus", nHosts)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	promql := perMetric(devops.TableName, devops.GetAllCPUMetrics(), func(m string) string {
+		return fmt.Sprintf("max(max_over_time(%s[1h]))", selector(m, hostMatcher))
+	})
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, promql, interval, time.Hour)
+}
+
+// LastPointPerHost finds the last reading of every metric for every host in
+// the dataset with an instant query at the end of the dataset, e.g. in PromQL:
+//
+//	label_replace(last_over_time(cpu_usage_user[$DATASET_DURATION]), "metric", "cpu_usage_user", "", "")
+//	or ...
+func (d *Devops) LastPointPerHost(qi query.Query) {
+	humanLabel := "Prometheus last row per host"
+	humanDesc := humanLabel + ": cpu"
+	window := formatDuration(d.Interval.Duration())
+	promql := perMetric(devops.TableName, devops.GetAllCPUMetrics(), func(m string) string {
+		return fmt.Sprintf("last_over_time(%s[%s])", selector(m, ""), window)
+	})
+	d.fillInInstantQuery(qi, humanLabel, humanDesc, promql, d.Interval)
+}
+
+// HighCPUForHosts populates a query that gets CPU metrics when the CPU has high
+// usage between a time period for a number of hosts (if 0, it will search all hosts),
+// e.g. in PromQL with step=10s:
+//
+//	{__name__=~"cpu_.+",hostname=~"$HOST|$HOST2..."}
+//	and on (hostname) (cpu_usage_user{hostname=~"$HOST|$HOST2..."} > 90)
+func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
+	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+
+	var hostMatcher string
+	if nHosts > 0 {
+		hostMatcher = d.getHostMatcher(nHosts)
+	}
+	allMetrics := fmt.Sprintf(`__name__=~"%s"`, metricName(devops.TableName, ".+"))
+	if hostMatcher != "" {
+		allMetrics += "," + hostMatcher
+	}
+
+	humanLabel, err := devops.GetHighCPULabel("Prometheus", nHosts)
+	databases.PanicIfErr(err)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	promql := fmt.Sprintf("{%s} and on (hostname) (%s > 90)", allMetrics, selector("usage_user", hostMatcher))
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, promql, interval, highCPUStep)
+}
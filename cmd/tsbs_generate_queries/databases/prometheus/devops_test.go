@@ -0,0 +1,176 @@
+package prometheus
+
+import (
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestDevops(t *testing.T, s, e time.Time) *Devops {
+	b := BaseGenerator{}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	return dq.(*Devops)
+}
+
+func verifyQuery(t *testing.T, q query.Query, humanLabel, humanDesc, path string, params url.Values) {
+	hq, ok := q.(*query.HTTP)
+	if !ok {
+		t.Fatal("Filled query is not *query.HTTP type")
+	}
+
+	if got := string(hq.HumanLabel); got != humanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, humanLabel)
+	}
+	if got := string(hq.HumanDescription); got != humanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, humanDesc)
+	}
+	if got := string(hq.Method); got != "GET" {
+		t.Errorf("incorrect method:\ngot\n%s\nwant GET", got)
+	}
+	if got := string(hq.RawQuery); got != params.Get("query") {
+		t.Errorf("incorrect raw query:\ngot\n%s\nwant\n%s", got, params.Get("query"))
+	}
+	if got := string(hq.Path); got != path+"?"+params.Encode() {
+		t.Errorf("incorrect path:\ngot\n%s\nwant\n%s", got, path+"?"+params.Encode())
+	}
+}
+
+func TestDevopsGroupByTime(t *testing.T) {
+	expectedHumanLabel := "Prometheus 2 cpu metric(s), random    1 hosts, random 1h0m0s by 1m"
+	expectedHumanDesc := "Prometheus 2 cpu metric(s), random    1 hosts, random 1h0m0s by 1m: 1970-01-01T00:16:22Z"
+	params := url.Values{}
+	params.Set("query", `label_replace(max(max_over_time(cpu_usage_user{hostname=~"host_9"}[1m])), "metric", "cpu_usage_user", "", "")`+
+		` or label_replace(max(max_over_time(cpu_usage_system{hostname=~"host_9"}[1m])), "metric", "cpu_usage_system", "", "")`)
+	params.Set("start", "982.646325489")
+	params.Set("end", "4582.646325489")
+	params.Set("step", "60s")
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(2*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTime(q, 1, 2, time.Hour)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, rangeQueryPath, params)
+}
+
+func TestDevopsGroupByOrderByLimit(t *testing.T) {
+	expectedHumanLabel := "Prometheus max cpu over last 5 min-intervals (random end)"
+	expectedHumanDesc := "Prometheus max cpu over last 5 min-intervals (random end): 1970-01-01T00:16:22Z"
+	params := url.Values{}
+	params.Set("query", "max(max_over_time(cpu_usage_user[1m]))")
+	params.Set("start", "4342.646325489")
+	params.Set("end", "4582.646325489")
+	params.Set("step", "60s")
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(2*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByOrderByLimit(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, rangeQueryPath, params)
+}
+
+func TestDevopsGroupByTimeAndPrimaryTag(t *testing.T) {
+	expectedHumanLabel := "Prometheus mean of 1 metrics, all hosts, random 12h0m0s by 1h"
+	expectedHumanDesc := "Prometheus mean of 1 metrics, all hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z"
+	params := url.Values{}
+	params.Set("query", "avg by (hostname) (avg_over_time(cpu_usage_user[1h]))")
+	params.Set("start", "982.646325489")
+	params.Set("end", "44182.646325489")
+	params.Set("step", "3600s")
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(13*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTimeAndPrimaryTag(q, 1)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, rangeQueryPath, params)
+}
+
+func TestDevopsLastPointPerHost(t *testing.T) {
+	expectedHumanLabel := "Prometheus last row per host"
+	expectedHumanDesc := "Prometheus last row per host: cpu"
+
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.LastPointPerHost(q)
+
+	hq := q.(*query.HTTP)
+	params := url.Values{}
+	params.Set("query", string(hq.RawQuery))
+	params.Set("time", "3600")
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, instantQueryPath, params)
+
+	wantPrefix := `label_replace(last_over_time(cpu_usage_user[3600s]), "metric", "cpu_usage_user", "", "") or `
+	if got := string(hq.RawQuery); len(got) < len(wantPrefix) || got[:len(wantPrefix)] != wantPrefix {
+		t.Errorf("incorrect query:\ngot\n%s\nwant prefix\n%s", got, wantPrefix)
+	}
+}
+
+func TestDevopsHighCPUForHosts(t *testing.T) {
+	cases := []struct {
+		desc               string
+		nHosts             int
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedQuery      string
+	}{
+		{
+			desc:               "zero hosts",
+			nHosts:             0,
+			expectedHumanLabel: "Prometheus CPU over threshold, all hosts",
+			expectedHumanDesc:  "Prometheus CPU over threshold, all hosts: 1970-01-01T00:16:22Z",
+			expectedQuery:      `{__name__=~"cpu_.+"} and on (hostname) (cpu_usage_user > 90)`,
+		},
+		{
+			desc:               "one host",
+			nHosts:             1,
+			expectedHumanLabel: "Prometheus CPU over threshold, 1 host(s)",
+			expectedHumanDesc:  "Prometheus CPU over threshold, 1 host(s): 1970-01-01T00:16:22Z",
+			expectedQuery:      `{__name__=~"cpu_.+",hostname=~"host_9"} and on (hostname) (cpu_usage_user{hostname=~"host_9"} > 90)`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			s := time.Unix(0, 0)
+			d := newTestDevops(t, s, s.Add(13*time.Hour))
+
+			q := d.GenerateEmptyQuery()
+			d.HighCPUForHosts(q, c.nHosts)
+
+			params := url.Values{}
+			params.Set("query", c.expectedQuery)
+			params.Set("start", "982.646325489")
+			params.Set("end", "44182.646325489")
+			params.Set("step", "10s")
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, rangeQueryPath, params)
+		})
+	}
+}
+
+func TestPerMetric(t *testing.T) {
+	expr := func(f string) string { return "max(cpu_" + f + ")" }
+	if got := perMetric("cpu", []string{"usage_user"}, expr); got != "max(cpu_usage_user)" {
+		t.Errorf("incorrect single metric expression: %s", got)
+	}
+	want := `label_replace(max(cpu_usage_user), "metric", "cpu_usage_user", "", "") or label_replace(max(cpu_usage_system), "metric", "cpu_usage_system", "", "")`
+	if got := perMetric("cpu", []string{"usage_user", "usage_system"}, expr); got != want {
+		t.Errorf("incorrect multi metric expression:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
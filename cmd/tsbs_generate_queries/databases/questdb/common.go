@@ -0,0 +1,52 @@
+package questdb
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// questdbTimeFmt is the format of timestamp literals, which QuestDB parses
+// as UTC with microsecond precision.
+const questdbTimeFmt = "2006-01-02T15:04:05.000000Z"
+
+// BaseGenerator contains settings specific for QuestDB
+type BaseGenerator struct {
+}
+
+// GenerateEmptyQuery returns an empty query.QuestDB.
+func (g *BaseGenerator) GenerateEmptyQuery() query.Query {
+	return query.NewQuestDB()
+}
+
+// fillInQuery fills the query struct with data.
+func (g *BaseGenerator) fillInQuery(qi query.Query, humanLabel, humanDesc, table, sql string) {
+	q := qi.(*query.QuestDB)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(humanDesc)
+	q.Table = []byte(table)
+	q.SqlQuery = []byte(sql)
+}
+
+// formatTime returns t as a timestamp literal.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(questdbTimeFmt)
+}
+
+// NewDevops creates a new devops use case query generator.
+func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := devops.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	devops := &Devops{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return devops, nil
+}
@@ -0,0 +1,154 @@
+package questdb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestDevops(t *testing.T, s, e time.Time) *Devops {
+	b := BaseGenerator{}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	return dq.(*Devops)
+}
+
+func verifyQuery(t *testing.T, q query.Query, humanLabel, humanDesc, sql string) {
+	qq, ok := q.(*query.QuestDB)
+	if !ok {
+		t.Fatal("Filled query is not *query.QuestDB type")
+	}
+
+	if got := string(qq.HumanLabel); got != humanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, humanLabel)
+	}
+	if got := string(qq.HumanDescription); got != humanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, humanDesc)
+	}
+	if got := string(qq.Table); got != "cpu" {
+		t.Errorf("incorrect table: got %s want cpu", got)
+	}
+	if got := string(qq.SqlQuery); got != sql {
+		t.Errorf("incorrect SQL query:\ndiff\n%s\ngot\n%s\nwant\n%s", diff.CharacterDiff(got, sql), got, sql)
+	}
+}
+
+func TestDevopsGetHostWhereWithHostnames(t *testing.T) {
+	d := newTestDevops(t, time.Now(), time.Now())
+	if got, want := d.getHostWhereWithHostnames([]string{"foo1", "foo2"}), "hostname IN ('foo1','foo2')"; got != want {
+		t.Errorf("incorrect output: got %s want %s", got, want)
+	}
+}
+
+func TestDevopsGroupByTime(t *testing.T) {
+	expectedHumanLabel := "QuestDB 1 cpu metric(s), random    1 hosts, random 1s by 1m"
+	expectedHumanDesc := "QuestDB 1 cpu metric(s), random    1 hosts, random 1s by 1m: 1970-01-01T00:05:58Z"
+	expectedSQLQuery := `SELECT timestamp, max(usage_user) AS max_usage_user
+        FROM cpu
+        WHERE hostname IN ('host_9') AND timestamp >= '1970-01-01T00:05:58.646325Z' AND timestamp < '1970-01-01T00:05:59.646325Z'
+        SAMPLE BY 1m ALIGN TO CALENDAR`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTime(q, 1, 1, time.Second)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedSQLQuery)
+}
+
+func TestDevopsGroupByOrderByLimit(t *testing.T) {
+	expectedHumanLabel := "QuestDB max cpu over last 5 min-intervals (random end)"
+	expectedHumanDesc := "QuestDB max cpu over last 5 min-intervals (random end): 1970-01-01T01:16:22Z"
+	expectedSQLQuery := `SELECT * FROM (
+          SELECT timestamp, max(usage_user)
+          FROM cpu
+          WHERE timestamp < '1970-01-01T01:16:22.646325Z'
+          SAMPLE BY 1m ALIGN TO CALENDAR)
+        ORDER BY timestamp DESC
+        LIMIT 5`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(2*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByOrderByLimit(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedSQLQuery)
+}
+
+func TestDevopsGroupByTimeAndPrimaryTag(t *testing.T) {
+	expectedHumanLabel := "QuestDB mean of 1 metrics, all hosts, random 12h0m0s by 1h"
+	expectedHumanDesc := "QuestDB mean of 1 metrics, all hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z"
+	expectedSQLQuery := `SELECT timestamp, hostname, avg(usage_user) AS mean_usage_user
+        FROM cpu
+        WHERE timestamp >= '1970-01-01T00:16:22.646325Z' AND timestamp < '1970-01-01T12:16:22.646325Z'
+        SAMPLE BY 1h ALIGN TO CALENDAR`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(13*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTimeAndPrimaryTag(q, 1)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedSQLQuery)
+}
+
+func TestDevopsLastPointPerHost(t *testing.T) {
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.LastPointPerHost(q)
+
+	verifyQuery(t, q, "QuestDB last row per host", "QuestDB last row per host",
+		"SELECT * FROM cpu LATEST ON timestamp PARTITION BY hostname")
+}
+
+func TestDevopsHighCPUForHosts(t *testing.T) {
+	cases := []struct {
+		desc               string
+		nHosts             int
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedSQLQuery   string
+	}{
+		{
+			desc:               "zero hosts",
+			nHosts:             0,
+			expectedHumanLabel: "QuestDB CPU over threshold, all hosts",
+			expectedHumanDesc:  "QuestDB CPU over threshold, all hosts: 1970-01-01T00:16:22Z",
+			expectedSQLQuery: "SELECT * FROM cpu WHERE usage_user > 90.0 AND " +
+				"timestamp >= '1970-01-01T00:16:22.646325Z' AND timestamp < '1970-01-01T12:16:22.646325Z'",
+		},
+		{
+			desc:               "one host",
+			nHosts:             1,
+			expectedHumanLabel: "QuestDB CPU over threshold, 1 host(s)",
+			expectedHumanDesc:  "QuestDB CPU over threshold, 1 host(s): 1970-01-01T00:54:10Z",
+			expectedSQLQuery: "SELECT * FROM cpu WHERE usage_user > 90.0 AND " +
+				"timestamp >= '1970-01-01T00:54:10.138978Z' AND timestamp < '1970-01-01T12:54:10.138978Z' AND hostname IN ('host_5')",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			s := time.Unix(0, 0)
+			d := newTestDevops(t, s, s.Add(13*time.Hour))
+
+			q := d.GenerateEmptyQuery()
+			d.HighCPUForHosts(q, c.nHosts)
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedSQLQuery)
+		})
+	}
+}
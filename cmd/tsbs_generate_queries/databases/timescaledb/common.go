@@ -1,6 +1,7 @@
 package timescaledb
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
@@ -9,13 +10,19 @@ import (
 	"github.com/timescale/tsbs/pkg/query"
 )
 
-const goTimeFmt = "2006-01-02 15:04:05.999999 -0700"
+const (
+	goTimeFmt = "2006-01-02 15:04:05.999999 -0700"
+	// dateBinOrigin aligns date_bin buckets to the unix epoch, like time_bucket
+	dateBinOrigin = "TIMESTAMPTZ '1970-01-01 00:00:00+00'"
+)
 
 // BaseGenerator contains settings specific for TimescaleDB
 type BaseGenerator struct {
 	UseJSON       bool
 	UseTags       bool
 	UseTimeBucket bool
+	// UseCaggs reads eligible devops queries from the continuous aggregates
+	UseCaggs bool
 }
 
 // GenerateEmptyQuery returns an empty query.TimescaleDB.
@@ -32,6 +39,16 @@ func (g *BaseGenerator) fillInQuery(qi query.Query, humanLabel, humanDesc, table
 	q.SqlQuery = []byte(sql)
 }
 
+// timeBucket returns the expression grouping column into buckets of the
+// given interval, e.g. '10 minutes'. time_bucket is only available with
+// TimescaleDB, so plain PostgreSQL uses date_bin (PostgreSQL 14 or newer).
+func (g *BaseGenerator) timeBucket(interval, column string) string {
+	if g.UseTimeBucket {
+		return fmt.Sprintf("time_bucket('%s', %s)", interval, column)
+	}
+	return fmt.Sprintf("date_bin('%s', %s, %s)", interval, column, dateBinOrigin)
+}
+
 // NewDevops creates a new devops use case query generator.
 func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
 	core, err := devops.NewCore(start, end, scale)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
@@ -20,8 +21,8 @@ const (
 	oneMinute = 60
 	oneHour   = oneMinute * 60
 
-	timeBucketFmt    = "time_bucket('%d seconds', time)"
-	nonTimeBucketFmt = "to_timestamp(((extract(epoch from time)::int)/%d)*%d)"
+	timeBucketFmt = "time_bucket('%d seconds', time)"
+	dateTruncFmt  = "date_trunc('%s', time)"
 )
 
 // Devops produces TimescaleDB-specific queries for all the devops query types.
@@ -61,17 +62,75 @@ func (d *Devops) getHostWhereString(nHosts int) string {
 	return d.getHostWhereWithHostnames(hostnames)
 }
 
+// getTimeBucket returns the expression grouping time into buckets of the given
+// number of seconds. Without time_bucket, minutes and hours use date_trunc,
+// which every PostgreSQL version supports, and other widths use date_bin.
 func (d *Devops) getTimeBucket(seconds int) string {
 	if d.UseTimeBucket {
 		return fmt.Sprintf(timeBucketFmt, seconds)
 	}
-	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+	switch seconds {
+	case oneMinute:
+		return fmt.Sprintf(dateTruncFmt, "minute")
+	case oneHour:
+		return fmt.Sprintf(dateTruncFmt, "hour")
+	}
+	return d.timeBucket(fmt.Sprintf("%d seconds", seconds), "time")
+}
+
+// cpuSource is the relation read by queries grouping cpu into buckets of a
+// fixed width: either the cpu hypertable or one of its continuous aggregates.
+type cpuSource struct {
+	table      string
+	timeColumn string
+	bucket     string
+	cagg       bool
+	// width is the bucket width of a continuous aggregate
+	width time.Duration
+}
+
+// getCPUSource returns the relation to read for buckets of the given number
+// of seconds. With UseCaggs, 1-minute and 1-hour buckets are read from the
+// cpu_1m and cpu_1h continuous aggregates created by tsbs_load with
+// -continuous-aggregates including 1m and 1h, named as tsbs_load names them.
+func (d *Devops) getCPUSource(seconds int) cpuSource {
+	if d.UseCaggs {
+		switch seconds {
+		case oneMinute:
+			return cpuSource{table: "cpu_1m", timeColumn: "bucket", bucket: "bucket", cagg: true, width: time.Minute}
+		case oneHour:
+			return cpuSource{table: "cpu_1h", timeColumn: "bucket", bucket: "bucket", cagg: true, width: time.Hour}
+		}
+	}
+	return cpuSource{table: devops.TableName, timeColumn: "time", bucket: d.getTimeBucket(seconds)}
+}
+
+// window returns the interval to read from the source. A continuous aggregate
+// only holds whole buckets, so the interval is truncated to the bucket width:
+// every bucket read is then complete, instead of the first one covering data
+// from before the start.
+func (s cpuSource) window(interval *utils.TimeInterval) *utils.TimeInterval {
+	if !s.cagg {
+		return interval
+	}
+	window, err := utils.NewTimeInterval(interval.Start().Truncate(s.width), interval.End().Truncate(s.width))
+	panicIfErr(err)
+	return window
+}
+
+// column returns the column to aggregate with agg for the metric, which is
+// the pre-aggregated agg_metric column of a continuous aggregate.
+func (s cpuSource) column(agg, metric string) string {
+	if s.cagg {
+		return agg + "_" + metric
+	}
+	return metric
 }
 
-func (d *Devops) getSelectClausesAggMetrics(agg string, metrics []string) []string {
+func (d *Devops) getSelectClausesAggMetrics(src cpuSource, agg string, metrics []string) []string {
 	selectClauses := make([]string, len(metrics))
 	for i, m := range metrics {
-		selectClauses[i] = fmt.Sprintf("%[1]s(%[2]s) as %[1]s_%[2]s", agg, m)
+		selectClauses[i] = fmt.Sprintf("%s(%s) as %s_%s", agg, src.column(agg, m), agg, m)
 	}
 
 	return selectClauses
@@ -90,21 +149,24 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 	interval := d.Interval.MustRandWindow(timeRange)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
-	selectClauses := d.getSelectClausesAggMetrics("max", metrics)
+	src := d.getCPUSource(oneMinute)
+	interval = src.window(interval)
+	selectClauses := d.getSelectClausesAggMetrics(src, "max", metrics)
 	if len(selectClauses) < 1 {
 		panic(fmt.Sprintf("invalid number of select clauses: got %d", len(selectClauses)))
 	}
 
 	sql := fmt.Sprintf(`SELECT %s AS minute,
         %s
-        FROM cpu
-        WHERE %s AND time >= '%s' AND time < '%s'
+        FROM %s
+        WHERE %s AND %s >= '%s' AND %s < '%s'
         GROUP BY minute ORDER BY minute ASC`,
-		d.getTimeBucket(oneMinute),
+		src.bucket,
 		strings.Join(selectClauses, ", "),
+		src.table,
 		d.getHostWhereString(nHosts),
-		interval.Start().Format(goTimeFmt),
-		interval.End().Format(goTimeFmt))
+		src.timeColumn, interval.Start().Format(goTimeFmt),
+		src.timeColumn, interval.End().Format(goTimeFmt))
 
 	humanLabel := fmt.Sprintf("TimescaleDB %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange)
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
@@ -117,15 +179,18 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 // GROUP BY t ORDER BY t DESC
 // LIMIT $LIMIT
 func (d *Devops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
-	sql := fmt.Sprintf(`SELECT %s AS minute, max(usage_user)
-        FROM cpu
-        WHERE time < '%s'
+	src := d.getCPUSource(oneMinute)
+	interval := src.window(d.Interval.MustRandWindow(time.Hour))
+	sql := fmt.Sprintf(`SELECT %s AS minute, max(%s)
+        FROM %s
+        WHERE %s < '%s'
         GROUP BY minute
         ORDER BY minute DESC
         LIMIT 5`,
-		d.getTimeBucket(oneMinute),
-		interval.End().Format(goTimeFmt))
+		src.bucket,
+		src.column("max", "usage_user"),
+		src.table,
+		src.timeColumn, interval.End().Format(goTimeFmt))
 
 	humanLabel := "TimescaleDB max cpu over last 5 min-intervals (random end)"
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.EndString())
@@ -142,13 +207,14 @@ func (d *Devops) GroupByOrderByLimit(qi query.Query) {
 func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	src := d.getCPUSource(oneHour)
+	interval := src.window(d.Interval.MustRandWindow(devops.DoubleGroupByDuration))
 
 	selectClauses := make([]string, numMetrics)
 	meanClauses := make([]string, numMetrics)
 	for i, m := range metrics {
 		meanClauses[i] = "mean_" + m
-		selectClauses[i] = fmt.Sprintf("avg(%s) as %s", m, meanClauses[i])
+		selectClauses[i] = fmt.Sprintf("avg(%s) as %s", src.column("avg", m), meanClauses[i])
 	}
 
 	hostnameField := "hostname"
@@ -168,19 +234,20 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
         WITH cpu_avg AS (
           SELECT %s as hour, %s,
           %s
-          FROM cpu
-          WHERE time >= '%s' AND time < '%s'
+          FROM %s
+          WHERE %s >= '%s' AND %s < '%s'
           GROUP BY 1, 2
         )
         SELECT hour, %s, %s
         FROM cpu_avg
         %s
         ORDER BY hour, %s`,
-		d.getTimeBucket(oneHour),
+		src.bucket,
 		partitionGrouping,
 		strings.Join(selectClauses, ", "),
-		interval.Start().Format(goTimeFmt),
-		interval.End().Format(goTimeFmt),
+		src.table,
+		src.timeColumn, interval.Start().Format(goTimeFmt),
+		src.timeColumn, interval.End().Format(goTimeFmt),
 		hostnameField, strings.Join(meanClauses, ", "),
 		joinStr, hostnameField)
 	humanLabel := devops.GetDoubleGroupByLabel("TimescaleDB", numMetrics)
@@ -196,21 +263,23 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY hour ORDER BY hour
 func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
-	interval := d.Interval.MustRandWindow(duration)
+	src := d.getCPUSource(oneHour)
+	interval := src.window(d.Interval.MustRandWindow(duration))
 
 	metrics := devops.GetAllCPUMetrics()
-	selectClauses := d.getSelectClausesAggMetrics("max", metrics)
+	selectClauses := d.getSelectClausesAggMetrics(src, "max", metrics)
 
 	sql := fmt.Sprintf(`SELECT %s AS hour,
         %s
-        FROM cpu
-        WHERE %s AND time >= '%s' AND time < '%s'
+        FROM %s
+        WHERE %s AND %s >= '%s' AND %s < '%s'
         GROUP BY hour ORDER BY hour`,
-		d.getTimeBucket(oneHour),
+		src.bucket,
 		strings.Join(selectClauses, ", "),
+		src.table,
 		d.getHostWhereString(nHosts),
-		interval.Start().Format(goTimeFmt),
-		interval.End().Format(goTimeFmt))
+		src.timeColumn, interval.Start().Format(goTimeFmt),
+		src.timeColumn, interval.End().Format(goTimeFmt))
 
 	humanLabel := devops.GetMaxAllLabel("TimescaleDB", nHosts)
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
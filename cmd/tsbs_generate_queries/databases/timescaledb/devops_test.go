@@ -124,18 +124,41 @@ func TestDevopsGetTimeBucket(t *testing.T) {
 	d := dq.(*Devops)
 
 	seconds := 60
-	want := fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
-	if got := d.getTimeBucket(seconds); got != want {
-		t.Errorf("incorrect non time bucket format: got %s want %s", got, want)
+	cases := []struct {
+		seconds int
+		want    string
+	}{
+		{seconds: oneMinute, want: "date_trunc('minute', time)"},
+		{seconds: oneHour, want: "date_trunc('hour', time)"},
+		{seconds: 600, want: "date_bin('600 seconds', time, TIMESTAMPTZ '1970-01-01 00:00:00+00')"},
+	}
+	for _, c := range cases {
+		if got := d.getTimeBucket(c.seconds); got != c.want {
+			t.Errorf("incorrect non time bucket format: got %s want %s", got, c.want)
+		}
 	}
 
 	d.UseTimeBucket = true
-	want = fmt.Sprintf(timeBucketFmt, seconds)
+	want := fmt.Sprintf(timeBucketFmt, seconds)
 	if got := d.getTimeBucket(seconds); got != want {
 		t.Errorf("incorrect time bucket format: got %s want %s", got, want)
 	}
 }
 
+func TestBaseGeneratorTimeBucket(t *testing.T) {
+	b := &BaseGenerator{}
+	want := "date_bin('10 minutes', time, TIMESTAMPTZ '1970-01-01 00:00:00+00')"
+	if got := b.timeBucket("10 minutes", "time"); got != want {
+		t.Errorf("incorrect date_bin format: got %s want %s", got, want)
+	}
+
+	b.UseTimeBucket = true
+	want = "time_bucket('10 minutes', time)"
+	if got := b.timeBucket("10 minutes", "time"); got != want {
+		t.Errorf("incorrect time bucket format: got %s want %s", got, want)
+	}
+}
+
 func TestDevopsGetSelectClausesAggMetrics(t *testing.T) {
 	cases := []struct {
 		desc    string
@@ -171,7 +194,7 @@ func TestDevopsGetSelectClausesAggMetrics(t *testing.T) {
 		}
 		d := dq.(*Devops)
 
-		if got := strings.Join(d.getSelectClausesAggMetrics(c.agg, c.metrics), ","); got != c.want {
+		if got := strings.Join(d.getSelectClausesAggMetrics(d.getCPUSource(oneMinute), c.agg, c.metrics), ","); got != c.want {
 			t.Errorf("%s: incorrect output: got %s want %s", c.desc, got, c.want)
 		}
 	}
@@ -533,3 +556,76 @@ func verifyQuery(t *testing.T, q query.Query, humanLabel, humanDesc, hypertable,
 		t.Errorf("incorrect SQL query:\ndiff\n%s\ngot\n%s\nwant\n%s", diff.CharacterDiff(got, sqlQuery), got, sqlQuery)
 	}
 }
+
+func TestDevopsUseCaggs(t *testing.T) {
+	s := time.Unix(0, 0)
+	newDevops := func() *Devops {
+		rand.Seed(123) // Setting seed for testing purposes.
+		b := BaseGenerator{
+			UseTimeBucket: true,
+			UseCaggs:      true,
+		}
+		dq, err := b.NewDevops(s, s.Add(13*time.Hour), 10)
+		if err != nil {
+			t.Fatalf("Error while creating devops generator")
+		}
+		return dq.(*Devops)
+	}
+
+	d := newDevops()
+	q := d.GenerateEmptyQuery()
+	d.GroupByTime(q, 1, 2, time.Hour)
+	verifyQuery(t, q,
+		"TimescaleDB 2 cpu metric(s), random    1 hosts, random 1h0m0s by 1m",
+		"TimescaleDB 2 cpu metric(s), random    1 hosts, random 1h0m0s by 1m: 1970-01-01T06:16:00Z",
+		"cpu",
+		`SELECT bucket AS minute,
+        max(max_usage_user) as max_usage_user, max(max_usage_system) as max_usage_system
+        FROM cpu_1m
+        WHERE hostname IN ('host_9') AND bucket >= '1970-01-01 06:16:00 +0000' AND bucket < '1970-01-01 07:16:00 +0000'
+        GROUP BY minute ORDER BY minute ASC`)
+
+	d = newDevops()
+	q = d.GenerateEmptyQuery()
+	d.GroupByOrderByLimit(q)
+	verifyQuery(t, q,
+		"TimescaleDB max cpu over last 5 min-intervals (random end)",
+		"TimescaleDB max cpu over last 5 min-intervals (random end): 1970-01-01T07:16:00Z",
+		"cpu",
+		`SELECT bucket AS minute, max(max_usage_user)
+        FROM cpu_1m
+        WHERE bucket < '1970-01-01 07:16:00 +0000'
+        GROUP BY minute
+        ORDER BY minute DESC
+        LIMIT 5`)
+
+	d = newDevops()
+	q = d.GenerateEmptyQuery()
+	d.GroupByTimeAndPrimaryTag(q, 1)
+	verifyQuery(t, q,
+		"TimescaleDB mean of 1 metrics, all hosts, random 12h0m0s by 1h",
+		"TimescaleDB mean of 1 metrics, all hosts, random 12h0m0s by 1h: 1970-01-01T00:00:00Z",
+		"cpu",
+		`
+        WITH cpu_avg AS (
+          SELECT bucket as hour, hostname,
+          avg(avg_usage_user) as mean_usage_user
+          FROM cpu_1h
+          WHERE bucket >= '1970-01-01 00:00:00 +0000' AND bucket < '1970-01-01 12:00:00 +0000'
+          GROUP BY 1, 2
+        )
+        SELECT hour, hostname, mean_usage_user
+        FROM cpu_avg
+        
+        ORDER BY hour, hostname`)
+
+	d = newDevops()
+	q = d.GenerateEmptyQuery()
+	d.MaxAllCPU(q, 1, devops.MaxAllDuration)
+	got := string(q.(*query.TimescaleDB).SqlQuery)
+	for _, want := range []string{"SELECT bucket AS hour", "max(max_usage_guest_nice) as max_usage_guest_nice", "FROM cpu_1h", ":00:00 +0000' AND bucket < '"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("MaxAllCPU query does not contain %q:\n%s", want, got)
+		}
+	}
+}
@@ -363,7 +363,8 @@ func TestTrucksWithLongDrivingSessions(t *testing.T) {
 
 	for _, c := range cases {
 		b := BaseGenerator{
-			UseJSON: c.useJSON,
+			UseJSON:       c.useJSON,
+			UseTimeBucket: true,
 		}
 		ig, err := b.NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(6*time.Hour), 10)
 		if err != nil {
@@ -428,7 +429,8 @@ func TestTrucksWithLongDailySessions(t *testing.T) {
 
 	for _, c := range cases {
 		b := BaseGenerator{
-			UseJSON: c.useJSON,
+			UseJSON:       c.useJSON,
+			UseTimeBucket: true,
 		}
 		ig, err := b.NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(25*time.Hour), 10)
 		if err != nil {
@@ -554,7 +556,8 @@ func TestAvgDailyDrivingDuration(t *testing.T) {
 
 	for _, c := range cases {
 		b := BaseGenerator{
-			UseJSON: c.useJSON,
+			UseJSON:       c.useJSON,
+			UseTimeBucket: true,
 		}
 		ig, err := b.NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(25*time.Hour), 10)
 		if err != nil {
@@ -637,7 +640,8 @@ func TestAvgDailyDrivingSession(t *testing.T) {
 
 	for _, c := range cases {
 		b := BaseGenerator{
-			UseJSON: c.useJSON,
+			UseJSON:       c.useJSON,
+			UseTimeBucket: true,
 		}
 		ig, err := b.NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(25*time.Hour), 10)
 		if err != nil {
@@ -755,7 +759,8 @@ func TestDailyTruckActivity(t *testing.T) {
 
 	for _, c := range cases {
 		b := BaseGenerator{
-			UseJSON: c.useJSON,
+			UseJSON:       c.useJSON,
+			UseTimeBucket: true,
 		}
 		ig, err := b.NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(25*time.Hour), 10)
 		if err != nil {
@@ -830,7 +835,8 @@ func TestTruckBreakdownFrequency(t *testing.T) {
 
 	for _, c := range cases {
 		b := BaseGenerator{
-			UseJSON: c.useJSON,
+			UseJSON:       c.useJSON,
+			UseTimeBucket: true,
 		}
 		ig, err := b.NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(25*time.Hour), 10)
 		if err != nil {
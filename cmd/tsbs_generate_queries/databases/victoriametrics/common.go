@@ -0,0 +1,117 @@
+package victoriametrics
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	internalutils "github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	rangeQueryPath   = "/api/v1/query_range"
+	instantQueryPath = "/api/v1/query"
+)
+
+// BaseGenerator contains settings specific for VictoriaMetrics.
+type BaseGenerator struct{}
+
+// GenerateEmptyQuery returns an empty query.HTTP.
+func (g *BaseGenerator) GenerateEmptyQuery() query.Query {
+	return query.NewHTTP()
+}
+
+// fillInRangeQuery fills the query struct with a MetricsQL range query
+// evaluated every step over the given interval. The rollup result cache is
+// bypassed with nocache=1, so every query is computed from the stored data.
+func (g *BaseGenerator) fillInRangeQuery(qi query.Query, humanLabel, humanDesc, metricsql string, interval *internalutils.TimeInterval, step time.Duration) {
+	v := url.Values{}
+	v.Set("query", metricsql)
+	v.Set("start", formatTime(interval.Start()))
+	v.Set("end", formatTime(interval.End()))
+	v.Set("step", formatDuration(step))
+	v.Set("nocache", "1")
+	g.fillIn(qi, humanLabel, humanDesc, metricsql, rangeQueryPath+"?"+v.Encode(), interval)
+}
+
+// fillInInstantQuery fills the query struct with a MetricsQL instant query
+// evaluated at the end of the given interval.
+func (g *BaseGenerator) fillInInstantQuery(qi query.Query, humanLabel, humanDesc, metricsql string, interval *internalutils.TimeInterval) {
+	v := url.Values{}
+	v.Set("query", metricsql)
+	v.Set("time", formatTime(interval.End()))
+	g.fillIn(qi, humanLabel, humanDesc, metricsql, instantQueryPath+"?"+v.Encode(), interval)
+}
+
+func (g *BaseGenerator) fillIn(qi query.Query, humanLabel, humanDesc, metricsql, path string, interval *internalutils.TimeInterval) {
+	q := qi.(*query.HTTP)
+	q.HumanLabel = []byte(humanLabel)
+	q.RawQuery = []byte(metricsql)
+	q.HumanDescription = []byte(humanDesc)
+	q.Method = []byte("GET")
+	q.Path = []byte(path)
+	q.Body = nil
+	q.StartTimestamp = interval.StartUnixNano()
+	q.EndTimestamp = interval.EndUnixNano()
+}
+
+// formatTime formats t as a unix timestamp in seconds as accepted by the HTTP API.
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
+
+// formatDuration formats d as a MetricsQL duration in whole seconds.
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%ds", int64(d/time.Second))
+}
+
+// regexMatcher returns a label matcher matching any of the given values, or an
+// empty string when there are no values to match.
+func regexMatcher(label string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`%s=~"%s"`, label, strings.Join(values, "|"))
+}
+
+// selector returns a series selector for the fields of the measurement,
+// optionally restricted by matcher. VictoriaMetrics names the series written
+// through the line protocol <measurement>_<field>, so several fields are
+// selected at once with a regular expression on the metric name, e.g.
+//
+//	{__name__=~"cpu_(usage_user|usage_system)",hostname=~"host_1"}
+func selector(measurement string, fields []string, matcher string) string {
+	if len(fields) == 1 {
+		name := measurement + "_" + fields[0]
+		if matcher == "" {
+			return name
+		}
+		return fmt.Sprintf("%s{%s}", name, matcher)
+	}
+	s := fmt.Sprintf(`__name__=~"%s_(%s)"`, measurement, strings.Join(fields, "|"))
+	if matcher != "" {
+		s += "," + matcher
+	}
+	return "{" + s + "}"
+}
+
+// NewDevops creates a new devops use case query generator.
+func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := devops.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	devops := &Devops{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return devops, nil
+}
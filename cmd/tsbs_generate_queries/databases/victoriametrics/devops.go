@@ -0,0 +1,135 @@
+package victoriametrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	internalutils "github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// highCPUStep is the resolution of the high-cpu query; it matches the
+	// default interval between readings so every reading is evaluated.
+	highCPUStep = 10 * time.Second
+	// groupByOrderByLimitWindows is the number of 1m windows returned by the
+	// groupby-orderby-limit query.
+	groupByOrderByLimitWindows = 5
+)
+
+// Devops produces MetricsQL queries for all the devops query types. Rollup
+// functions drop the metric name, so keep_metric_names is used to keep the
+// results of several metrics apart.
+type Devops struct {
+	*BaseGenerator
+	*devops.Core
+}
+
+func (d *Devops) getHostMatcher(nHosts int) string {
+	hostnames, err := d.GetRandomHosts(nHosts)
+	databases.PanicIfErr(err)
+	return regexMatcher("hostname", hostnames)
+}
+
+// GroupByTime selects the MAX for numMetrics metrics under 'cpu',
+// per minute for nhosts hosts,
+// e.g. in MetricsQL with step=60s:
+//
+//	max(max_over_time({__name__=~"cpu_(usage_user|...)",hostname=~"$HOSTNAME_1|...|$HOSTNAME_N"}[1m]) keep_metric_names) by (__name__)
+func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
+	interval := d.Interval.MustRandWindow(timeRange)
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+	hostMatcher := d.getHostMatcher(nHosts)
+
+	humanLabel := fmt.Sprintf("VictoriaMetrics %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	metricsql := fmt.Sprintf("max(max_over_time(%s[1m]) keep_metric_names) by (__name__)",
+		selector(devops.TableName, metrics, hostMatcher))
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, metricsql, interval, time.Minute)
+}
+
+// GroupByOrderByLimit benchmarks a query that returns the max of a metric in
+// the last 5 one minute windows before a random end time,
+// e.g. in MetricsQL with start=$TIME-4m, end=$TIME and step=60s:
+//
+//	max(max_over_time(cpu_usage_user[1m]))
+func (d *Devops) GroupByOrderByLimit(qi query.Query) {
+	interval := d.Interval.MustRandWindow(time.Hour)
+	end := interval.End()
+	limited, err := internalutils.NewTimeInterval(end.Add(-(groupByOrderByLimitWindows-1)*time.Minute), end)
+	databases.PanicIfErr(err)
+
+	humanLabel := "VictoriaMetrics max cpu over last 5 min-intervals (random end)"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	metricsql := fmt.Sprintf("max(max_over_time(%s[1m]))", selector(devops.TableName, []string{"usage_user"}, ""))
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, metricsql, limited, time.Minute)
+}
+
+// GroupByTimeAndPrimaryTag selects the AVG of numMetrics metrics under 'cpu' per device per hour for a day,
+// e.g. in MetricsQL with step=1h:
+//
+//	avg(avg_over_time({__name__=~"cpu_(usage_user|...)"}[1h]) keep_metric_names) by (__name__, hostname)
+func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+
+	humanLabel := devops.GetDoubleGroupByLabel("VictoriaMetrics", numMetrics)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	metricsql := fmt.Sprintf("avg(avg_over_time(%s[1h]) keep_metric_names) by (__name__, hostname)",
+		selector(devops.TableName, metrics, ""))
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, metricsql, interval, time.Hour)
+}
+
+// MaxAllCPU selects the MAX of all metrics under 'cpu' per hour for nhosts hosts,
+// e.g. in MetricsQL with step=1h:
+//
+//	max(max_over_time({__name__=~"cpu_(usage_user|...|usage_guest_nice)",hostname=~"$HOSTNAME_1|..."}[1h]) keep_metric_names) by (__name__)
+func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hostMatcher := d.getHostMatcher(nHosts)
+
+	humanLabel := devops.GetMaxAllLabel("VictoriaMetrics", nHosts)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	metricsql := fmt.Sprintf("max(max_over_time(%s[1h]) keep_metric_names) by (__name__)",
+		selector(devops.TableName, devops.GetAllCPUMetrics(), hostMatcher))
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, metricsql, interval, time.Hour)
+}
+
+// LastPointPerHost finds the last reading of every metric for every host in
+// the dataset with an instant query at the end of the dataset, e.g. in MetricsQL:
+//
+//	last_over_time({__name__=~"cpu_(usage_user|...)"}[$DATASET_DURATION]) keep_metric_names
+func (d *Devops) LastPointPerHost(qi query.Query) {
+	humanLabel := "VictoriaMetrics last row per host"
+	humanDesc := humanLabel + ": cpu"
+	metricsql := fmt.Sprintf("last_over_time(%s[%s]) keep_metric_names",
+		selector(devops.TableName, devops.GetAllCPUMetrics(), ""), formatDuration(d.Interval.Duration()))
+	d.fillInInstantQuery(qi, humanLabel, humanDesc, metricsql, d.Interval)
+}
+
+// HighCPUForHosts populates a query that gets CPU metrics when the CPU has high
+// usage between a time period for a number of hosts (if 0, it will search all hosts),
+// e.g. in MetricsQL with step=10s:
+//
+//	{__name__=~"cpu_(usage_user|...)",hostname=~"$HOST|$HOST2..."}
+//	and on (hostname) (cpu_usage_user{hostname=~"$HOST|$HOST2..."} > 90)
+func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
+	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+
+	var hostMatcher string
+	if nHosts > 0 {
+		hostMatcher = d.getHostMatcher(nHosts)
+	}
+
+	humanLabel, err := devops.GetHighCPULabel("VictoriaMetrics", nHosts)
+	databases.PanicIfErr(err)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	metricsql := fmt.Sprintf("%s and on (hostname) (%s > 90)",
+		selector(devops.TableName, devops.GetAllCPUMetrics(), hostMatcher),
+		selector(devops.TableName, []string{"usage_user"}, hostMatcher))
+	d.fillInRangeQuery(qi, humanLabel, humanDesc, metricsql, interval, highCPUStep)
+}
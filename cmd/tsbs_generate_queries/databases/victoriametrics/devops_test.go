@@ -0,0 +1,186 @@
+package victoriametrics
+
+import (
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestDevops(t *testing.T, s, e time.Time) *Devops {
+	b := BaseGenerator{}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	return dq.(*Devops)
+}
+
+func verifyQuery(t *testing.T, q query.Query, humanLabel, humanDesc, path string, params url.Values) {
+	hq, ok := q.(*query.HTTP)
+	if !ok {
+		t.Fatal("Filled query is not *query.HTTP type")
+	}
+
+	if got := string(hq.HumanLabel); got != humanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, humanLabel)
+	}
+	if got := string(hq.HumanDescription); got != humanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, humanDesc)
+	}
+	if got := string(hq.Method); got != "GET" {
+		t.Errorf("incorrect method:\ngot\n%s\nwant GET", got)
+	}
+	if got := string(hq.RawQuery); got != params.Get("query") {
+		t.Errorf("incorrect raw query:\ngot\n%s\nwant\n%s", got, params.Get("query"))
+	}
+	if got := string(hq.Path); got != path+"?"+params.Encode() {
+		t.Errorf("incorrect path:\ngot\n%s\nwant\n%s", got, path+"?"+params.Encode())
+	}
+}
+
+func TestDevopsGroupByTime(t *testing.T) {
+	expectedHumanLabel := "VictoriaMetrics 2 cpu metric(s), random    1 hosts, random 1h0m0s by 1m"
+	expectedHumanDesc := "VictoriaMetrics 2 cpu metric(s), random    1 hosts, random 1h0m0s by 1m: 1970-01-01T00:16:22Z"
+	params := url.Values{}
+	params.Set("query", `max(max_over_time({__name__=~"cpu_(usage_user|usage_system)",hostname=~"host_9"}[1m]) keep_metric_names) by (__name__)`)
+	params.Set("start", "982.646325489")
+	params.Set("end", "4582.646325489")
+	params.Set("step", "60s")
+	params.Set("nocache", "1")
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(2*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTime(q, 1, 2, time.Hour)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, rangeQueryPath, params)
+}
+
+func TestDevopsGroupByOrderByLimit(t *testing.T) {
+	expectedHumanLabel := "VictoriaMetrics max cpu over last 5 min-intervals (random end)"
+	expectedHumanDesc := "VictoriaMetrics max cpu over last 5 min-intervals (random end): 1970-01-01T00:16:22Z"
+	params := url.Values{}
+	params.Set("query", "max(max_over_time(cpu_usage_user[1m]))")
+	params.Set("start", "4342.646325489")
+	params.Set("end", "4582.646325489")
+	params.Set("step", "60s")
+	params.Set("nocache", "1")
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(2*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByOrderByLimit(q)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, rangeQueryPath, params)
+}
+
+func TestDevopsGroupByTimeAndPrimaryTag(t *testing.T) {
+	expectedHumanLabel := "VictoriaMetrics mean of 1 metrics, all hosts, random 12h0m0s by 1h"
+	expectedHumanDesc := "VictoriaMetrics mean of 1 metrics, all hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z"
+	params := url.Values{}
+	params.Set("query", "avg(avg_over_time(cpu_usage_user[1h]) keep_metric_names) by (__name__, hostname)")
+	params.Set("start", "982.646325489")
+	params.Set("end", "44182.646325489")
+	params.Set("step", "3600s")
+	params.Set("nocache", "1")
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(13*time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTimeAndPrimaryTag(q, 1)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, rangeQueryPath, params)
+}
+
+func TestDevopsLastPointPerHost(t *testing.T) {
+	expectedHumanLabel := "VictoriaMetrics last row per host"
+	expectedHumanDesc := "VictoriaMetrics last row per host: cpu"
+
+	s := time.Unix(0, 0)
+	d := newTestDevops(t, s, s.Add(time.Hour))
+
+	q := d.GenerateEmptyQuery()
+	d.LastPointPerHost(q)
+
+	hq := q.(*query.HTTP)
+	params := url.Values{}
+	params.Set("query", string(hq.RawQuery))
+	params.Set("time", "3600")
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, instantQueryPath, params)
+
+	want := `last_over_time({__name__=~"cpu_(usage_user|usage_system|usage_idle|usage_nice|usage_iowait|usage_irq|usage_softirq|usage_steal|usage_guest|usage_guest_nice)"}[3600s]) keep_metric_names`
+	if got := string(hq.RawQuery); got != want {
+		t.Errorf("incorrect query:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDevopsHighCPUForHosts(t *testing.T) {
+	cases := []struct {
+		desc               string
+		nHosts             int
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedQuery      string
+	}{
+		{
+			desc:               "zero hosts",
+			nHosts:             0,
+			expectedHumanLabel: "VictoriaMetrics CPU over threshold, all hosts",
+			expectedHumanDesc:  "VictoriaMetrics CPU over threshold, all hosts: 1970-01-01T00:16:22Z",
+			expectedQuery:      `{__name__=~"cpu_(usage_user|usage_system|usage_idle|usage_nice|usage_iowait|usage_irq|usage_softirq|usage_steal|usage_guest|usage_guest_nice)"} and on (hostname) (cpu_usage_user > 90)`,
+		},
+		{
+			desc:               "one host",
+			nHosts:             1,
+			expectedHumanLabel: "VictoriaMetrics CPU over threshold, 1 host(s)",
+			expectedHumanDesc:  "VictoriaMetrics CPU over threshold, 1 host(s): 1970-01-01T00:16:22Z",
+			expectedQuery:      `{__name__=~"cpu_(usage_user|usage_system|usage_idle|usage_nice|usage_iowait|usage_irq|usage_softirq|usage_steal|usage_guest|usage_guest_nice)",hostname=~"host_9"} and on (hostname) (cpu_usage_user{hostname=~"host_9"} > 90)`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			s := time.Unix(0, 0)
+			d := newTestDevops(t, s, s.Add(13*time.Hour))
+
+			q := d.GenerateEmptyQuery()
+			d.HighCPUForHosts(q, c.nHosts)
+
+			params := url.Values{}
+			params.Set("query", c.expectedQuery)
+			params.Set("start", "982.646325489")
+			params.Set("end", "44182.646325489")
+			params.Set("step", "10s")
+			params.Set("nocache", "1")
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, rangeQueryPath, params)
+		})
+	}
+}
+
+func TestSelector(t *testing.T) {
+	cases := []struct {
+		fields  []string
+		matcher string
+		want    string
+	}{
+		{fields: []string{"usage_user"}, want: "cpu_usage_user"},
+		{fields: []string{"usage_user"}, matcher: `hostname=~"host_1"`, want: `cpu_usage_user{hostname=~"host_1"}`},
+		{fields: []string{"usage_user", "usage_system"}, want: `{__name__=~"cpu_(usage_user|usage_system)"}`},
+		{fields: []string{"usage_user", "usage_system"}, matcher: `hostname=~"host_1"`, want: `{__name__=~"cpu_(usage_user|usage_system)",hostname=~"host_1"}`},
+	}
+	for _, c := range cases {
+		if got := selector("cpu", c.fields, c.matcher); got != c.want {
+			t.Errorf("incorrect selector:\ngot\n%s\nwant\n%s", got, c.want)
+		}
+	}
+}
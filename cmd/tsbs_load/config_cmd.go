@@ -6,6 +6,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load/config"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
@@ -25,7 +26,7 @@ func initConfigCMD() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Generate example config yaml file and save it to" + writeConfigTo,
-		Run:   config,
+		Run:   writeExampleConfig,
 	}
 
 	cmd.PersistentFlags().String(
@@ -41,7 +42,7 @@ func initConfigCMD() *cobra.Command {
 	return cmd
 }
 
-func config(cmd *cobra.Command, _ []string) {
+func writeExampleConfig(cmd *cobra.Command, _ []string) {
 	dataSourceSelected := readFlag(cmd, dataSourceFlag)
 	targetSelected := readFlag(cmd, targetDbFlag)
 
@@ -55,19 +56,19 @@ func config(cmd *cobra.Command, _ []string) {
 	fmt.Printf("Wrote example config to: %s\n", writeConfigTo)
 }
 
-func getEmptyConfigWithoutDbSpecifics(target, dataSource string) *LoadConfig {
-	loadConfig := &LoadConfig{
-		Loader: &LoaderConfig{
+func getEmptyConfigWithoutDbSpecifics(target, dataSource string) *config.LoadConfig {
+	loadConfig := &config.LoadConfig{
+		Loader: &config.LoaderConfig{
 			Target: target,
 		},
 	}
 	switch dataSource {
 	case source.FileDataSourceType:
-		loadConfig.DataSource = &DataSourceConfig{
+		loadConfig.DataSource = &config.DataSourceConfig{
 			Type: source.FileDataSourceType,
 		}
 	case source.SimulatorDataSourceType:
-		loadConfig.DataSource = &DataSourceConfig{
+		loadConfig.DataSource = &config.DataSourceConfig{
 			Type: source.SimulatorDataSourceType,
 		}
 	}
@@ -82,7 +83,7 @@ func readFlag(cmd *cobra.Command, flag string) string {
 	return val
 }
 
-func setExampleConfigInViper(confWithoutDBSpecifics *LoadConfig, t targets.ImplementedTarget) *viper.Viper {
+func setExampleConfigInViper(confWithoutDBSpecifics *config.LoadConfig, t targets.ImplementedTarget) *viper.Viper {
 	v := viper.New()
 	v.SetConfigType("yaml")
 
@@ -6,6 +6,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load/config"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
@@ -67,7 +68,7 @@ func createRunLoad(target targets.ImplementedTarget) cmdRunner {
 		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
 			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
 		}
-		bench, runner, err := parseConfig(target, viper.GetViper())
+		bench, runner, err := config.Parse(target, viper.GetViper())
 		if err != nil {
 			panic(err)
 		}
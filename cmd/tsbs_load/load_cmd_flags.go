@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/report"
 	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/load/insertstrategy"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"strings"
 	"time"
@@ -34,6 +36,26 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 		"Whether to abort if a database with the given name already exists.",
 	)
 	fs.Duration("loader.runner.reporting-period", 10*time.Second, "Period to report write stats")
+	fs.String(
+		"loader.runner.checkpoint-file",
+		"",
+		"Periodically save the progress of the load to this file, to continue a failed load from it with resume-from",
+	)
+	fs.Duration("loader.runner.checkpoint-interval", time.Minute, "Time between the checkpoints saved to checkpoint-file")
+	fs.String(
+		"loader.runner.resume-from",
+		"",
+		"Continue the load into the existing database after the data loaded up to this checkpoint file",
+	)
+	fs.Duration("loader.runner.duration", 0, "Stop reading data after this duration, e.g. 30m (0 = no time limit)")
+	fs.Bool(
+		"loader.runner.loop",
+		false,
+		"Read the data again each time it runs out, shifting its timestamps past the ones loaded, until stopped "+
+			"by duration or limit",
+	)
+	fs.String("loader.runner.report-file", "", "Also write the periodic write stats to this file")
+	fs.String("loader.runner.report-format", report.FormatCSV, "Format of the report-file: csv or jsonl")
 	fs.Int64("loader.runner.seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	fs.Bool(
 		"loader.runner.do-load",
@@ -67,6 +89,53 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 			"Default 0 means that:\n\tif hash-workers=false then capacity = 5 * number of workers\n\t"+
 			"if hash-workers=true, then capacity = 5 for each worker",
 	)
+	fs.String("loader.runner.results-file", "", "Write the test results summary json to this file")
+	fs.Bool(
+		"loader.runner.verify",
+		false,
+		"Whether to verify after the load that the database holds all rows read, failing the run on mismatches",
+	)
+	fs.Uint64(
+		"loader.runner.ingest-rate",
+		0,
+		"Limit the rows (or metrics, see ingest-rate-unit) inserted per second by all workers, 0 = no limit",
+	)
+	fs.String("loader.runner.ingest-rate-unit", insertstrategy.RateUnitRows, "Unit of the ingest-rate: rows or metrics")
+	fs.String(
+		"loader.runner.hdr-latencies",
+		"",
+		"Write the High Dynamic Range (HDR) Histogram of batch write latencies to this file.",
+	)
+	fs.Float64(
+		"loader.runner.error-budget",
+		0,
+		"Fraction of the metrics that may fail to be written before the load stops, e.g. 0.01 (0 = stop at the first failed batch)",
+	)
+	fs.String(
+		"loader.runner.metrics-listen",
+		"",
+		"Serve live metrics of the load for Prometheus at /metrics on this address, e.g. :9099",
+	)
+	fs.String(
+		"loader.runner.load-profile",
+		"",
+		"YAML file with the phases (duration and rate and/or workers) the load follows, stopping after the last one",
+	)
+	fs.String(
+		"loader.runner.coordinator-listen",
+		"",
+		"Coordinate the load of loader.runner.agents agents that join at this address, e.g. :7070, instead of loading data",
+	)
+	fs.Uint(
+		"loader.runner.agents",
+		0,
+		"Number of agents the coordinator waits for before starting them all",
+	)
+	fs.String(
+		"loader.runner.coordinator",
+		"",
+		"Load the shard of the data assigned by the coordinator at this address, e.g. coordinator-host:7070, as its agent",
+	)
 }
 
 func addDataSourceFlags(fs *pflag.FlagSet) {
@@ -78,7 +147,12 @@ func addDataSourceFlags(fs *pflag.FlagSet) {
 	fs.String(
 		"data-source.file.location",
 		"./file-from-tsbs-generate-data",
-		"If data-source.type=FILE, load the data from this file location",
+		"If data-source.type=FILE, load the data from this file location: a file, possibly compressed with gzip, zstd or lz4, or a directory or glob pattern of files loaded one after another",
+	)
+	fs.Int(
+		"data-source.file.read-ahead",
+		0,
+		"If data-source.type=FILE, decompress this many files after the one being loaded in parallel",
 	)
 	fs.String("data-source.simulator.use-case", "devops-generic", fmt.Sprintf("Use case to generate."))
 	fs.String("data-source.simulator.timestamp-start", defaultTimeStart, "Beginning timestamp (RFC3339).")
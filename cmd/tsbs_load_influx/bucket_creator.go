@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// bucketCreator is the DBCreator used with api-version 2. InfluxDB 2.x and 3.x
+// have no databases, so the db-name is used as the name of a bucket in the
+// configured organization. A DBRP mapping is created alongside the bucket so the
+// InfluxQL queries issued through the v1 compatible /query endpoint still resolve
+// the database name.
+type bucketCreator struct {
+	daemonURL string
+	orgID     string
+}
+
+// errNotFound is returned by getJSON for a 404 response
+var errNotFound = errors.New("not found")
+
+type bucketListing struct {
+	Buckets []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"buckets"`
+}
+
+func (d *bucketCreator) Init() {
+	d.daemonURL = daemonURLs[0] // pick first one since it always exists
+	orgID, err := d.lookupOrgID()
+	if err != nil {
+		fatal("could not find organization %s: %v", org, err)
+	}
+	d.orgID = orgID
+}
+
+func (d *bucketCreator) DBExists(dbName string) bool {
+	id, err := d.bucketID(dbName)
+	if err != nil {
+		fatal("could not list buckets: %v", err)
+	}
+	return id != ""
+}
+
+func (d *bucketCreator) RemoveOldDB(dbName string) error {
+	id, err := d.bucketID(dbName)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return nil
+	}
+	resp, err := d.do("DELETE", "/api/v2/buckets/"+id, nil, nil)
+	if err != nil {
+		return fmt.Errorf("drop bucket error: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("drop bucket returned non-204 code: %d", resp.StatusCode)
+	}
+	time.Sleep(time.Second)
+	return nil
+}
+
+func (d *bucketCreator) CreateDB(dbName string) error {
+	type retentionRule struct {
+		Type         string `json:"type"`
+		EverySeconds int64  `json:"everySeconds"`
+	}
+	req := struct {
+		OrgID          string          `json:"orgID"`
+		Name           string          `json:"name"`
+		RetentionRules []retentionRule `json:"retentionRules"`
+	}{
+		OrgID:          d.orgID,
+		Name:           dbName,
+		RetentionRules: []retentionRule{},
+	}
+	if retentionPeriod > 0 {
+		req.RetentionRules = append(req.RetentionRules, retentionRule{Type: "expire", EverySeconds: int64(retentionPeriod.Seconds())})
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := d.postJSON("/api/v2/buckets", req, &created); err != nil {
+		return fmt.Errorf("bad bucket create: %v", err)
+	}
+
+	dbrp := struct {
+		OrgID           string `json:"orgID"`
+		BucketID        string `json:"bucketID"`
+		Database        string `json:"database"`
+		RetentionPolicy string `json:"retention_policy"`
+		Default         bool   `json:"default"`
+	}{
+		OrgID:           d.orgID,
+		BucketID:        created.ID,
+		Database:        dbName,
+		RetentionPolicy: "autogen",
+		Default:         true,
+	}
+	if err := d.postJSON("/api/v2/dbrps", dbrp, nil); err != nil {
+		return fmt.Errorf("bad dbrp mapping create: %v", err)
+	}
+
+	time.Sleep(time.Second)
+	return nil
+}
+
+func (d *bucketCreator) lookupOrgID() (string, error) {
+	v := url.Values{}
+	v.Set("org", org)
+	var listing struct {
+		Orgs []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"orgs"`
+	}
+	if err := d.getJSON("/api/v2/orgs", v, &listing); err != nil {
+		return "", err
+	}
+	for _, o := range listing.Orgs {
+		if o.Name == org {
+			return o.ID, nil
+		}
+	}
+	return "", fmt.Errorf("organization not found")
+}
+
+// bucketID returns the ID of the bucket with the given name, or an empty string
+// if no such bucket exists in the organization.
+func (d *bucketCreator) bucketID(name string) (string, error) {
+	v := url.Values{}
+	v.Set("orgID", d.orgID)
+	v.Set("name", name)
+	var listing bucketListing
+	err := d.getJSON("/api/v2/buckets", v, &listing)
+	// A missing bucket is reported as 404 by some server versions.
+	if errors.Is(err, errNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, b := range listing.Buckets {
+		if b.Name == name {
+			return b.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func (d *bucketCreator) getJSON(path string, params url.Values, out interface{}) error {
+	resp, err := d.do("GET", path, params, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("GET %s: %w: %s", path, errNotFound, body)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned non-200 code %d: %s", path, resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, out)
+}
+
+func (d *bucketCreator) postJSON(path string, in, out interface{}) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := d.do("POST", path, nil, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("POST %s returned code %d: %s", path, resp.StatusCode, body)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// do sends a request to the API path, relative to the path of the daemon URL,
+// if any, e.g. of an InfluxDB behind a reverse proxy
+func (d *bucketCreator) do(method, path string, params url.Values, body io.Reader) (*http.Response, error) {
+	u, err := url.Parse(d.daemonURL)
+	if err != nil {
+		return nil, err
+	}
+	u = u.JoinPath(path)
+	if params != nil {
+		u.RawQuery = params.Encode()
+	}
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set(headerAuthorization, "Token "+token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return http.DefaultClient.Do(req)
+}
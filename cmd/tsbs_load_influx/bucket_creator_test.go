@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBucketCreatorPaths(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/influx/api/v2/orgs":
+			w.Write([]byte(`{"orgs":[{"id":"o1","name":"tsbs"}]}`))
+		case "/influx/api/v2/buckets":
+			http.NotFound(w, r)
+		default:
+			t.Errorf("unexpected request of %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	oldOrg := org
+	defer func() { org = oldOrg }()
+	org = "tsbs"
+	d := &bucketCreator{daemonURL: srv.URL + "/influx/"}
+	id, err := d.lookupOrgID()
+	if err != nil || id != "o1" {
+		t.Fatalf("incorrect org ID: got %q, %v want %q", id, err, "o1")
+	}
+	d.orgID = id
+	// a missing bucket may be reported as 404
+	if id, err := d.bucketID("benchmark"); err != nil || id != "" {
+		t.Errorf("incorrect bucket ID of a missing bucket: got %q, %v", id, err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("incorrect requests: got %q", paths)
+	}
+}
+
+func TestBucketCreatorOrgsNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	d := &bucketCreator{daemonURL: srv.URL}
+	if _, err := d.lookupOrgID(); err == nil {
+		t.Errorf("expected error when the orgs API is not found")
+	}
+}
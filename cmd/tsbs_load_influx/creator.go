@@ -118,3 +118,65 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	time.Sleep(time.Second)
 	return nil
 }
+
+// StorageBytes returns the disk size of the shards of the database, as
+// reported by SHOW STATS of the first daemon. Data still in the WAL or cache
+// is not counted until it is compacted into TSM files.
+func (d *dbCreator) StorageBytes(dbName string) (int64, error) {
+	u := fmt.Sprintf("%s/query?q=%s", d.daemonURL, url.QueryEscape("SHOW STATS"))
+	resp, err := http.Get(u)
+	if err != nil {
+		return 0, fmt.Errorf("show stats error: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return 0, fmt.Errorf("show stats returned non-200 code: %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	return shardDiskBytes(body, dbName)
+}
+
+// shardDiskBytes sums the diskBytes of the shards of the database in a SHOW
+// STATS response, e.g.:
+// {"results":[{"series":[{"name":"shard","tags":{"database":"benchmark",...},"columns":["diskBytes",...],"values":[[1024,...]]}]}]}
+func shardDiskBytes(body []byte, dbName string) (int64, error) {
+	type statsType struct {
+		Results []struct {
+			Series []struct {
+				Name    string
+				Tags    map[string]string
+				Columns []string
+				Values  [][]interface{}
+			}
+		}
+	}
+	var stats statsType
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return 0, err
+	}
+	if len(stats.Results) == 0 {
+		return 0, fmt.Errorf("show stats returned no results")
+	}
+
+	var total int64
+	for _, series := range stats.Results[0].Series {
+		if series.Name != "shard" || series.Tags["database"] != dbName {
+			continue
+		}
+		for i, col := range series.Columns {
+			if col != "diskBytes" {
+				continue
+			}
+			for _, row := range series.Values {
+				if v, ok := row[i].(float64); ok {
+					total += int64(v)
+				}
+			}
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestShardDiskBytes(t *testing.T) {
+	body := []byte(`{"results":[{"statement_id":0,"series":[` +
+		`{"name":"shard","tags":{"database":"benchmark","id":"2"},"columns":["diskBytes","fieldsCreate"],"values":[[1024,10]]},` +
+		`{"name":"shard","tags":{"database":"benchmark","id":"3"},"columns":["fieldsCreate","diskBytes"],"values":[[10,2048]]},` +
+		`{"name":"shard","tags":{"database":"_internal","id":"1"},"columns":["diskBytes"],"values":[[4096]]},` +
+		`{"name":"tsm1_filestore","tags":{"database":"benchmark","id":"2"},"columns":["diskBytes"],"values":[[1024]]}]}]}`)
+
+	got, err := shardDiskBytes(body, "benchmark")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 3072 {
+		t.Errorf("incorrect disk bytes: got %d want %d", got, 3072)
+	}
+
+	if _, err := shardDiskBytes([]byte(`{"results":[]}`), "benchmark"); err == nil {
+		t.Errorf("expected error for empty results")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/valyala/fasthttp"
 )
 
@@ -15,6 +16,10 @@ const (
 	httpClientName        = "tsbs_load_influx"
 	headerContentEncoding = "Content-Encoding"
 	headerGzip            = "gzip"
+	headerAuthorization   = "Authorization"
+
+	apiV1 = 1
+	apiV2 = 2
 )
 
 var (
@@ -38,26 +43,49 @@ type HTTPWriterConfig struct {
 
 	// Debug label for more informative errors.
 	DebugInfo string
+
+	// APIVersion selects the write endpoint: 1 for /write, 2 for /api/v2/write.
+	// The zero value is treated as 1.
+	APIVersion int
+
+	// Organization owning the target bucket (API v2 only).
+	Org string
+
+	// Token used in the Authorization header (API v2 only).
+	Token string
+
+	// Precision of the timestamps in the written lines (API v2 only).
+	Precision string
 }
 
 // HTTPWriter is a Writer that writes to an InfluxDB HTTP server.
 type HTTPWriter struct {
 	client fasthttp.Client
 
-	c   HTTPWriterConfig
-	url []byte
+	c    HTTPWriterConfig
+	url  []byte
+	auth []byte
 }
 
 // NewHTTPWriter returns a new HTTPWriter from the supplied HTTPWriterConfig.
+// For API v2 the database name is used as the bucket and consistency is ignored.
 func NewHTTPWriter(c HTTPWriterConfig, consistency string) *HTTPWriter {
-	return &HTTPWriter{
+	w := &HTTPWriter{
 		client: fasthttp.Client{
 			Name: httpClientName,
 		},
 
-		c:   c,
-		url: []byte(c.Host + "/write?consistency=" + consistency + "&db=" + url.QueryEscape(c.Database)),
+		c: c,
 	}
+	if c.APIVersion == apiV2 {
+		w.url = []byte(c.Host + "/api/v2/write?org=" + url.QueryEscape(c.Org) + "&bucket=" + url.QueryEscape(c.Database) + "&precision=" + c.Precision)
+	} else {
+		w.url = []byte(c.Host + "/write?consistency=" + consistency + "&db=" + url.QueryEscape(c.Database))
+	}
+	if c.Token != "" {
+		w.auth = []byte("Token " + c.Token)
+	}
+	return w
 }
 
 var (
@@ -72,6 +100,9 @@ func (w *HTTPWriter) initializeReq(req *fasthttp.Request, body []byte, isGzip bo
 	if isGzip {
 		req.Header.Add(headerContentEncoding, headerGzip)
 	}
+	if len(w.auth) > 0 {
+		req.Header.SetBytesV(headerAuthorization, w.auth)
+	}
 	req.SetBody(body)
 }
 
@@ -84,7 +115,8 @@ func (w *HTTPWriter) executeReq(req *fasthttp.Request, resp *fasthttp.Response)
 		if sc == 500 && backpressurePred(resp.Body()) {
 			err = errBackoff
 		} else if sc != fasthttp.StatusNoContent {
-			err = fmt.Errorf("[DebugInfo: %s] Invalid write response (status %d): %s", w.c.DebugInfo, sc, resp.Body())
+			statusErr := &targets.HTTPStatusError{Status: fmt.Sprintf("%d %s", sc, fasthttp.StatusMessage(sc)), StatusCode: sc, Body: string(resp.Body())}
+			err = fmt.Errorf("[DebugInfo: %s] Invalid write response: %w", w.c.DebugInfo, statusErr)
 		}
 	}
 	return lat, err
@@ -109,6 +109,35 @@ func TestNewHTTPWriter(t *testing.T) {
 	}
 }
 
+func TestNewHTTPWriterAPIV2(t *testing.T) {
+	conf := testConf
+	conf.APIVersion = apiV2
+	conf.Org = "my org"
+	conf.Token = "secret"
+	conf.Precision = "ns"
+	w := NewHTTPWriter(conf, testConsistency)
+
+	want := conf.Host + "/api/v2/write?org=my+org&bucket=test&precision=ns"
+	if got := string(w.url); got != want {
+		t.Errorf("incorrect v2 url: got %s want %s", got, want)
+	}
+
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	w.initializeReq(req, []byte("body"), false)
+	if got := string(req.Header.Peek(headerAuthorization)); got != "Token secret" {
+		t.Errorf("incorrect Authorization header: got %s want %s", got, "Token secret")
+	}
+
+	// v1 writer without a token must not send an Authorization header
+	w = NewHTTPWriter(testConf, testConsistency)
+	req.Reset()
+	w.initializeReq(req, []byte("body"), false)
+	if got := string(req.Header.Peek(headerAuthorization)); got != "" {
+		t.Errorf("Authorization header is not empty: got %s", got)
+	}
+}
+
 func TestHTTPWriterInitializeReq(t *testing.T) {
 	req := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(req)
@@ -5,7 +5,6 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"log"
@@ -30,6 +29,11 @@ var (
 	useGzip           bool
 	doAbortOnExist    bool
 	consistency       string
+	apiVersion        int
+	org               string
+	token             string
+	precision         string
+	retentionPeriod   time.Duration
 )
 
 // Global vars
@@ -47,6 +51,13 @@ var consistencyChoices = map[string]struct{}{
 	"all":    {},
 }
 
+var precisionChoices = map[string]struct{}{
+	"ns": {},
+	"us": {},
+	"ms": {},
+	"s":  {},
+}
+
 // allows for testing
 var fatal = log.Fatalf
 
@@ -75,11 +86,29 @@ func init() {
 	consistency = viper.GetString("consistency")
 	backoff = viper.GetDuration("backoff")
 	useGzip = viper.GetBool("gzip")
+	apiVersion = viper.GetInt("api-version")
+	org = viper.GetString("org")
+	token = viper.GetString("token")
+	precision = viper.GetString("precision")
+	retentionPeriod = viper.GetDuration("retention-period")
 
 	if _, ok := consistencyChoices[consistency]; !ok {
 		log.Fatalf("invalid consistency settings")
 	}
 
+	switch apiVersion {
+	case apiV1:
+	case apiV2:
+		if org == "" {
+			log.Fatal("missing 'org' flag, required with api-version 2")
+		}
+		if _, ok := precisionChoices[precision]; !ok {
+			log.Fatalf("invalid precision settings")
+		}
+	default:
+		log.Fatalf("invalid api-version: %d", apiVersion)
+	}
+
 	daemonURLs = strings.Split(csvDaemonURLs, ",")
 	if len(daemonURLs) == 0 {
 		log.Fatal("missing 'urls' flag")
@@ -91,7 +120,7 @@ func init() {
 type benchmark struct{}
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
+	return newFileDataSource(config.FileName, config.ReadAhead)
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
@@ -107,6 +136,9 @@ func (b *benchmark) GetProcessor() targets.Processor {
 }
 
 func (b *benchmark) GetDBCreator() targets.DBCreator {
+	if apiVersion == apiV2 {
+		return &bucketCreator{}
+	}
 	return &dbCreator{}
 }
 
@@ -23,9 +23,13 @@ type processor struct {
 func (p *processor) Init(numWorker int, _, _ bool) {
 	daemonURL := daemonURLs[numWorker%len(daemonURLs)]
 	cfg := HTTPWriterConfig{
-		DebugInfo: fmt.Sprintf("worker #%d, dest url: %s", numWorker, daemonURL),
-		Host:      daemonURL,
-		Database:  loader.DatabaseName(),
+		DebugInfo:  fmt.Sprintf("worker #%d, dest url: %s", numWorker, daemonURL),
+		Host:       daemonURL,
+		Database:   loader.DatabaseName(),
+		APIVersion: apiVersion,
+		Org:        org,
+		Token:      token,
+		Precision:  precision,
 	}
 	w := NewHTTPWriter(cfg, consistency)
 	p.initWithHTTPWriter(numWorker, w)
@@ -44,7 +48,18 @@ func (p *processor) Close(_ bool) {
 }
 
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	res := p.ProcessBatchWithResult(b, doLoad)
+	if res.Err != nil {
+		fatal("Error writing: %s\n", res.Err.Error())
+	}
+	return res.Metrics, res.Rows
+}
+
+// ProcessBatchWithResult writes the batch, retrying for as long as the
+// server asks to back off, and fails it as a whole on any other error
+func (p *processor) ProcessBatchWithResult(b targets.Batch, doLoad bool) targets.BatchResult {
 	batch := b.(*batch)
+	var res targets.BatchResult
 
 	// Write the batch: try until backoff is not needed.
 	if doLoad {
@@ -70,16 +85,17 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 			}
 		}
 		if err != nil {
-			fatal("Error writing: %s\n", err.Error())
+			res.Fail(batch.metrics, uint64(batch.rows), err, targets.ClassifyError(err))
 		}
 	}
-	metricCnt := batch.metrics
-	rowCnt := batch.rows
+	if res.Err == nil {
+		res.Metrics, res.Rows = batch.metrics, uint64(batch.rows)
+	}
 
 	// Return the batch buffer to the pool.
 	batch.buf.Reset()
 	bufPool.Put(batch.buf)
-	return metricCnt, uint64(rowCnt)
+	return res
 }
 
 func (p *processor) processBackoffMessages(workerID int) {
@@ -5,9 +5,11 @@ import (
 	"bytes"
 	"strings"
 
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/influx"
 )
 
 const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
@@ -15,22 +17,58 @@ const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
 var newLine = []byte("\n")
 
 type fileDataSource struct {
+	reader  *load.RewindableReader
 	scanner *bufio.Scanner
+	shift   data.TimeShift
+}
+
+func newFileDataSource(fileName string, readAhead int) *fileDataSource {
+	br := load.GetRewindableReader(fileName, readAhead)
+	return &fileDataSource{reader: br, scanner: bufio.NewScanner(br)}
 }
 
 func (d *fileDataSource) NextItem() data.LoadedPoint {
 	ok := d.scanner.Scan()
 	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+		if d.reader.NextFile() {
+			d.scanner = bufio.NewScanner(d.reader)
+			return d.NextItem()
+		}
 		return data.LoadedPoint{}
 	} else if !ok {
 		fatal("scan error: %v", d.scanner.Err())
 		return data.LoadedPoint{}
 	}
-	return data.NewLoadedPoint(d.scanner.Bytes())
+	line, err := influx.ShiftTimestamp(d.scanner.Bytes(), &d.shift)
+	if err != nil {
+		fatal("%v", err)
+		return data.LoadedPoint{}
+	}
+	return data.NewLoadedPoint(line)
 }
 
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders { return nil }
 
+// Shard reads only the lines of the shard-th of shards byte ranges of the
+// data file, if it is a single uncompressed file
+func (d *fileDataSource) Shard(shard, shards int) bool {
+	if !d.reader.ShardLines(shard, shards, load.LineShards{}) {
+		return false
+	}
+	d.scanner = bufio.NewScanner(d.reader)
+	return true
+}
+
+// Rewind reads the file again, with the timestamps shifted past the ones read
+// before
+func (d *fileDataSource) Rewind() bool {
+	if !d.shift.Next() || !d.reader.Rewind() {
+		return false
+	}
+	d.scanner = bufio.NewScanner(d.reader)
+	return true
+}
+
 type batch struct {
 	buf     *bytes.Buffer
 	rows    uint
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// verifyQueriesFmt counts the points, finds the first and last point and
+// counts the series of a measurement, in that order
+const verifyQueriesFmt = `SELECT count(*) FROM "%[1]s"; ` +
+	`SELECT * FROM "%[1]s" ORDER BY time ASC LIMIT 1; ` +
+	`SELECT * FROM "%[1]s" ORDER BY time DESC LIMIT 1; ` +
+	`SHOW SERIES EXACT CARDINALITY FROM "%[1]s"`
+
+// TrackPoint adds the line of the point to stats. The series key, i.e. the
+// measurement and its tags, is the tag set.
+func (d *dbCreator) TrackPoint(p data.LoadedPoint, stats *targets.DataStats) {
+	line := p.Data.([]byte)
+	args := bytes.Split(line, []byte(" "))
+	if len(args) != 3 {
+		fatal(errNotThreeTuplesFmt, len(args))
+		return
+	}
+	ns, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		fatal("invalid timestamp: %v", err)
+		return
+	}
+	measurement := string(bytes.SplitN(args[0], []byte(","), 2)[0])
+	stats.Add(measurement, time.Unix(0, ns), string(args[0]))
+}
+
+// Verify returns the number of points, the time range and the number of
+// series of each measurement
+func (d *dbCreator) Verify(dbName string, measurements []string) (map[string]targets.MeasurementStats, error) {
+	stats := make(map[string]targets.MeasurementStats, len(measurements))
+	for _, m := range measurements {
+		v := url.Values{}
+		v.Set("db", dbName)
+		v.Set("epoch", "ns")
+		v.Set("q", fmt.Sprintf(verifyQueriesFmt, m))
+		resp, err := http.Get(fmt.Sprintf("%s/query?%s", d.daemonURL, v.Encode()))
+		if err != nil {
+			return nil, fmt.Errorf("verify error: %s", err.Error())
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("verify returned non-200 code: %d", resp.StatusCode)
+		}
+
+		s, err := parseVerifyResponse(body)
+		if err != nil {
+			return nil, fmt.Errorf("could not verify measurement %s: %v", m, err)
+		}
+		stats[m] = s
+	}
+	return stats, nil
+}
+
+// parseVerifyResponse parses the results of the verify queries. Numbers are
+// decoded as json.Number, since nanosecond timestamps do not fit a float64.
+func parseVerifyResponse(body []byte) (targets.MeasurementStats, error) {
+	var resp verifyResponse
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return targets.MeasurementStats{}, err
+	}
+	if len(resp.Results) != 4 {
+		return targets.MeasurementStats{}, fmt.Errorf("expected 4 results, got %d", len(resp.Results))
+	}
+
+	// count(*) counts every field separately, the series cardinality is
+	// in a single count column
+	rows, err := resp.maxColumn(0, "count")
+	if err != nil {
+		return targets.MeasurementStats{}, err
+	}
+	minTime, err := resp.maxColumn(1, "time")
+	if err != nil {
+		return targets.MeasurementStats{}, err
+	}
+	maxTime, err := resp.maxColumn(2, "time")
+	if err != nil {
+		return targets.MeasurementStats{}, err
+	}
+	series, err := resp.maxColumn(3, "count")
+	if err != nil {
+		return targets.MeasurementStats{}, err
+	}
+	return targets.MeasurementStats{
+		Rows:    uint64(rows),
+		MinTime: time.Unix(0, minTime),
+		MaxTime: time.Unix(0, maxTime),
+		TagSets: uint64(series),
+	}, nil
+}
+
+type verifyResponse struct {
+	Results []struct {
+		Error  string
+		Series []struct {
+			Columns []string
+			Values  [][]interface{}
+		}
+	}
+}
+
+// maxColumn returns the largest value in the first row of the i-th result of
+// all columns whose name starts with prefix, or 0 if the result has no rows
+func (r *verifyResponse) maxColumn(i int, prefix string) (int64, error) {
+	result := r.Results[i]
+	if result.Error != "" {
+		return 0, errors.New(result.Error)
+	}
+	if len(result.Series) == 0 || len(result.Series[0].Values) == 0 {
+		return 0, nil
+	}
+	var max int64
+	for j, col := range result.Series[0].Columns {
+		if !strings.HasPrefix(col, prefix) {
+			continue
+		}
+		n, ok := result.Series[0].Values[0][j].(json.Number)
+		if !ok {
+			continue
+		}
+		v, err := n.Int64()
+		if err != nil {
+			return 0, err
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max, nil
+}
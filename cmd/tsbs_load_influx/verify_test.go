@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestDBCreatorTrackPoint(t *testing.T) {
+	d := &dbCreator{}
+	stats := targets.NewDataStats()
+	lines := []string{
+		"cpu,hostname=host_0 usage_user=1 1451606400000000001",
+		"cpu,hostname=host_1 usage_user=1 1451606410000000000",
+		"cpu,hostname=host_0 usage_user=1 1451606420000000000",
+		"mem,hostname=host_0 used=1 1451606400000000000",
+	}
+	for _, l := range lines {
+		d.TrackPoint(data.NewLoadedPoint([]byte(l)), stats)
+	}
+
+	want := targets.MeasurementStats{
+		Rows:    3,
+		MinTime: time.Unix(0, 1451606400000000001),
+		MaxTime: time.Unix(1451606420, 0),
+		TagSets: 2,
+	}
+	if got := stats.Get("cpu"); got != want {
+		t.Errorf("incorrect cpu stats: got %+v want %+v", got, want)
+	}
+	if got := stats.Get("mem"); got.Rows != 1 || got.TagSets != 1 {
+		t.Errorf("incorrect mem stats: got %+v", got)
+	}
+}
+
+func TestParseVerifyResponse(t *testing.T) {
+	body := []byte(`{"results":[` +
+		`{"statement_id":0,"series":[{"name":"cpu","columns":["time","count_usage_user","count_usage_system"],"values":[[0,300,299]]}]},` +
+		`{"statement_id":1,"series":[{"name":"cpu","columns":["time","hostname","usage_user"],"values":[[1451606400000000001,"host_0",1]]}]},` +
+		`{"statement_id":2,"series":[{"name":"cpu","columns":["time","hostname","usage_user"],"values":[[1451606420000000000,"host_0",1]]}]},` +
+		`{"statement_id":3,"series":[{"columns":["count"],"values":[[2]]}]}]}`)
+
+	got, err := parseVerifyResponse(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := targets.MeasurementStats{
+		Rows:    300,
+		MinTime: time.Unix(0, 1451606400000000001),
+		MaxTime: time.Unix(1451606420, 0),
+		TagSets: 2,
+	}
+	if got != want {
+		t.Errorf("incorrect stats: got %+v want %+v", got, want)
+	}
+
+	empty := []byte(`{"results":[{"statement_id":0},{"statement_id":1},{"statement_id":2},{"statement_id":3}]}`)
+	if got, err := parseVerifyResponse(empty); err != nil || got.Rows != 0 || got.TagSets != 0 {
+		t.Errorf("incorrect stats of empty measurement: got %+v, %v", got, err)
+	}
+
+	failed := []byte(`{"results":[{"statement_id":0,"error":"database not found"},{"statement_id":1},{"statement_id":2},{"statement_id":3}]}`)
+	if _, err := parseVerifyResponse(failed); err == nil {
+		t.Errorf("expected error for failed query")
+	}
+}
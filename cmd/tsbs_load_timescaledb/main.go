@@ -94,7 +94,7 @@ func main() {
 
 	benchmark, err := timescaledb.NewBenchmark(loaderConf.DBName, opts, &source.DataSourceConfig{
 		Type: source.FileDataSourceType,
-		File: &source.FileDataSourceConfig{Location: loaderConf.FileName},
+		File: &source.FileDataSourceConfig{Location: loaderConf.FileName, ReadAhead: loaderConf.ReadAhead},
 	})
 	if err != nil {
 		panic(err)
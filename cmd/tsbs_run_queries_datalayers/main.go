@@ -6,6 +6,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
+	loadconfig "github.com/timescale/tsbs/load/config"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
@@ -43,6 +44,13 @@ func init() {
 
 	// Initialize the runner.
 	runner = query.NewBenchmarkRunner(config)
+	if len(config.LoadConfig) > 0 {
+		workload, err := loadconfig.NewWorkload(config.LoadConfig)
+		if err != nil {
+			panic(fmt.Errorf("unable to parse load config: %s", err))
+		}
+		runner.SetWorkload(workload)
+	}
 }
 
 func main() {
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -15,6 +17,20 @@ import (
 
 var bytesSlash = []byte("/") // heap optimization
 
+// apiV2Prefix marks query paths that target the InfluxDB 2.x/3.x API. Those are
+// scoped by organization instead of database.
+var apiV2Prefix = []byte("/api/v2/")
+
+const (
+	contentTypeFlux = "application/vnd.flux"
+	acceptCSV       = "application/csv"
+)
+
+var (
+	newLine       = []byte("\n")
+	fluxCSVHeader = []byte(",result,")
+)
+
 // HTTPClient is a reusable HTTP Client.
 type HTTPClient struct {
 	//client     fasthttp.Client
@@ -30,6 +46,8 @@ type HTTPClientDoOptions struct {
 	PrettyPrintResponses bool
 	chunkSize            uint64
 	database             string
+	org                  string
+	token                string
 }
 
 var httpClientOnce = sync.Once{}
@@ -63,17 +81,35 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 	w.uri = append(w.uri, w.Host...)
 	//w.uri = append(w.uri, bytesSlash...)
 	w.uri = append(w.uri, q.Path...)
-	w.uri = append(w.uri, []byte("&db="+url.QueryEscape(opts.database))...)
-	if opts.chunkSize > 0 {
-		s := fmt.Sprintf("&chunked=true&chunk_size=%d", opts.chunkSize)
-		w.uri = append(w.uri, []byte(s)...)
+	isFlux := bytes.HasPrefix(q.Path, apiV2Prefix)
+	if isFlux {
+		w.uri = append(w.uri, []byte("?org="+url.QueryEscape(opts.org))...)
+	} else {
+		// InfluxQL goes through the v1 compatible endpoint, on 2.x/3.x the
+		// database is resolved through the DBRP mapping created by the loader.
+		w.uri = append(w.uri, []byte("&db="+url.QueryEscape(opts.database))...)
+		if opts.chunkSize > 0 {
+			s := fmt.Sprintf("&chunked=true&chunk_size=%d", opts.chunkSize)
+			w.uri = append(w.uri, []byte(s)...)
+		}
 	}
 
 	// populate a request with data from the Query:
-	req, err := http.NewRequest(string(q.Method), string(w.uri), nil)
+	var body io.Reader
+	if len(q.Body) > 0 {
+		body = bytes.NewReader(q.Body)
+	}
+	req, err := http.NewRequest(string(q.Method), string(w.uri), body)
 	if err != nil {
 		panic(err)
 	}
+	if opts.token != "" {
+		req.Header.Set("Authorization", "Token "+opts.token)
+	}
+	if isFlux {
+		req.Header.Set("Content-Type", contentTypeFlux)
+		req.Header.Set("Accept", acceptCSV)
+	}
 
 	// Perform the request while tracking latency:
 	start := time.Now()
@@ -86,8 +122,8 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 		panic("http request did not return status 200 OK")
 	}
 
-	var body []byte
-	body, err = ioutil.ReadAll(resp.Body)
+	var respBody []byte
+	respBody, err = ioutil.ReadAll(resp.Body)
 
 	if err != nil {
 		panic(err)
@@ -95,7 +131,22 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 
 	lag = float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
 
+	fluxRows := 0
+	if isFlux {
+		// a missing bucket fails the request, but a query of a time range or
+		// bucket without data, e.g. generated for another dataset, succeeds
+		// with an empty result
+		fluxRows = countFluxCSVRows(respBody)
+		if fluxRows == 0 {
+			warnEmptyFluxResult(q)
+		}
+	}
+
 	if opts != nil {
+		if isFlux && opts.Debug > 0 {
+			fmt.Fprintf(os.Stderr, "debug: %s returned %d rows\n", q.HumanLabel, fluxRows)
+		}
+
 		// Print debug messages, if applicable:
 		switch opts.Debug {
 		case 1:
@@ -108,22 +159,28 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 		case 4:
 			fmt.Fprintf(os.Stderr, "debug: %s in %7.2fms -- %s\n", q.HumanLabel, lag, q.HumanDescription)
 			fmt.Fprintf(os.Stderr, "debug:   request: %s\n", string(q.String()))
-			fmt.Fprintf(os.Stderr, "debug:   response: %s\n", string(body))
+			fmt.Fprintf(os.Stderr, "debug:   response: %s\n", string(respBody))
 		default:
 		}
 
 		// Pretty print JSON responses, if applicable:
 		if opts.PrettyPrintResponses {
 			// Assumes the response is JSON! This holds for Influx
-			// and Elastic.
+			// and Elastic. Flux responses are CSV and are printed as is.
 
 			prefix := fmt.Sprintf("ID %d: ", q.GetID())
 			var v interface{}
 			var line []byte
 			full := make(map[string]interface{})
-			full["influxql"] = string(q.RawQuery)
-			json.Unmarshal(body, &v)
-			full["response"] = v
+			if isFlux {
+				full["flux"] = string(q.RawQuery)
+				full["rows"] = fluxRows
+				full["response"] = string(respBody)
+			} else {
+				full["influxql"] = string(q.RawQuery)
+				json.Unmarshal(respBody, &v)
+				full["response"] = v
+			}
 			line, err = json.MarshalIndent(full, prefix, "  ")
 			if err != nil {
 				return
@@ -134,3 +191,31 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 
 	return lag, err
 }
+
+// emptyFluxResults holds the labels of the queries already warned about
+// returning no rows
+var emptyFluxResults sync.Map
+
+// warnEmptyFluxResult warns, once per kind of query, that a Flux query
+// returned no rows
+func warnEmptyFluxResult(q *query.HTTP) {
+	if _, warned := emptyFluxResults.LoadOrStore(string(q.HumanLabel), true); warned {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s returned no rows, the query found no data in its time range or bucket -- %s\n", q.HumanLabel, q.HumanDescription)
+}
+
+// countFluxCSVRows returns the number of data rows in a Flux CSV response.
+// Every table in the response starts with its own header row and tables are
+// separated by empty lines, neither of which is counted.
+func countFluxCSVRows(body []byte) int {
+	rows := 0
+	for _, line := range bytes.Split(body, newLine) {
+		line = bytes.TrimRight(line, "\r")
+		if len(line) == 0 || bytes.HasPrefix(line, fluxCSVHeader) {
+			continue
+		}
+		rows++
+	}
+	return rows
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestCountFluxCSVRows(t *testing.T) {
+	cases := []struct {
+		desc string
+		body string
+		want int
+	}{
+		{desc: "empty", body: "", want: 0},
+		{desc: "header only", body: ",result,table,_time,_value\r\n\r\n", want: 0},
+		{
+			desc: "two tables",
+			body: ",result,table,_time,_value\r\n,_result,0,2016-01-01T00:00:00Z,1\r\n,_result,0,2016-01-01T00:01:00Z,2\r\n\r\n" +
+				",result,table,_time,_value\r\n,_result,1,2016-01-01T00:00:00Z,3\r\n\r\n",
+			want: 3,
+		},
+	}
+	for _, c := range cases {
+		if got := countFluxCSVRows([]byte(c.body)); got != c.want {
+			t.Errorf("%s: incorrect rows: got %d want %d", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestDoWarnsEmptyFluxResult(t *testing.T) {
+	body := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	w := NewHTTPClient(server.URL)
+	opts := &HTTPClientDoOptions{org: "org"}
+	do := func(label string) {
+		q := &query.HTTP{
+			HumanLabel: []byte(label),
+			Method:     []byte("POST"),
+			Path:       []byte("/api/v2/query"),
+			Body:       []byte(`from(bucket: "benchmark")`),
+		}
+		if _, err := w.Do(q, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	do("Flux empty")
+	if _, warned := emptyFluxResults.Load("Flux empty"); !warned {
+		t.Errorf("no warning for a Flux query returning no rows")
+	}
+	body = ",result,table,_time,_value\r\n,_result,0,2016-01-01T00:00:00Z,1\r\n"
+	do("Flux rows")
+	if _, warned := emptyFluxResults.Load("Flux rows"); warned {
+		t.Errorf("warning for a Flux query returning rows")
+	}
+}
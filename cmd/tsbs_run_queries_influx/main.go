@@ -13,6 +13,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
+	loadconfig "github.com/timescale/tsbs/load/config"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
@@ -20,6 +21,8 @@ import (
 var (
 	daemonUrls []string
 	chunkSize  uint64
+	org        string
+	token      string
 )
 
 // Global vars:
@@ -35,6 +38,8 @@ func init() {
 
 	pflag.String("urls", "http://localhost:8086", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
 	pflag.Uint64("chunk-response-size", 0, "Number of series to chunk results into. 0 means no chunking.")
+	pflag.String("org", "", "Organization to run /api/v2/query requests in (InfluxDB 2.x/3.x only).")
+	pflag.String("token", "", "Authentication token sent in the Authorization header (InfluxDB 2.x/3.x only).")
 
 	pflag.Parse()
 
@@ -50,6 +55,8 @@ func init() {
 
 	csvDaemonUrls = viper.GetString("urls")
 	chunkSize = viper.GetUint64("chunk-response-size")
+	org = viper.GetString("org")
+	token = viper.GetString("token")
 
 	daemonUrls = strings.Split(csvDaemonUrls, ",")
 	if len(daemonUrls) == 0 {
@@ -57,6 +64,13 @@ func init() {
 	}
 
 	runner = query.NewBenchmarkRunner(config)
+	if len(config.LoadConfig) > 0 {
+		workload, err := loadconfig.NewWorkload(config.LoadConfig)
+		if err != nil {
+			panic(fmt.Errorf("unable to parse load config: %s", err))
+		}
+		runner.SetWorkload(workload)
+	}
 }
 
 func main() {
@@ -76,6 +90,8 @@ func (p *processor) Init(workerNumber int) {
 		PrettyPrintResponses: runner.DoPrintResponses(),
 		chunkSize:            chunkSize,
 		database:             runner.DatabaseName(),
+		org:                  org,
+		token:                token,
 	}
 	url := daemonUrls[workerNumber%len(daemonUrls)]
 	p.w = NewHTTPClient(url)
@@ -0,0 +1,97 @@
+// tsbs_run_queries_prometheus speed tests Prometheus using requests from stdin.
+//
+// It reads encoded Query objects from stdin, and makes concurrent requests
+// to the Prometheus HTTP API (/api/v1/query and /api/v1/query_range) of the
+// provided endpoints.
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+	loadconfig "github.com/timescale/tsbs/load/config"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/query/prometheus"
+)
+
+// Program option vars:
+var (
+	daemonUrls []string
+)
+
+// Global vars:
+var (
+	runner *query.BenchmarkRunner
+)
+
+// Parse args:
+func init() {
+	var config query.BenchmarkRunnerConfig
+	config.AddToFlagSet(pflag.CommandLine)
+	var csvDaemonUrls string
+
+	pflag.String("urls", "http://localhost:9090", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
+
+	pflag.Parse()
+
+	err := utils.SetupConfigFile()
+
+	if err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+
+	if err := viper.Unmarshal(&config); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+
+	csvDaemonUrls = viper.GetString("urls")
+
+	daemonUrls = strings.Split(csvDaemonUrls, ",")
+	if len(daemonUrls) == 0 {
+		log.Fatal("missing 'urls' flag")
+	}
+
+	runner = query.NewBenchmarkRunner(config)
+	if len(config.LoadConfig) > 0 {
+		workload, err := loadconfig.NewWorkload(config.LoadConfig)
+		if err != nil {
+			panic(fmt.Errorf("unable to parse load config: %s", err))
+		}
+		runner.SetWorkload(workload)
+	}
+}
+
+func main() {
+	runner.Run(&query.HTTPPool, newProcessor)
+}
+
+type processor struct {
+	w    *prometheus.HTTPClient
+	opts *prometheus.HTTPClientDoOptions
+}
+
+func newProcessor() query.Processor { return &processor{} }
+
+func (p *processor) Init(workerNumber int) {
+	p.opts = &prometheus.HTTPClientDoOptions{
+		Debug:                runner.DebugLevel(),
+		PrettyPrintResponses: runner.DoPrintResponses(),
+	}
+	url := daemonUrls[workerNumber%len(daemonUrls)]
+	p.w = prometheus.NewHTTPClient(url)
+}
+
+func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(hq, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
@@ -0,0 +1,188 @@
+// tsbs_run_queries_questdb speed tests QuestDB using requests from stdin or file
+//
+// It reads encoded Query objects from stdin or file, and makes concurrent requests
+// to the PostgreSQL wire protocol endpoint of the provided QuestDB hosts.
+// This program has no knowledge of the internals of the endpoint.
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blagojts/viper"
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+	loadconfig "github.com/timescale/tsbs/load/config"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	pgxDriver = "pgx"
+	// questdbName is the name of the only database of a QuestDB server
+	questdbName = "qdb"
+)
+
+// Program option vars:
+var (
+	hostList []string
+	user     string
+	pass     string
+	port     string
+)
+
+// Global vars:
+var (
+	runner *query.BenchmarkRunner
+)
+
+// Parse args:
+func init() {
+	var config query.BenchmarkRunnerConfig
+	config.AddToFlagSet(pflag.CommandLine)
+
+	pflag.String("hosts", "localhost", "Comma separated list of QuestDB hosts (pass multiple values for sharding reads on a multi-node setup)")
+	pflag.String("user", "admin", "User to connect to QuestDB as")
+	pflag.String("pass", "quest", "Password for the user connecting to QuestDB")
+	pflag.String("port", "8812", "Port of the PostgreSQL wire protocol endpoint")
+
+	pflag.Parse()
+
+	err := utils.SetupConfigFile()
+
+	if err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+
+	if err := viper.Unmarshal(&config); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+
+	hosts := viper.GetString("hosts")
+	user = viper.GetString("user")
+	pass = viper.GetString("pass")
+	port = viper.GetString("port")
+
+	runner = query.NewBenchmarkRunner(config)
+	if len(config.LoadConfig) > 0 {
+		workload, err := loadconfig.NewWorkload(config.LoadConfig)
+		if err != nil {
+			panic(fmt.Errorf("unable to parse load config: %s", err))
+		}
+		runner.SetWorkload(workload)
+	}
+
+	// Parse comma separated string of hosts and put in a slice (for multi-node setups)
+	for _, host := range strings.Split(hosts, ",") {
+		hostList = append(hostList, host)
+	}
+}
+
+func main() {
+	runner.Run(&query.QuestDBPool, newProcessor)
+}
+
+// getConnectString returns the connection string of a worker. Workers are
+// assigned to the hosts in a round robin fashion.
+func getConnectString(workerNumber int) string {
+	host := hostList[workerNumber%len(hostList)]
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		host, port, user, pass, questdbName)
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(rows *sql.Rows, q *query.QuestDB) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	resp["results"] = mapRows(rows)
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+func mapRows(r *sql.Rows) []map[string]interface{} {
+	rows := []map[string]interface{}{}
+	cols, _ := r.Columns()
+	for r.Next() {
+		row := make(map[string]interface{})
+		values := make([]interface{}, len(cols))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+
+		err := r.Scan(values...)
+		if err != nil {
+			panic(errors.Wrap(err, "error while reading values"))
+		}
+
+		for i, column := range cols {
+			row[column] = *values[i].(*interface{})
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type queryExecutorOptions struct {
+	debug         bool
+	printResponse bool
+}
+
+type processor struct {
+	db   *sql.DB
+	opts *queryExecutorOptions
+}
+
+func newProcessor() query.Processor { return &processor{} }
+
+func (p *processor) Init(workerNumber int) {
+	db, err := sql.Open(pgxDriver, getConnectString(workerNumber))
+	if err != nil {
+		panic(err)
+	}
+	p.db = db
+	p.opts = &queryExecutorOptions{
+		debug:         runner.DebugLevel() > 0,
+		printResponse: runner.DoPrintResponses(),
+	}
+}
+
+func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	qq := q.(*query.QuestDB)
+
+	start := time.Now()
+	qry := string(qq.SqlQuery)
+	if p.opts.debug {
+		fmt.Println(qry)
+	}
+	rows, err := p.db.Query(qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.printResponse {
+		prettyPrintResponse(rows, qq)
+	}
+	// Fetching all the rows to confirm that the query is fully completed.
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, nil
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
+	loadconfig "github.com/timescale/tsbs/load/config"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
@@ -78,6 +79,13 @@ func init() {
 	forceTextFormat = viper.GetBool("force-text-format")
 
 	runner = query.NewBenchmarkRunner(config)
+	if len(config.LoadConfig) > 0 {
+		workload, err := loadconfig.NewWorkload(config.LoadConfig)
+		if err != nil {
+			panic(fmt.Errorf("unable to parse load config: %s", err))
+		}
+		runner.SetWorkload(workload)
+	}
 
 	if showExplain {
 		runner.SetLimit(1)
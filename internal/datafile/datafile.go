@@ -0,0 +1,178 @@
+// Package datafile opens the input files of the loaders and query runners,
+// which may be compressed and split across several files.
+package datafile
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Compression formats of input files
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+	LZ4  = "lz4"
+)
+
+// extensions are the file extensions of the compression formats
+var extensions = map[string]string{
+	".gz":   Gzip,
+	".gzip": Gzip,
+	".zst":  Zstd,
+	".zstd": Zstd,
+	".lz4":  LZ4,
+}
+
+// magics are the bytes the data of the compression formats starts with
+var magics = []struct {
+	format string
+	magic  []byte
+}{
+	{Gzip, []byte{0x1f, 0x8b}},
+	{Zstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{LZ4, []byte{0x04, 0x22, 0x4d, 0x18}},
+}
+
+// Paths returns the files name stands for: the files in it if it is a
+// directory, the files matching it if it is a glob pattern, in name order,
+// or else name itself. Name may also be a list of those, separated by the
+// list separator of the OS (':' on Unix), whose files are returned in turn.
+func Paths(name string) ([]string, error) {
+	if list := filepath.SplitList(name); len(list) > 1 {
+		var paths []string
+		for _, n := range list {
+			p, err := Paths(n)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, p...)
+		}
+		return paths, nil
+	}
+	if strings.ContainsAny(name, "*?[") {
+		paths, err := filepath.Glob(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %v", name, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no files match %s", name)
+		}
+		sort.Strings(paths)
+		return paths, nil
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{name}, nil
+	}
+	entries, err := ioutil.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.Mode().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+			paths = append(paths, filepath.Join(name, e.Name()))
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files in directory %s", name)
+	}
+	return paths, nil
+}
+
+// Open opens the file at path, decompressing it if its extension or first
+// bytes show it is compressed
+func Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(file, extensions[strings.ToLower(filepath.Ext(path))])
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not read %s: %v", path, err)
+	}
+	return &readCloser{Reader: r, closers: []io.Closer{r, file}}, nil
+}
+
+// Format returns the compression format of the file at path, from its
+// extension or first bytes, or "" if it is not compressed
+func Format(path string) (string, error) {
+	if format, ok := extensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return format, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return sniff(bufio.NewReader(file)), nil
+}
+
+// NewReader returns a reader of r decompressed in the given format. If format
+// is empty it is detected from the first bytes of r, and r is read as is if
+// it is not compressed. Closing the reader does not close r.
+func NewReader(r io.Reader, format string) (io.ReadCloser, error) {
+	if format == "" {
+		br := bufio.NewReader(r)
+		r = br
+		format = sniff(br)
+	}
+	switch format {
+	case "":
+		return ioutil.NopCloser(r), nil
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case LZ4:
+		return ioutil.NopCloser(lz4.NewReader(r)), nil
+	default:
+		return nil, fmt.Errorf("unknown compression format %s", format)
+	}
+}
+
+// sniff returns the compression format the data of br starts with, or "" if
+// it is not compressed
+func sniff(br *bufio.Reader) string {
+	for _, m := range magics {
+		if start, _ := br.Peek(len(m.magic)); bytes.Equal(start, m.magic) {
+			return m.format
+		}
+	}
+	return ""
+}
+
+// readCloser closes all of its closers, in order
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var first error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,220 @@
+package datafile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// compress returns data compressed in the given format, or as is if the
+// format is empty
+func compress(t *testing.T, format string, data string) []byte {
+	var b bytes.Buffer
+	var w io.WriteCloser
+	switch format {
+	case "":
+		return []byte(data)
+	case Gzip:
+		w = gzip.NewWriter(&b)
+	case Zstd:
+		zw, err := zstd.NewWriter(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = zw
+	case LZ4:
+		w = lz4.NewWriter(&b)
+	}
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func writeFile(t *testing.T, path string, b []byte) {
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readAll(t *testing.T, r io.Reader) string {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datafile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		desc   string
+		name   string
+		format string
+	}{
+		{desc: "plain", name: "data", format: ""},
+		{desc: "gzip by extension", name: "data.gz", format: Gzip},
+		{desc: "zstd by extension", name: "data.zst", format: Zstd},
+		{desc: "lz4 by extension", name: "data.lz4", format: LZ4},
+		{desc: "gzip by magic bytes", name: "gzip-data", format: Gzip},
+		{desc: "zstd by magic bytes", name: "zstd-data", format: Zstd},
+		{desc: "lz4 by magic bytes", name: "lz4-data", format: LZ4},
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, c.name)
+		writeFile(t, path, compress(t, c.format, "some data\n"))
+		r, err := Open(path)
+		if err != nil {
+			t.Errorf("%s: could not open: %v", c.desc, err)
+			continue
+		}
+		if got := readAll(t, r); got != "some data\n" {
+			t.Errorf("%s: incorrect data: got %q want %q", c.desc, got, "some data\n")
+		}
+		if err := r.Close(); err != nil {
+			t.Errorf("%s: could not close: %v", c.desc, err)
+		}
+		if got, err := Format(path); err != nil || got != c.format {
+			t.Errorf("%s: incorrect format: got %q (%v) want %q", c.desc, got, err, c.format)
+		}
+	}
+
+	// the extension wins over the data not being compressed
+	path := filepath.Join(dir, "plain.gz")
+	writeFile(t, path, []byte("some data\n"))
+	if _, err := Open(path); err == nil {
+		t.Errorf("expected an error for a .gz file that is not compressed")
+	}
+}
+
+func TestPaths(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datafile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"b.gz", "a.gz", "c.txt", ".hidden"} {
+		writeFile(t, filepath.Join(dir, name), nil)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc string
+		name string
+		want []string
+	}{
+		{desc: "file", name: filepath.Join(dir, "c.txt"), want: []string{"c.txt"}},
+		{desc: "directory", name: dir, want: []string{"a.gz", "b.gz", "c.txt"}},
+		{desc: "glob", name: filepath.Join(dir, "*.gz"), want: []string{"a.gz", "b.gz"}},
+		{desc: "list", name: filepath.Join(dir, "c.txt") + string(os.PathListSeparator) + filepath.Join(dir, "*.gz"), want: []string{"c.txt", "a.gz", "b.gz"}},
+	}
+	for _, c := range cases {
+		paths, err := Paths(c.name)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+			continue
+		}
+		if len(paths) != len(c.want) {
+			t.Errorf("%s: incorrect paths: got %v want %v", c.desc, paths, c.want)
+			continue
+		}
+		for i, p := range paths {
+			if want := filepath.Join(dir, c.want[i]); p != want {
+				t.Errorf("%s: incorrect path %d: got %s want %s", c.desc, i, p, want)
+			}
+		}
+	}
+
+	for _, name := range []string{filepath.Join(dir, "*.zst"), filepath.Join(dir, "sub"), filepath.Join(dir, "missing")} {
+		if _, err := Paths(name); err == nil {
+			t.Errorf("expected an error for %s", name)
+		}
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datafile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeFile(t, filepath.Join(dir, "1.gz"), compress(t, Gzip, "one\n"))
+	writeFile(t, filepath.Join(dir, "2.zst"), compress(t, Zstd, "two\n"))
+	writeFile(t, filepath.Join(dir, "3"), compress(t, "", "three\n"))
+	writeFile(t, filepath.Join(dir, "4.lz4"), compress(t, LZ4, "four\n"))
+	want := []string{"one\n", "two\n", "three\n", "four\n"}
+
+	for _, readAhead := range []int{0, 1, 3} {
+		f, err := OpenFiles(dir, readAhead)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for pass := 0; pass < 2; pass++ {
+			if pass > 0 {
+				if err := f.Rewind(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i, w := range want {
+				if i > 0 {
+					if ok, err := f.Next(); !ok || err != nil {
+						t.Fatalf("read ahead %d: could not move on to file %d: %v", readAhead, i, err)
+					}
+				}
+				if got := readAll(t, f); got != w {
+					t.Errorf("read ahead %d, pass %d: incorrect data of file %d: got %q want %q", readAhead, pass, i, got, w)
+				}
+			}
+			if ok, err := f.Next(); ok || err != nil {
+				t.Errorf("read ahead %d: expected no more files, got %v (error %v)", readAhead, ok, err)
+			}
+		}
+		f.Close()
+
+		f, err = OpenFiles(dir, readAhead)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readAll(t, f.Concat()); got != "one\ntwo\nthree\nfour\n" {
+			t.Errorf("read ahead %d: incorrect concatenated data: got %q", readAhead, got)
+		}
+		f.Close()
+	}
+}
+
+func TestFilesCloseWhileReadingAhead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "datafile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// more chunks than are read ahead, so reading ahead blocks until closed
+	big := bytes.Repeat([]byte{'x'}, (chunksAhead+2)*chunkSize)
+	writeFile(t, filepath.Join(dir, "1"), []byte("one\n"))
+	writeFile(t, filepath.Join(dir, "2"), big)
+
+	f, err := OpenFiles(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Errorf("unexpected error closing: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package datafile
+
+import (
+	"io"
+)
+
+const (
+	// chunkSize is the size of the chunks files are read ahead in
+	chunkSize = 1 << 20 // 1 MB
+	// chunksAhead is the number of chunks read ahead of each file
+	chunksAhead = 4
+)
+
+// Files reads the files of an input one after another, each of them ending
+// with io.EOF. The ones after the file being read can be decompressed ahead
+// in parallel.
+type Files struct {
+	paths     []string
+	readAhead int
+	// next is the index of the file after current
+	next    int
+	current io.ReadCloser
+	// ahead are the files after current being read ahead
+	ahead []*prefetcher
+}
+
+// OpenFiles opens the first of the files name stands for (see Paths), and
+// starts reading up to readAhead files after it in the background
+func OpenFiles(name string, readAhead int) (*Files, error) {
+	paths, err := Paths(name)
+	if err != nil {
+		return nil, err
+	}
+	f := &Files{paths: paths, readAhead: readAhead}
+	if _, err := f.Next(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Paths returns the paths of the files
+func (f *Files) Paths() []string {
+	return f.paths
+}
+
+// Read reads the current file
+func (f *Files) Read(p []byte) (int, error) {
+	if f.current == nil {
+		return 0, io.EOF
+	}
+	return f.current.Read(p)
+}
+
+// Next closes the current file and moves on to the next one. It returns
+// false if there are no more files.
+func (f *Files) Next() (bool, error) {
+	if f.current != nil {
+		f.current.Close()
+		f.current = nil
+	}
+	if len(f.ahead) > 0 {
+		f.current = f.ahead[0]
+		f.ahead = f.ahead[1:]
+	} else if f.next < len(f.paths) {
+		r, err := Open(f.paths[f.next])
+		if err != nil {
+			return false, err
+		}
+		f.current = r
+		f.next++
+	} else {
+		return false, nil
+	}
+	for len(f.ahead) < f.readAhead && f.next < len(f.paths) {
+		r, err := Open(f.paths[f.next])
+		if err != nil {
+			return false, err
+		}
+		f.ahead = append(f.ahead, prefetch(r))
+		f.next++
+	}
+	return true, nil
+}
+
+// Rewind starts reading the files from the first one again
+func (f *Files) Rewind() error {
+	f.Close()
+	f.next = 0
+	_, err := f.Next()
+	return err
+}
+
+// Close closes the files being read
+func (f *Files) Close() error {
+	var first error
+	if f.current != nil {
+		first = f.current.Close()
+		f.current = nil
+	}
+	for _, p := range f.ahead {
+		if err := p.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	f.ahead = nil
+	return first
+}
+
+// Concat returns a reader of all the files, read one after another as if they
+// were one
+func (f *Files) Concat() io.Reader {
+	return &concatReader{f}
+}
+
+type concatReader struct {
+	f *Files
+}
+
+func (r *concatReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.f.Read(p)
+		if err != io.EOF {
+			return n, err
+		}
+		ok, err := r.f.Next()
+		if err != nil {
+			return n, err
+		}
+		if !ok {
+			return n, io.EOF
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// prefetcher reads a file ahead, in chunks, in the background
+type prefetcher struct {
+	r      io.ReadCloser
+	chunks chan []byte
+	// err is the read error, set before chunks is closed
+	err  error
+	buf  []byte
+	done chan struct{}
+	// exited is closed once the background reading is over
+	exited chan struct{}
+}
+
+func prefetch(r io.ReadCloser) *prefetcher {
+	p := &prefetcher{
+		r:      r,
+		chunks: make(chan []byte, chunksAhead),
+		done:   make(chan struct{}),
+		exited: make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *prefetcher) run() {
+	defer close(p.exited)
+	defer close(p.chunks)
+	for {
+		chunk := make([]byte, chunkSize)
+		n, err := io.ReadFull(p.r, chunk)
+		if n > 0 {
+			select {
+			case p.chunks <- chunk[:n]:
+			case <-p.done:
+				return
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return
+		} else if err != nil {
+			p.err = err
+			return
+		}
+	}
+}
+
+func (p *prefetcher) Read(b []byte) (int, error) {
+	for len(p.buf) == 0 {
+		chunk, ok := <-p.chunks
+		if !ok {
+			if p.err != nil {
+				return 0, p.err
+			}
+			return 0, io.EOF
+		}
+		p.buf = chunk
+	}
+	n := copy(b, p.buf)
+	p.buf = p.buf[n:]
+	return n, nil
+}
+
+// Close stops reading ahead and closes the file
+func (p *prefetcher) Close() error {
+	close(p.done)
+	<-p.exited
+	return p.r.Close()
+}
@@ -98,7 +98,17 @@ func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (com
 		return nil, err
 	}
 
-	return scfg.NewSimulator(g.config.LogInterval, g.config.Limit), nil
+	// a load with --loop keeps the simulation going with a new simulator for
+	// each pass, continuing the random sequence of the one before
+	return common.NewLoopingSimulator(func() common.Simulator {
+		sim := scfg.NewSimulator(g.config.LogInterval, g.config.Limit)
+		// an agent of a distributed load simulates only the hosts of its
+		// interleaved generation group
+		if gs, ok := sim.(common.GroupedSimulator); ok && g.config.InterleavedNumGroups > 1 {
+			gs.KeepGroup(g.config.InterleavedGroupID, g.config.InterleavedNumGroups)
+		}
+		return sim
+	}), nil
 }
 
 func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, dgc *common.DataGeneratorConfig) error {
@@ -129,7 +139,7 @@ func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.
 
 func (g *DataGenerator) getSerializer(sim common.Simulator, target targets.ImplementedTarget) (serialize.PointSerializer, error) {
 	switch target.TargetName() {
-	case constants.FormatTimescaleDB:
+	case constants.FormatTimescaleDB, constants.FormatClickhouse:
 		g.writeHeader(sim.Headers())
 	}
 	return target.Serializer(), nil
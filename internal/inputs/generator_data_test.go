@@ -333,6 +333,7 @@ func TestGetSerializer(t *testing.T) {
 	checkWriteHeader(constants.FormatInflux, false)
 	checkWriteHeader(constants.FormatTimescaleDB, true)
 	checkWriteHeader(constants.FormatDatalayers, false)
+	checkWriteHeader(constants.FormatClickhouse, true)
 }
 
 type mockSerializer struct {
@@ -0,0 +1,82 @@
+// Package interrupt lets a benchmark run stop gracefully on SIGINT or
+// SIGTERM, finishing the work in flight and reporting its partial results.
+package interrupt
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Signals are the signals that stop a benchmark run
+var Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// Handler catches the Signals while a benchmark runs
+type Handler struct {
+	sigs     chan os.Signal
+	done     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// Notify catches the Signals until Stop is called. The first one closes the
+// Done channel; a second one exits the program at once.
+func Notify() *Handler {
+	h := &Handler{
+		sigs:    make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	signal.Notify(h.sigs, Signals...)
+	go h.wait()
+	return h
+}
+
+func (h *Handler) wait() {
+	select {
+	case sig := <-h.sigs:
+		fmt.Fprintf(os.Stderr, "\ncaught %v, finishing the work in flight (send it again to exit at once)\n", sig)
+		close(h.done)
+	case <-h.stopped:
+		return
+	}
+	select {
+	case <-h.sigs:
+		fmt.Fprintln(os.Stderr, "\ncaught a second signal, exiting")
+		os.Exit(1)
+	case <-h.stopped:
+	}
+}
+
+// Done returns a channel that is closed when a signal was caught
+func (h *Handler) Done() <-chan struct{} {
+	if h == nil {
+		// never closed
+		return nil
+	}
+	return h.done
+}
+
+// Interrupted returns whether a signal was caught
+func (h *Handler) Interrupted() bool {
+	select {
+	case <-h.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop stops catching the Signals, so they kill the program again; it can be
+// called more than once
+func (h *Handler) Stop() {
+	if h == nil {
+		return
+	}
+	h.stopOnce.Do(func() {
+		signal.Stop(h.sigs)
+		close(h.stopped)
+	})
+}
@@ -0,0 +1,37 @@
+package interrupt
+
+import (
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	h := Notify()
+	defer h.Stop()
+	if h.Interrupted() {
+		t.Fatalf("interrupted before a signal")
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-h.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("signal not caught")
+	}
+	if !h.Interrupted() {
+		t.Errorf("not interrupted after a signal")
+	}
+	h.Stop()
+	h.Stop()
+}
+
+func TestHandlerNil(t *testing.T) {
+	var h *Handler
+	if h.Interrupted() {
+		t.Errorf("a nil handler was interrupted")
+	}
+	h.Stop()
+}
@@ -0,0 +1,36 @@
+// Package metrics serves the live metrics of a benchmark run for Prometheus
+// to scrape.
+package metrics
+
+import (
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is where the metrics are served
+const Path = "/metrics"
+
+// LatencyBuckets are the buckets of latency histograms in seconds, from 1ms
+// to about two minutes
+var LatencyBuckets = prometheus.ExponentialBuckets(0.001, 2, 18)
+
+// Listen serves the metrics gathered by g at Path on addr, e.g. ":9099", in
+// the background and returns the address it listens on.
+func Listen(addr string, g prometheus.Gatherer) (net.Addr, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.HandlerFor(g, promhttp.HandlerOpts{}))
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			log.Printf("metrics server stopped: %v", err)
+		}
+	}()
+	return ln.Addr(), nil
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestListen(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "A test counter."})
+	reg.MustRegister(c)
+	c.Add(3)
+
+	addr, err := Listen("127.0.0.1:0", reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get("http://" + addr.String() + Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "test_total 3\n") {
+		t.Errorf("counter not served:\n%s", body)
+	}
+}
+
+func TestListenInvalidAddress(t *testing.T) {
+	if _, err := Listen("not an address", prometheus.NewRegistry()); err == nil {
+		t.Errorf("expected an error for an invalid address")
+	}
+}
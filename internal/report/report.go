@@ -0,0 +1,138 @@
+// Package report writes the periodic reports of a benchmark run to a file,
+// as CSV or JSON lines, for plotting.
+package report
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Formats of a report file
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Writer writes records with fixed columns to a report file. It is safe for
+// concurrent use, and records written after Close are dropped.
+type Writer struct {
+	lock    sync.Mutex
+	file    *os.File
+	w       *bufio.Writer
+	csv     *csv.Writer
+	columns []string
+	closed  bool
+}
+
+// NewWriter creates the report file in the given format, writing the
+// columns as the header of a CSV file
+func NewWriter(fileName, format string, columns []string) (*Writer, error) {
+	if format != FormatCSV && format != FormatJSONL {
+		return nil, fmt.Errorf("unknown report format %q: must be %s or %s", format, FormatCSV, FormatJSONL)
+	}
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	rw := &Writer{file: file, w: bufio.NewWriter(file), columns: columns}
+	if format == FormatCSV {
+		rw.csv = csv.NewWriter(rw.w)
+		if err := rw.csv.Write(columns); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return rw, rw.flush()
+}
+
+// Write writes a record with a value for each column. Values may be nil,
+// which is an empty CSV field or a JSON null, or of a type JSON can encode.
+// Each record is flushed to the file, so it can be followed while the run
+// goes on.
+func (rw *Writer) Write(values ...interface{}) error {
+	if len(values) != len(rw.columns) {
+		return fmt.Errorf("report record has %d values for %d columns", len(values), len(rw.columns))
+	}
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+	if rw.closed {
+		return nil
+	}
+	var err error
+	if rw.csv != nil {
+		err = rw.writeCSV(values)
+	} else {
+		err = rw.writeJSON(values)
+	}
+	if err != nil {
+		return err
+	}
+	return rw.flush()
+}
+
+func (rw *Writer) writeCSV(values []interface{}) error {
+	fields := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			fields[i] = v
+		case float64:
+			fields[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			fields[i] = fmt.Sprint(v)
+		}
+	}
+	return rw.csv.Write(fields)
+}
+
+func (rw *Writer) writeJSON(values []interface{}) error {
+	rw.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			rw.w.WriteByte(',')
+		}
+		key, err := json.Marshal(rw.columns[i])
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		rw.w.Write(key)
+		rw.w.WriteByte(':')
+		rw.w.Write(value)
+	}
+	_, err := rw.w.WriteString("}\n")
+	return err
+}
+
+func (rw *Writer) flush() error {
+	if rw.csv != nil {
+		rw.csv.Flush()
+		if err := rw.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return rw.w.Flush()
+}
+
+// Close closes the report file. It does nothing for a nil Writer.
+func (rw *Writer) Close() error {
+	if rw == nil {
+		return nil
+	}
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+	if rw.closed {
+		return nil
+	}
+	rw.closed = true
+	return rw.file.Close()
+}
@@ -0,0 +1,63 @@
+package report
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: FormatCSV,
+			want:   "time,rate,rows,phase\n1600000000,1234.5,,\"ramp, up\"\n",
+		},
+		{
+			format: FormatJSONL,
+			want:   `{"time":1600000000,"rate":1234.5,"rows":null,"phase":"ramp, up"}` + "\n",
+		},
+	}
+	for _, c := range cases {
+		fileName := filepath.Join(dir, "report."+c.format)
+		w, err := NewWriter(fileName, c.format, []string{"time", "rate", "rows", "phase"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Write(int64(1600000000), 1234.5, nil, "ramp, up"); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.format, err)
+		}
+		if err := w.Write(1, 2); err == nil {
+			t.Errorf("%s: expected an error for a record with missing values", c.format)
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("%s: unexpected error closing: %v", c.format, err)
+		}
+		if err := w.Write(int64(1600000001), 1.0, nil, ""); err != nil {
+			t.Errorf("%s: unexpected error after closing: %v", c.format, err)
+		}
+
+		got, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.want {
+			t.Errorf("%s: incorrect report\ngot:\n%s\nwant:\n%s", c.format, got, c.want)
+		}
+	}
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	if _, err := NewWriter(filepath.Join(os.TempDir(), "report.xml"), "xml", nil); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
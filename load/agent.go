@@ -0,0 +1,189 @@
+package load
+
+import (
+	"fmt"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/internal/datafile"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// coordinatorService is the name the coordinator serves its RPC methods under
+const coordinatorService = "Coordinator"
+
+// JoinRequest is sent by an agent to join the load of a coordinator
+type JoinRequest struct {
+	Name string
+}
+
+// Assignment is the shard of the data an agent loads, out of the shards of
+// all agents, into the database the coordinator created
+type Assignment struct {
+	Agent  int
+	Agents int
+	DBName string
+}
+
+// AgentReport is the outcome of the load of an agent, sent to the coordinator
+// once it is done
+type AgentReport struct {
+	Name          string
+	Workers       uint
+	Metrics       uint64
+	Rows          uint64
+	FailedMetrics uint64
+	Start         time.Time
+	End           time.Time
+	Partial       bool
+	// Latencies is the histogram of the batch latencies of all workers
+	Latencies *hdrhistogram.Snapshot
+	Totals    map[string]interface{}
+}
+
+// Agent loads a shard of the data for the coordinator it joined with
+// --coordinator. Its shard is the hosts of its interleaved generation group
+// if the data is simulated, a range of the data files if there are at least
+// as many as agents, or else the part of the data its ShardedDataSource
+// reads for it, e.g. a byte range of a single data file.
+type Agent struct {
+	Assignment
+	client *rpc.Client
+	name   string
+	// shardData is set if the DataSource must read the shard of the agent
+	shardData bool
+}
+
+// JoinCoordinator joins the load of the coordinator at c.Coordinator, waiting
+// for it to assign the shard of the agent. The shard is applied to ds, and c
+// is set to load into the database created by the coordinator.
+func JoinCoordinator(c *BenchmarkRunnerConfig, ds *source.DataSourceConfig) (*Agent, error) {
+	client, err := jsonrpc.Dial("tcp", c.Coordinator)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to coordinator %s: %v", c.Coordinator, err)
+	}
+	host, _ := os.Hostname()
+	a := &Agent{client: client, name: fmt.Sprintf("%s:%d", host, os.Getpid())}
+	if err := client.Call(coordinatorService+".Join", JoinRequest{Name: a.name}, &a.Assignment); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("could not join coordinator %s: %v", c.Coordinator, err)
+	}
+
+	printFn("joined coordinator %s as agent %d of %d\n", c.Coordinator, a.Agent, a.Agents)
+	a.shardData = true
+	switch ds.Type {
+	case source.SimulatorDataSourceType:
+		if ds.Simulator.Scale < uint64(a.Agents) {
+			client.Close()
+			return nil, fmt.Errorf("cannot spread %d hosts over %d agents", ds.Simulator.Scale, a.Agents)
+		}
+		// each host belongs to the interleaved generation group of one agent
+		ds.Simulator.InterleavedGroupID = uint(a.Agent)
+		ds.Simulator.InterleavedNumGroups = uint(a.Agents)
+		printFn("generating the hosts of group %d of %d\n", a.Agent+1, a.Agents)
+		a.shardData = false
+	case source.FileDataSourceType:
+		paths, err := datafile.Paths(ds.File.Location)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+		if len(paths) >= a.Agents {
+			from, to := a.Agent*len(paths)/a.Agents, (a.Agent+1)*len(paths)/a.Agents
+			ds.File.Location = strings.Join(paths[from:to], string(os.PathListSeparator))
+			printFn("loading files %d to %d of %d\n", from+1, to, len(paths))
+			a.shardData = false
+		}
+	}
+
+	c.DBName = a.DBName
+	// the coordinator created the database
+	c.DoCreateDB = false
+	c.DoAbortOnExist = false
+	return a, nil
+}
+
+// GetAgentBenchmarkRunner returns the BenchmarkRunner that loads the shard of
+// agent a, starting at the same time as the other agents of its coordinator
+// and reporting to it at the end
+func GetAgentBenchmarkRunner(c BenchmarkRunnerConfig, a *Agent) BenchmarkRunner {
+	runner := GetBenchmarkRunner(c)
+	switch r := runner.(type) {
+	case *CommonBenchmarkRunner:
+		r.agent = a
+	case *noFlowBenchmarkRunner:
+		r.agent = a
+	}
+	return runner
+}
+
+// shardDataSource reads only the items of the shard of the agent from ds,
+// if it is not a range of the data files. Data that can't be split is not
+// loaded, rather than read in full by every agent.
+func (a *Agent) shardDataSource(ds targets.DataSource) targets.DataSource {
+	if a == nil || !a.shardData || a.Agents <= 1 {
+		return ds
+	}
+	inner := ds
+	if lds, ok := ds.(*loopingDataSource); ok {
+		inner = lds.RewindableDataSource
+	}
+	sds, ok := inner.(targets.ShardedDataSource)
+	if !ok || !sds.Shard(a.Agent, a.Agents) {
+		fatal("cannot split the data between %d agents: use at least as many data files as agents, or a single uncompressed data file", a.Agents)
+		return ds
+	}
+	printFn("loading part %d of %d of the data\n", a.Agent+1, a.Agents)
+	return ds
+}
+
+// waitForStart tells the coordinator the agent is ready to load, and waits
+// for all the other agents to be ready too
+func (a *Agent) waitForStart() {
+	if a == nil {
+		return
+	}
+	var start time.Time
+	if err := a.client.Call(coordinatorService+".Ready", struct{}{}, &start); err != nil {
+		fatal("could not start the load with the coordinator: %v", err)
+	}
+}
+
+// report sends the outcome of the load to the coordinator
+func (a *Agent) report(r *AgentReport) {
+	if a == nil {
+		return
+	}
+	r.Name = a.name
+	if err := a.client.Call(coordinatorService+".Report", r, &struct{}{}); err != nil {
+		fatal("could not report to the coordinator: %v", err)
+	}
+	a.client.Close()
+}
+
+// reportToCoordinator sends the outcome of the load to the coordinator, if the
+// load is a shard of its load
+func (l *CommonBenchmarkRunner) reportToCoordinator(start, end time.Time, totals map[string]interface{}) {
+	if l.agent == nil {
+		return
+	}
+	r := &AgentReport{
+		Workers:       l.Workers,
+		Metrics:       l.metricCnt,
+		Rows:          l.rowCnt,
+		FailedMetrics: l.failures.failedMetrics(),
+		Start:         start,
+		End:           end,
+		Partial:       l.interrupt.Interrupted(),
+		Totals:        totals,
+	}
+	if l.latencies != nil {
+		r.Latencies = mergeLatencies(l.latencies).Export()
+	}
+	l.agent.report(r)
+}
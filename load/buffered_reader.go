@@ -2,7 +2,11 @@ package load
 
 import (
 	"bufio"
+	"bytes"
+	"io"
 	"os"
+
+	"github.com/timescale/tsbs/internal/datafile"
 )
 
 const (
@@ -10,17 +14,211 @@ const (
 )
 
 // GetBufferedReader returns the buffered Reader that should be used by the file loader
-// if no file name is specified a buffer for STDIN is returned
+// if no file name is specified a buffer for STDIN is returned. The file name
+// may also be a directory or a glob pattern, whose files are read one after
+// another, and compressed data is decompressed.
 func GetBufferedReader(fileName string) *bufio.Reader {
 	if len(fileName) == 0 {
 		// Read from STDIN
-		return bufio.NewReaderSize(os.Stdin, defaultReadSize)
+		return bufio.NewReaderSize(stdinReader(), defaultReadSize)
+	}
+	// Read from specified files
+	files, err := datafile.OpenFiles(fileName, 0)
+	if err != nil {
+		fatal("cannot open file for read %s: %v", fileName, err)
+		return nil
+	}
+	return bufio.NewReaderSize(files.Concat(), defaultReadSize)
+}
+
+// stdinReader returns a reader of STDIN, decompressed if its first bytes
+// show it is compressed
+func stdinReader() *bufio.Reader {
+	r, err := datafile.NewReader(os.Stdin, "")
+	if err != nil {
+		fatal("cannot read STDIN: %v", err)
+		return nil
+	}
+	return bufio.NewReaderSize(r, defaultReadSize)
+}
+
+// RewindableReader is a buffered Reader of the data files, that reads each
+// of them up to its end before moving on to the next one with NextFile, and
+// can read them from the start again for a load with --loop
+type RewindableReader struct {
+	*bufio.Reader
+	files *datafile.Files
+	// shard is the part of the data file read, once it is sharded
+	shard *fileShard
+}
+
+// GetRewindableReader returns the RewindableReader of the data files; if no
+// file name is specified it reads STDIN, which can't be rewound. Up to
+// readAhead files after the one being read are decompressed in parallel.
+func GetRewindableReader(fileName string, readAhead int) *RewindableReader {
+	if len(fileName) == 0 {
+		return &RewindableReader{Reader: stdinReader()}
 	}
-	// Read from specified file
-	file, err := os.Open(fileName)
+	files, err := datafile.OpenFiles(fileName, readAhead)
 	if err != nil {
 		fatal("cannot open file for read %s: %v", fileName, err)
 		return nil
 	}
-	return bufio.NewReaderSize(file, defaultReadSize)
+	return &RewindableReader{Reader: bufio.NewReaderSize(files, defaultReadSize), files: files}
+}
+
+// NextFile moves on to the next file once the current one is read. It
+// returns false if there are no more files, or no RewindableReader.
+func (r *RewindableReader) NextFile() bool {
+	if r == nil || r.files == nil || r.shard != nil {
+		return false
+	}
+	ok, err := r.files.Next()
+	if err != nil {
+		fatal("cannot open file for read: %v", err)
+		return false
+	}
+	if ok {
+		r.Reader.Reset(r.files)
+	}
+	return ok
+}
+
+// Rewind reads the files from the start again. It returns false when reading
+// STDIN.
+func (r *RewindableReader) Rewind() bool {
+	if r.shard != nil {
+		r.Reader.Reset(r.shard.reader())
+		return true
+	}
+	if r.files == nil {
+		return false
+	}
+	if err := r.files.Rewind(); err != nil {
+		fatal("cannot open file for read: %v", err)
+		return false
+	}
+	r.Reader.Reset(r.files)
+	return true
+}
+
+// LineShards tells how a data file of lines is split into shards
+type LineShards struct {
+	// Header is set if the file starts with a header ending with an empty
+	// line, which is read by every shard before its part of the data
+	Header bool
+	// IsStart tells whether a line starts a record, which may span several
+	// lines; every line does if it is nil
+	IsStart func(line []byte) bool
+}
+
+// fileShard is a byte range of the data of a file, read after its header
+type fileShard struct {
+	file       *os.File
+	headerEnd  int64
+	start, end int64
+}
+
+func (s *fileShard) reader() io.Reader {
+	return io.MultiReader(io.NewSectionReader(s.file, 0, s.headerEnd), io.NewSectionReader(s.file, s.start, s.end-s.start))
+}
+
+// ShardLines limits the reader to the records of the shard-th of shards
+// about equal byte ranges of the data, each record belonging to the range its
+// first byte is in, so that no agent of a coordinator reads the data of
+// another one. The data must be a single uncompressed file, that is read from
+// the start again; it returns false otherwise, leaving the reader as is.
+func (r *RewindableReader) ShardLines(shard, shards int, ls LineShards) bool {
+	if r == nil || r.files == nil || len(r.files.Paths()) != 1 {
+		return false
+	}
+	path := r.files.Paths()[0]
+	if format, err := datafile.Format(path); err != nil || format != "" {
+		return false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		fatal("cannot open file for read %s: %v", path, err)
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		fatal("cannot read file %s: %v", path, err)
+		return false
+	}
+
+	s := &fileShard{file: f}
+	if ls.Header {
+		s.headerEnd, err = headerEnd(f)
+		if err != nil {
+			fatal("cannot read the header of %s: %v", path, err)
+			return false
+		}
+	}
+	size := info.Size() - s.headerEnd
+	s.start, err = recordStart(f, s.headerEnd, s.headerEnd+size*int64(shard)/int64(shards), info.Size(), ls.IsStart)
+	if err == nil {
+		s.end, err = recordStart(f, s.headerEnd, s.headerEnd+size*int64(shard+1)/int64(shards), info.Size(), ls.IsStart)
+	}
+	if err != nil {
+		fatal("cannot read file %s: %v", path, err)
+		return false
+	}
+	r.shard = s
+	r.Reader.Reset(s.reader())
+	return true
+}
+
+// headerEnd returns the offset after the empty line that ends the header of
+// the file
+func headerEnd(f *os.File) (int64, error) {
+	br := bufio.NewReader(io.NewSectionReader(f, 0, 1<<62))
+	var offset int64
+	for {
+		line, err := br.ReadBytes('\n')
+		offset += int64(len(line))
+		if err == io.EOF {
+			return offset, nil
+		} else if err != nil {
+			return 0, err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			return offset, nil
+		}
+	}
+}
+
+// recordStart returns the offset of the first record starting at or after
+// offset, or size if there is none. The data starts at dataStart.
+func recordStart(f *os.File, dataStart, offset, size int64, isStart func(line []byte) bool) (int64, error) {
+	if offset >= size {
+		return size, nil
+	}
+	if offset > dataStart {
+		// a line starts at offset if the byte before it ends a line
+		offset--
+	}
+	br := bufio.NewReader(io.NewSectionReader(f, offset, size-offset))
+	if offset > dataStart {
+		skipped, err := br.ReadBytes('\n')
+		offset += int64(len(skipped))
+		if err == io.EOF {
+			return size, nil
+		} else if err != nil {
+			return 0, err
+		}
+	}
+	for isStart != nil {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return size, nil
+		} else if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if isStart(line) {
+			break
+		}
+		offset += int64(len(line))
+	}
+	return offset, nil
 }
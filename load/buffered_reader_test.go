@@ -0,0 +1,74 @@
+package load
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRewindableReaderShardLines(t *testing.T) {
+	header := "tags,hostname string\ncpu,usage_user\n\n"
+	var records strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&records, "tags,hostname=host_%d\ncpu,%d,%s\n", i, i, strings.Repeat("1", i))
+	}
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "data")
+	if err := os.WriteFile(fileName, []byte(header+records.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	isStart := func(line []byte) bool { return bytes.HasPrefix(line, []byte("tags,hostname=")) }
+
+	for shards := 1; shards <= 7; shards++ {
+		var all strings.Builder
+		for shard := 0; shard < shards; shard++ {
+			r := GetRewindableReader(fileName, 0)
+			if !r.ShardLines(shard, shards, LineShards{Header: true, IsStart: isStart}) {
+				t.Fatalf("%d shards: uncompressed file not sharded", shards)
+			}
+			for pass := 0; pass < 2; pass++ {
+				content, err := ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !strings.HasPrefix(string(content), header) {
+					t.Fatalf("%d shards: shard %d does not start with the header:\n%s", shards, shard, content)
+				}
+				part := strings.TrimPrefix(string(content), header)
+				if part != "" && !strings.HasPrefix(part, "tags,hostname=") {
+					t.Errorf("%d shards: shard %d does not start with a record:\n%s", shards, shard, part)
+				}
+				if pass == 0 {
+					all.WriteString(part)
+					r.Rewind()
+				}
+			}
+			if r.NextFile() {
+				t.Errorf("%d shards: sharded reader moved on to another file", shards)
+			}
+		}
+		if all.String() != records.String() {
+			t.Errorf("%d shards: shards do not add up to the data:\ngot\n%s\nwant\n%s", shards, all.String(), records.String())
+		}
+	}
+}
+
+func TestRewindableReaderShardLinesUnsplittable(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("cpu usage_user=1 0\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if GetRewindableReader(dir, 0).ShardLines(0, 2, LineShards{}) {
+		t.Errorf("several files sharded by bytes")
+	}
+	var r *RewindableReader
+	if r.ShardLines(0, 2, LineShards{}) {
+		t.Errorf("missing reader sharded")
+	}
+}
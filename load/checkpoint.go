@@ -0,0 +1,160 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const defaultCheckpointInterval = time.Minute
+
+// checkpoint is the progress of a load, saved with --checkpoint-file so a
+// load that failed can continue with --resume-from. Its counts are those of
+// all the loads resumed from it as well.
+type checkpoint struct {
+	// Items is the number of items read from the DataSource, all of them in
+	// batches acknowledged by the workers
+	Items   uint64    `json:"items"`
+	Batches uint64    `json:"batches"`
+	Metrics uint64    `json:"metrics"`
+	Rows    uint64    `json:"rows"`
+	Time    time.Time `json:"time"`
+}
+
+func readCheckpoint(fileName string) (*checkpoint, error) {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read checkpoint: %v", err)
+	}
+	cp := &checkpoint{}
+	if err := json.Unmarshal(b, cp); err != nil {
+		return nil, fmt.Errorf("could not parse checkpoint %s: %v", fileName, err)
+	}
+	return cp, nil
+}
+
+// write replaces the checkpoint file, so it is never left half written
+func (cp *checkpoint) write(fileName string) error {
+	b, err := json.MarshalIndent(cp, "", " ")
+	if err != nil {
+		return err
+	}
+	tmp := fileName + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fileName)
+}
+
+// checkpointer saves checkpoints of the load while the data is scanned. At
+// each checkpoint the scanner waits for the workers to acknowledge all the
+// batches sent to them, so the items read so far are all written.
+type checkpointer struct {
+	l        *CommonBenchmarkRunner
+	interval time.Duration
+	next     time.Time
+	batches  uint64
+	lastSave *checkpoint
+}
+
+// newCheckpointer returns the checkpointer of the load, or nil if no
+// checkpoints are saved
+func (l *CommonBenchmarkRunner) newCheckpointer() *checkpointer {
+	if l.CheckpointFile == "" {
+		return nil
+	}
+	interval := l.CheckpointInterval
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+	return &checkpointer{l: l, interval: interval, next: time.Now().Add(interval)}
+}
+
+// acked counts a batch acknowledged by a worker
+func (c *checkpointer) acked() {
+	if c == nil {
+		return
+	}
+	c.batches++
+}
+
+// due returns whether it is time for the next checkpoint
+func (c *checkpointer) due() bool {
+	return c != nil && !time.Now().Before(c.next)
+}
+
+// save saves the checkpoint after itemsRead items, once all the batches with
+// them are acknowledged. No checkpoint is saved past the point the error
+// budget is exceeded, so resuming loads the failed batches again.
+func (c *checkpointer) save(itemsRead uint64) {
+	if c == nil {
+		return
+	}
+	c.next = time.Now().Add(c.interval)
+	if c.l.errorBudgetExceeded() {
+		return
+	}
+	cp := &checkpoint{
+		Items:   itemsRead,
+		Batches: c.batches,
+		Metrics: atomic.LoadUint64(&c.l.metricCnt),
+		Rows:    atomic.LoadUint64(&c.l.rowCnt),
+		Time:    time.Now(),
+	}
+	if r := c.l.resumed; r != nil {
+		cp.Items += r.Items
+		cp.Batches += r.Batches
+		cp.Metrics += r.Metrics
+		cp.Rows += r.Rows
+	}
+	if err := cp.write(c.l.CheckpointFile); err != nil {
+		fatal("could not save checkpoint to %s: %v", c.l.CheckpointFile, err)
+		return
+	}
+	c.lastSave = cp
+}
+
+// skipResumed reads the items loaded before the checkpoint resumed from, if
+// any, from ds. They are tracked as loaded if the load is verified.
+func (l *CommonBenchmarkRunner) skipResumed(ds targets.DataSource) {
+	if l.resumed == nil {
+		return
+	}
+	for i := uint64(0); i < l.resumed.Items; i++ {
+		item := ds.NextItem()
+		if item.Data == nil {
+			fatal("the checkpoint of %d items is past the end of the data (%d items)", l.resumed.Items, i)
+			return
+		}
+		if l.loadStats != nil {
+			l.dbCreator.(targets.Verifier).TrackPoint(item, l.loadStats)
+		}
+	}
+	printFn("resuming after %d items loaded before (%d metrics, %d rows)\n", l.resumed.Items, l.resumed.Metrics, l.resumed.Rows)
+}
+
+// checkpointSummary prints the totals of the load including the ones it
+// resumed from, and the last checkpoint saved
+func (l *CommonBenchmarkRunner) checkpointSummary() map[string]interface{} {
+	results := map[string]interface{}{}
+	if r := l.resumed; r != nil {
+		metrics, rows := r.Metrics+l.metricCnt, r.Rows+l.rowCnt
+		printFn("loaded %d metrics and %d rows in total, resumed from a checkpoint of %d items\n", metrics, rows, r.Items)
+		results["resumedFrom"] = r
+		results["cumulativeMetrics"] = metrics
+		results["cumulativeRows"] = rows
+	}
+	if cp := l.checkpoints; cp != nil && cp.lastSave != nil {
+		printFn("saved checkpoint of %d items to %s\n", cp.lastSave.Items, l.CheckpointFile)
+		results["checkpoint"] = cp.lastSave
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
@@ -0,0 +1,135 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestScanWithCheckpoints(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	br := &CommonBenchmarkRunner{}
+	br.CheckpointFile = filepath.Join(dir, "checkpoint.json")
+	br.CheckpointInterval = time.Nanosecond
+	br.resumed = &checkpoint{Items: 10, Batches: 5, Metrics: 10, Rows: 10}
+	cp := br.newCheckpointer()
+
+	channels := []*duplexChannel{newDuplexChannel(1)}
+	go func() {
+		for b := range channels[0].toWorker {
+			atomic.AddUint64(&br.metricCnt, uint64(b.Len()))
+			channels[0].sendToScanner()
+		}
+	}()
+	ds := &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{0x00, 0x01, 0x02}))}
+	read := scanWithFlowControl(channels, 2, 0, ds, &testFactory{}, &targets.ConstantIndexer{}, cp)
+	channels[0].close()
+	if read != 3 {
+		t.Fatalf("incorrect items read: got %d want %d", read, 3)
+	}
+
+	got, err := readCheckpoint(br.CheckpointFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the items read are added to the ones resumed from
+	if got.Items != 13 || got.Metrics != 13 {
+		t.Errorf("incorrect checkpoint: got %d items and %d metrics, want %d and %d", got.Items, got.Metrics, 13, 13)
+	}
+	// with a checkpoint due at each item, each one is sent in a batch of its own
+	if got.Batches != 8 {
+		t.Errorf("incorrect checkpoint batches: got %d want %d", got.Batches, 8)
+	}
+}
+
+func TestCheckpointNotSavedOverErrorBudget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	br := &CommonBenchmarkRunner{}
+	br.CheckpointFile = filepath.Join(dir, "checkpoint.json")
+	br.failures = newFailures()
+	br.failures.add(0, targets.BatchResult{FailedMetrics: 1, Err: fmt.Errorf("failed")})
+	br.newCheckpointer().save(1)
+	if _, err := os.Stat(br.CheckpointFile); !os.IsNotExist(err) {
+		t.Errorf("expected no checkpoint past the error budget, got error %v", err)
+	}
+}
+
+func TestResumeFrom(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	f, err := ioutil.TempFile("", "checkpoint_*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	if err := (&checkpoint{Items: 2, Batches: 1, Metrics: 20, Rows: 2}).write(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	br := GetBenchmarkRunner(BenchmarkRunnerConfig{ResumeFrom: f.Name(), Limit: 3, DoCreateDB: true}).(*CommonBenchmarkRunner)
+	if br.Limit != 1 {
+		t.Errorf("incorrect limit left: got %d want %d", br.Limit, 1)
+	}
+	if br.DoCreateDB {
+		t.Errorf("expected the database not to be created again")
+	}
+
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	ds := &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{0x00, 0x01, 0x02}))}
+	br.skipResumed(ds)
+	if p := ds.NextItem(); p.Data != byte(0x02) {
+		t.Errorf("incorrect item after the resumed ones: got %v want %v", p.Data, byte(0x02))
+	}
+
+	br.metricCnt, br.rowCnt = 10, 1
+	results := br.checkpointSummary()
+	want := "resuming after 2 items loaded before (20 metrics, 2 rows)\n" +
+		"loaded 30 metrics and 3 rows in total, resumed from a checkpoint of 2 items\n"
+	if got := b.String(); got != want {
+		t.Errorf("incorrect output\ngot %s\nwant %s", got, want)
+	}
+	if got := results["cumulativeMetrics"]; got != uint64(30) {
+		t.Errorf("incorrect cumulative metrics: got %v want %d", got, 30)
+	}
+}
+
+func TestResumeFromPastLimit(t *testing.T) {
+	f, err := ioutil.TempFile("", "checkpoint_*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	if err := (&checkpoint{Items: 2}).write(f.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a checkpoint past the limit")
+		}
+	}()
+	GetBenchmarkRunner(BenchmarkRunnerConfig{ResumeFrom: f.Name(), Limit: 2})
+}
@@ -1,4 +1,4 @@
-package main
+package config
 
 import (
 	"time"
@@ -29,6 +29,26 @@ type RunnerConfig struct {
 	InsertIntervals string `yaml:"insert-intervals" mapstructure:"insert-intervals"`
 	FlowControl     bool   `yaml:"flow-control" mapstructure:"flow-control"`
 	ChannelCapacity uint   `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	ResultsFile     string `yaml:"results-file" mapstructure:"results-file"`
+	Verify          bool
+	IngestRate      uint64  `yaml:"ingest-rate" mapstructure:"ingest-rate"`
+	IngestRateUnit  string  `yaml:"ingest-rate-unit" mapstructure:"ingest-rate-unit"`
+	LoadProfile     string  `yaml:"load-profile" mapstructure:"load-profile"`
+	HDRLatencies    string  `yaml:"hdr-latencies" mapstructure:"hdr-latencies"`
+	ErrorBudget     float64 `yaml:"error-budget" mapstructure:"error-budget"`
+	MetricsListen   string  `yaml:"metrics-listen" mapstructure:"metrics-listen"`
+	ReportFile      string  `yaml:"report-file" mapstructure:"report-file"`
+	ReportFormat    string  `yaml:"report-format" mapstructure:"report-format"`
+	Duration        time.Duration
+	Loop            bool
+
+	CheckpointFile     string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file"`
+	CheckpointInterval time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval"`
+	ResumeFrom         string        `yaml:"resume-from" mapstructure:"resume-from"`
+
+	CoordinatorListen string `yaml:"coordinator-listen" mapstructure:"coordinator-listen"`
+	Agents            uint
+	Coordinator       string
 }
 
 type DataSourceConfig struct {
@@ -38,7 +58,8 @@ type DataSourceConfig struct {
 }
 
 type FileDataSourceConfig struct {
-	Location string `yaml:"location"`
+	Location  string `yaml:"location"`
+	ReadAhead int    `yaml:"read-ahead" mapstructure:"read-ahead"`
 }
 
 type SimulatorDataSourceConfig struct {
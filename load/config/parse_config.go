@@ -1,4 +1,4 @@
-package main
+package config
 
 import (
 	"errors"
@@ -10,7 +10,9 @@ import (
 	"github.com/timescale/tsbs/pkg/targets"
 )
 
-func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, load.BenchmarkRunner, error) {
+// Parse parses the tsbs_load configuration in v into the Benchmark of the
+// target and the runner that loads its data
+func Parse(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, load.BenchmarkRunner, error) {
 	dataSourceViper := v.Sub("data-source")
 	if dataSourceViper == nil {
 		return nil, nil, fmt.Errorf("config file didn't have a top-level 'data-source' object")
@@ -38,6 +40,15 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 
 	loaderConfigInternal := convertRunnerConfigToInternalRep(loaderConfig)
 
+	// an agent loads the shard of the data assigned by its coordinator
+	var agent *load.Agent
+	if loaderConfigInternal.Coordinator != "" {
+		agent, err = load.JoinCoordinator(loaderConfigInternal, dataSourceInternal)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	dbSpecificViper := loaderViper.Sub("db-specific")
 	if dbSpecificViper == nil {
 		return nil, nil, fmt.Errorf("config file didn't have loader.db-specific specified")
@@ -48,6 +59,9 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 		return nil, nil, err
 	}
 
+	if agent != nil {
+		return benchmark, load.GetAgentBenchmarkRunner(*loaderConfigInternal, agent), nil
+	}
 	return benchmark, load.GetBenchmarkRunner(*loaderConfigInternal), nil
 }
 
@@ -74,6 +88,26 @@ func convertRunnerConfigToInternalRep(r *RunnerConfig) *load.BenchmarkRunnerConf
 		InsertIntervals: r.InsertIntervals,
 		NoFlowControl:   !r.FlowControl,
 		ChannelCapacity: r.ChannelCapacity,
+		ResultsFile:     r.ResultsFile,
+		Verify:          r.Verify,
+		IngestRate:      r.IngestRate,
+		IngestRateUnit:  r.IngestRateUnit,
+		LoadProfile:     r.LoadProfile,
+		HDRLatencies:    r.HDRLatencies,
+		ErrorBudget:     r.ErrorBudget,
+		MetricsListen:   r.MetricsListen,
+		ReportFile:      r.ReportFile,
+		ReportFormat:    r.ReportFormat,
+		Duration:        r.Duration,
+		Loop:            r.Loop,
+
+		CheckpointFile:     r.CheckpointFile,
+		CheckpointInterval: r.CheckpointInterval,
+		ResumeFrom:         r.ResumeFrom,
+
+		CoordinatorListen: r.CoordinatorListen,
+		Agents:            r.Agents,
+		Coordinator:       r.Coordinator,
 	}
 }
 
@@ -121,7 +155,8 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 	var simulator *common.DataGeneratorConfig
 	if d.Type == source.FileDataSourceType {
 		file = &source.FileDataSourceConfig{
-			Location: d.File.Location,
+			Location:  d.File.Location,
+			ReadAhead: d.File.ReadAhead,
 		}
 	} else {
 		simulator = &common.DataGeneratorConfig{
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/initializers"
+)
+
+// Workload is a data load described by a tsbs_load config file. It can be
+// run in the background of another benchmark, e.g. to run queries while
+// data is being loaded.
+type Workload struct {
+	Benchmark targets.Benchmark
+	Runner    load.BenchmarkRunner
+}
+
+// NewWorkload parses the tsbs_load config file at path, as written by
+// `tsbs_load config`, for the target set in its loader.target
+func NewWorkload(path string) (*Workload, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("could not read load config file %s: %v", path, err)
+	}
+	format := v.GetString("loader.target")
+	if format == "" {
+		return nil, fmt.Errorf("config file didn't have loader.target specified")
+	}
+	target := initializers.GetTarget(format)
+
+	// the defaults of the db-specific options the config file does not set
+	flagSet := pflag.NewFlagSet("", pflag.ContinueOnError)
+	target.TargetSpecificFlags("loader.db-specific.", flagSet)
+	if err := v.BindPFlags(flagSet); err != nil {
+		return nil, fmt.Errorf("could not bind db-specific flags for %s: %v", format, err)
+	}
+
+	// the runner of the queries reports the ingest rate of the load along
+	// with the queries, instead of the load printing its own report
+	v.Set("loader.runner.reporting-period", time.Duration(0))
+
+	benchmark, runner, err := Parse(target, v)
+	if err != nil {
+		return nil, err
+	}
+	return &Workload{Benchmark: benchmark, Runner: runner}, nil
+}
+
+// Run loads the data, returning when the load is done
+func (w *Workload) Run() {
+	w.Runner.RunBenchmark(w.Benchmark)
+}
+
+// Progress returns the number of metrics and rows loaded so far
+func (w *Workload) Progress() (metrics, rows uint64) {
+	return w.Runner.Progress()
+}
+
+// Ready returns a channel that is closed once the database is created
+func (w *Workload) Ready() <-chan struct{} {
+	return w.Runner.Ready()
+}
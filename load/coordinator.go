@@ -0,0 +1,295 @@
+package load
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// coordinator assigns each agent that joins it a shard of the load, starts
+// the agents at the same time and collects their reports. The agents connect
+// to it and call the methods of their agentSession over JSON-RPC.
+type coordinator struct {
+	agents int
+	dbName string
+
+	lock    sync.Mutex
+	names   []string
+	ready   int
+	reports []*AgentReport
+	// finished is the number of agents that reported or failed
+	finished int
+	// start is the time the agents were started
+	start time.Time
+	// joined is closed once all agents joined, started once all of them are
+	// ready to load, and done once all of them reported or failed
+	joined  chan struct{}
+	started chan struct{}
+	done    chan struct{}
+}
+
+func newCoordinator(agents int, dbName string) *coordinator {
+	return &coordinator{
+		agents:  agents,
+		dbName:  dbName,
+		reports: make([]*AgentReport, agents),
+		joined:  make(chan struct{}),
+		started: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// serve serves the agents connecting to ln, until it is closed
+func (c *coordinator) serve(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go c.serveAgent(conn)
+	}
+}
+
+// serveAgent serves the calls of the agent connected with conn, until it
+// disconnects
+func (c *coordinator) serveAgent(conn net.Conn) {
+	s := &agentSession{c: c, agent: -1}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName(coordinatorService, s); err != nil {
+		fatal("could not serve agent: %v", err)
+		return
+	}
+	srv.ServeCodec(jsonrpc.NewServerCodec(conn))
+	c.disconnected(s)
+}
+
+func (c *coordinator) join(s *agentSession, name string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.names) == c.agents {
+		return fmt.Errorf("all %d agents joined already", c.agents)
+	}
+	s.agent = len(c.names)
+	c.names = append(c.names, name)
+	printFn("agent %d joined from %s\n", s.agent, name)
+	if len(c.names) == c.agents {
+		close(c.joined)
+	}
+	return nil
+}
+
+func (c *coordinator) setReady() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.ready++
+	if c.ready == c.agents {
+		c.start = time.Now()
+		close(c.started)
+	}
+}
+
+func (c *coordinator) report(s *agentSession, r *AgentReport) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.reports[s.agent] = r
+	s.reported = true
+	printFn("agent %d finished: loaded %d metrics and %d rows\n", s.agent, r.Metrics, r.Rows)
+	c.finish()
+}
+
+// disconnected marks the agent of s as failed if it did not report before
+// disconnecting. The load can't start without all agents, so it is over if
+// an agent disconnects before.
+func (c *coordinator) disconnected(s *agentSession) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if s.agent < 0 || s.reported {
+		return
+	}
+	select {
+	case <-c.started:
+	default:
+		fatal("agent %d (%s) disconnected before the load started", s.agent, c.names[s.agent])
+		return
+	}
+	printFn("agent %d (%s) disconnected before reporting its results\n", s.agent, c.names[s.agent])
+	c.finish()
+}
+
+// finish counts an agent that reported or failed; the caller holds the lock
+func (c *coordinator) finish() {
+	c.finished++
+	if c.finished == c.agents {
+		close(c.done)
+	}
+}
+
+// agentSession serves the calls of one agent to the coordinator
+type agentSession struct {
+	c *coordinator
+	// agent is the index of the agent once it joined, and reported whether it
+	// sent its report; both are guarded by the lock of the coordinator
+	agent    int
+	reported bool
+}
+
+// Join joins the agent to the load, returning its assignment once all agents
+// joined
+func (s *agentSession) Join(req JoinRequest, a *Assignment) error {
+	if err := s.c.join(s, req.Name); err != nil {
+		return err
+	}
+	<-s.c.joined
+	s.c.lock.Lock()
+	*a = Assignment{Agent: s.agent, Agents: s.c.agents, DBName: s.c.dbName}
+	s.c.lock.Unlock()
+	return nil
+}
+
+// Ready waits for all agents to be ready to load, returning the time they
+// start at
+func (s *agentSession) Ready(_ struct{}, start *time.Time) error {
+	s.c.lock.Lock()
+	joined := s.agent >= 0
+	s.c.lock.Unlock()
+	if !joined {
+		return errors.New("the agent did not join the load")
+	}
+	s.c.setReady()
+	<-s.c.started
+	*start = s.c.start
+	return nil
+}
+
+// Report collects the report of the agent once its load is done
+func (s *agentSession) Report(r AgentReport, _ *struct{}) error {
+	s.c.lock.Lock()
+	started := s.agent >= 0
+	s.c.lock.Unlock()
+	if !started {
+		return errors.New("the agent did not join the load")
+	}
+	s.c.report(s, &r)
+	return nil
+}
+
+// coordinate runs the load as the coordinator of --agents agents, that join
+// it at --coordinator-listen
+func (l *CommonBenchmarkRunner) coordinate(b targets.Benchmark) {
+	ln, err := net.Listen("tcp", l.CoordinatorListen)
+	if err != nil {
+		fatal("could not listen for agents on %s: %v", l.CoordinatorListen, err)
+		return
+	}
+	l.coordinateAgents(b, ln)
+}
+
+// coordinateAgents creates the database, assigns the agents that connect to
+// ln their shard of the load once all of them joined, starts them at the
+// same time, and sums up their loads once they are done
+func (l *CommonBenchmarkRunner) coordinateAgents(b targets.Benchmark, ln net.Listener) {
+	defer ln.Close()
+	if dbc := b.GetDBCreator(); dbc != nil {
+		l.dbCreator = dbc
+		cleanupFn := l.useDBCreator(dbc)
+		defer cleanupFn()
+	}
+	l.markReady()
+
+	c := newCoordinator(int(l.Agents), l.DBName)
+	printFn("waiting for %d agents on %s\n", c.agents, ln.Addr())
+	go c.serve(ln)
+	<-c.started
+	printFn("started %d agents\n", c.agents)
+	<-c.done
+	l.agentsSummary(c)
+}
+
+// agentsSummary prints the load of each agent and of all of them, and saves
+// it to the results file
+func (l *CommonBenchmarkRunner) agentsSummary(c *coordinator) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	printFn("\nAgents:\n")
+	var end time.Time
+	var workers uint
+	var failedMetrics uint64
+	failedAgents := 0
+	merged := newLatencyHistogram()
+	perAgent := make([]map[string]interface{}, c.agents)
+	for i, r := range c.reports {
+		if r == nil {
+			printFn("agent %d (%s): failed\n", i, c.names[i])
+			perAgent[i] = map[string]interface{}{"name": c.names[i], "failed": true}
+			failedAgents++
+			continue
+		}
+		took := r.End.Sub(r.Start)
+		// the clocks of the agents may differ from the one of the coordinator
+		startOffset := r.Start.Sub(c.start)
+		printFn("agent %d (%s): loaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec), started %0.3fsec after the coordinator\n",
+			i, r.Name, r.Metrics, took.Seconds(), r.Workers, float64(r.Metrics)/took.Seconds(), startOffset.Seconds())
+		l.metricCnt += r.Metrics
+		l.rowCnt += r.Rows
+		workers += r.Workers
+		failedMetrics += r.FailedMetrics
+		if r.End.After(end) {
+			end = r.End
+		}
+		if r.Latencies != nil {
+			merged.Merge(hdrhistogram.Import(r.Latencies))
+		}
+		l.partial = l.partial || r.Partial
+		perAgent[i] = map[string]interface{}{
+			"name":              r.Name,
+			"workers":           r.Workers,
+			"metrics":           r.Metrics,
+			"rows":              r.Rows,
+			"failedMetrics":     r.FailedMetrics,
+			"startOffsetMillis": startOffset.Milliseconds(),
+			"durationMillis":    took.Milliseconds(),
+			"partial":           r.Partial,
+			"totals":            r.Totals,
+		}
+	}
+	if end.Before(c.start) {
+		end = time.Now()
+	}
+	took := end.Sub(c.start)
+
+	l.Workers = workers
+	l.summary(took)
+	results := map[string]interface{}{"agents": perAgent}
+	if failedMetrics > 0 {
+		printFn("failed to write %d metrics\n", failedMetrics)
+		results["failedMetrics"] = failedMetrics
+	}
+	if merged.TotalCount() > 0 {
+		results["batchLatencies"] = printLatencies(merged)
+	}
+	if failedAgents > 0 {
+		l.partial = true
+		results["failedAgents"] = failedAgents
+	}
+	if l.partial {
+		printFn("partial: the results cover only the data loaded by the agents until they stopped\n")
+	}
+	postLoadResults := l.postLoad()
+	storageResults := l.storage()
+	if l.ResultsFile != "" {
+		metricRate := float64(l.metricCnt) / took.Seconds()
+		rowRate := float64(l.rowCnt) / took.Seconds()
+		l.saveTestResult(took, c.start, end, metricRate, rowRate, results, postLoadResults, storageResults)
+	}
+	if failedAgents > 0 {
+		fatal("%d of %d agents failed", failedAgents, c.agents)
+	}
+}
@@ -0,0 +1,340 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// agentBenchmark loads items bytes, each in a batch of its own with a batch
+// size of 1
+type agentBenchmark struct {
+	items   int
+	creator targets.DBCreator
+}
+
+func (b *agentBenchmark) GetDataSource() targets.DataSource {
+	return &testShardedDataSource{data: make([]byte, b.items)}
+}
+func (b *agentBenchmark) GetBatchFactory() targets.BatchFactory { return &testFactory{} }
+func (b *agentBenchmark) GetPointIndexer(uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+func (b *agentBenchmark) GetProcessor() targets.Processor { return &testProcessor{} }
+func (b *agentBenchmark) GetDBCreator() targets.DBCreator { return b.creator }
+
+// lockedBuffer collects the output of the coordinator and agents running
+// at the same time
+type lockedBuffer struct {
+	lock sync.Mutex
+	b    bytes.Buffer
+}
+
+func (b *lockedBuffer) printf(s string, args ...interface{}) (n int, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return fmt.Fprintf(&b.b, s, args...)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.b.String()
+}
+
+func TestCoordinatorAgents(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	var out lockedBuffer
+	printFn = out.printf
+	resultsFile, err := ioutil.TempFile("", "results_*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultsFile.Close()
+	defer os.Remove(resultsFile.Name())
+	// fewer data files than agents
+	dataFile, err := ioutil.TempFile("", "data_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataFile.Close()
+	defer os.Remove(dataFile.Name())
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	creator := &testCreator{}
+	coordinator := GetBenchmarkRunner(BenchmarkRunnerConfig{
+		DBName:            "benchmark",
+		DoLoad:            true,
+		DoCreateDB:        true,
+		ResultsFile:       resultsFile.Name(),
+		CoordinatorListen: ln.Addr().String(),
+		Agents:            3,
+	}).(*CommonBenchmarkRunner)
+	done := make(chan struct{})
+	go func() {
+		coordinator.coordinateAgents(&agentBenchmark{creator: creator}, ln)
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		go func() {
+			c := BenchmarkRunnerConfig{
+				Coordinator: ln.Addr().String(),
+				BatchSize:   1,
+				Workers:     2,
+				DoLoad:      true,
+				DoCreateDB:  true,
+			}
+			ds := &source.DataSourceConfig{Type: source.FileDataSourceType, File: &source.FileDataSourceConfig{Location: dataFile.Name()}}
+			agent, err := JoinCoordinator(&c, ds)
+			if err != nil {
+				t.Errorf("could not join: %v", err)
+				return
+			}
+			if c.DBName != "benchmark" || c.DoCreateDB {
+				t.Errorf("agent not set to load into the database of the coordinator: db %s, create %v", c.DBName, c.DoCreateDB)
+			}
+			GetAgentBenchmarkRunner(c, agent).RunBenchmark(&agentBenchmark{items: 10})
+		}()
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("the load of the agents did not finish, output:\n%s", out.String())
+	}
+
+	if !creator.createCalled {
+		t.Errorf("database not created by the coordinator")
+	}
+	// the agents loaded a third of the items each
+	if coordinator.metricCnt != 10 {
+		t.Errorf("incorrect metrics loaded by all agents: got %d want %d", coordinator.metricCnt, 10)
+	}
+	if coordinator.Workers != 6 {
+		t.Errorf("incorrect workers of all agents: got %d want %d", coordinator.Workers, 6)
+	}
+	if got := out.String(); !strings.Contains(got, "loaded 10 metrics in") {
+		t.Errorf("summary of all agents missing, output:\n%s", got)
+	}
+
+	content, err := ioutil.ReadFile(resultsFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result LoaderTestResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatal(err)
+	}
+	agents, ok := result.Totals["agents"].([]interface{})
+	if !ok || len(agents) != 3 {
+		t.Fatalf("incorrect agents in the results:\n%s", content)
+	}
+	for i, a := range agents {
+		metrics := a.(map[string]interface{})["metrics"].(float64)
+		if metrics < 3 || metrics > 4 {
+			t.Errorf("incorrect metrics of agent %d: got %v want 3 or 4", i, metrics)
+		}
+	}
+	if result.Partial {
+		t.Errorf("results of a complete load marked as partial")
+	}
+}
+
+func TestJoinCoordinatorFileRange(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	var out lockedBuffer
+	printFn = out.printf
+	dir, err := ioutil.TempDir("", "agent_files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a", "b", "c"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	c := newCoordinator(2, "benchmark")
+	go c.serve(ln)
+
+	locations := make([]string, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ds := &source.DataSourceConfig{Type: source.FileDataSourceType, File: &source.FileDataSourceConfig{Location: dir}}
+			agent, err := JoinCoordinator(&BenchmarkRunnerConfig{Coordinator: ln.Addr().String()}, ds)
+			if err != nil {
+				t.Errorf("could not join: %v", err)
+				return
+			}
+			if agent.shardData {
+				t.Errorf("agent %d loads a part of the data instead of a range of the files", agent.Agent)
+			}
+			locations[agent.Agent] = ds.File.Location
+			// disconnecting after the start fails the agent
+			agent.waitForStart()
+			agent.client.Close()
+		}()
+	}
+	wg.Wait()
+	select {
+	case <-c.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("agents that disconnected not counted as failed")
+	}
+
+	sep := string(os.PathListSeparator)
+	want := []string{filepath.Join(dir, "a"), filepath.Join(dir, "b") + sep + filepath.Join(dir, "c")}
+	for i := range want {
+		if locations[i] != want[i] {
+			t.Errorf("incorrect files of agent %d: got %s want %s", i, locations[i], want[i])
+		}
+	}
+	if got := out.String(); !strings.Contains(got, "disconnected before reporting its results") {
+		t.Errorf("failed agents not reported, output:\n%s", got)
+	}
+}
+
+func TestJoinCoordinatorSimulatorGroups(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	var out lockedBuffer
+	printFn = out.printf
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	c := newCoordinator(2, "benchmark")
+	go c.serve(ln)
+
+	groups := make([]*common.DataGeneratorConfig, 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ds := &source.DataSourceConfig{
+				Type:      source.SimulatorDataSourceType,
+				Simulator: &common.DataGeneratorConfig{BaseConfig: common.BaseConfig{Scale: 10}, InterleavedNumGroups: 1},
+			}
+			agent, err := JoinCoordinator(&BenchmarkRunnerConfig{Coordinator: ln.Addr().String()}, ds)
+			if err != nil {
+				t.Errorf("could not join: %v", err)
+				return
+			}
+			if agent.shardData {
+				t.Errorf("agent %d loads a part of the data instead of the hosts of its group", agent.Agent)
+			}
+			groups[agent.Agent] = ds.Simulator
+			agent.waitForStart()
+			agent.client.Close()
+		}()
+	}
+	wg.Wait()
+	select {
+	case <-c.done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("agents that disconnected not counted as failed")
+	}
+
+	for i, g := range groups {
+		if g == nil {
+			continue
+		}
+		if g.InterleavedGroupID != uint(i) || g.InterleavedNumGroups != 2 {
+			t.Errorf("incorrect group of agent %d: got %d of %d want %d of %d", i, g.InterleavedGroupID, g.InterleavedNumGroups, i, 2)
+		}
+	}
+}
+
+// testShardedDataSource reads the part of its bytes of its shard
+type testShardedDataSource struct {
+	testDataSource
+	data []byte
+}
+
+// NextItem reads all of its bytes if it is not sharded
+func (d *testShardedDataSource) NextItem() data.LoadedPoint {
+	if d.br == nil {
+		d.br = bufio.NewReader(bytes.NewReader(d.data))
+	}
+	return d.testDataSource.NextItem()
+}
+
+func (d *testShardedDataSource) Shard(shard, shards int) bool {
+	if d.data == nil {
+		return false
+	}
+	from, to := shard*len(d.data)/shards, (shard+1)*len(d.data)/shards
+	d.br = bufio.NewReader(bytes.NewReader(d.data[from:to]))
+	return true
+}
+
+func TestShardDataSource(t *testing.T) {
+	oldPrintFn, oldFatal := printFn, fatal
+	defer func() { printFn, fatal = oldPrintFn, oldFatal }()
+	var out lockedBuffer
+	printFn = out.printf
+	var fatalMsg string
+	fatal = func(format string, args ...interface{}) {
+		fatalMsg = fmt.Sprintf(format, args...)
+	}
+	a := &Agent{Assignment: Assignment{Agent: 1, Agents: 2}, shardData: true}
+
+	data := []byte{0, 1, 2, 3}
+	sds := &testShardedDataSource{data: data}
+	ds := a.shardDataSource(sds)
+	var got []byte
+	for p := ds.NextItem(); p.Data != nil; p = ds.NextItem() {
+		got = append(got, p.Data.(byte))
+	}
+	if !bytes.Equal(got, []byte{2, 3}) {
+		t.Errorf("incorrect items of the shard: got %v want %v", got, []byte{2, 3})
+	}
+
+	// data that can't be split is not read in full by every agent
+	a.shardDataSource(&testDataSource{})
+	if !strings.Contains(fatalMsg, "cannot split the data") {
+		t.Errorf("expected a DataSource that can't be sharded to fail, got %q", fatalMsg)
+	}
+	fatalMsg = ""
+	a.shardDataSource(&testShardedDataSource{})
+	if !strings.Contains(fatalMsg, "cannot split the data") {
+		t.Errorf("expected data that can't be split to fail, got %q", fatalMsg)
+	}
+
+	fatalMsg = ""
+	a.shardData = false
+	if _, ok := a.shardDataSource(&testDataSource{}).(*testDataSource); !ok || fatalMsg != "" {
+		t.Errorf("expected the DataSource of a range of the files to be read as is")
+	}
+}
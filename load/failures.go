@@ -0,0 +1,116 @@
+package load
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// failures counts the metrics, rows and batches that could not be written,
+// by ErrorClass
+type failures struct {
+	lock    sync.Mutex
+	metrics uint64
+	rows    uint64
+	batches uint64
+	byClass map[targets.ErrorClass]uint64
+}
+
+func newFailures() *failures {
+	return &failures{byClass: make(map[targets.ErrorClass]uint64)}
+}
+
+// add counts a failed batch. The first error of each class is logged, the
+// ones after it are only counted.
+func (f *failures) add(workerNum uint, res targets.BatchResult) {
+	class := res.ErrorClass
+	if class == "" {
+		class = targets.ErrorClassOther
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.metrics += res.FailedMetrics
+	f.rows += res.FailedRows
+	f.batches++
+	f.byClass[class]++
+	if f.byClass[class] == 1 {
+		log.Printf("worker %d could not write a batch (%s error, further ones are only counted): %v", workerNum, class, res.Err)
+	}
+}
+
+// failedMetrics returns the number of metrics that could not be written
+func (f *failures) failedMetrics() uint64 {
+	if f == nil {
+		return 0
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.metrics
+}
+
+// processBatch writes the batch with the processor and counts the metrics
+// and rows written, and the failures if the processor reports them
+func (l *CommonBenchmarkRunner) processBatch(proc targets.Processor, batch targets.Batch, workerNum uint) (metricCnt, rowCnt uint64) {
+	var res targets.BatchResult
+	l.liveMetrics.batchStarted()
+	start := time.Now()
+	if rp, ok := proc.(targets.ResultProcessor); ok {
+		res = rp.ProcessBatchWithResult(batch, l.DoLoad)
+	} else {
+		res.Metrics, res.Rows = proc.ProcessBatch(batch, l.DoLoad)
+	}
+	l.liveMetrics.batchDone(time.Since(start), res)
+	atomic.AddUint64(&l.metricCnt, res.Metrics)
+	atomic.AddUint64(&l.rowCnt, res.Rows)
+	if res.Err != nil {
+		l.failures.add(workerNum, res)
+		if l.errorBudgetExceeded() {
+			l.stop.now()
+		}
+	}
+	return res.Metrics, res.Rows
+}
+
+// errorBudgetExceeded returns whether more than the ErrorBudget fraction of
+// the metrics failed to be written
+func (l *CommonBenchmarkRunner) errorBudgetExceeded() bool {
+	failed := l.failures.failedMetrics()
+	if failed == 0 {
+		return false
+	}
+	total := atomic.LoadUint64(&l.metricCnt) + failed
+	return float64(failed) > l.ErrorBudget*float64(total)
+}
+
+// failureSummary prints what could not be written, by error class
+func (l *CommonBenchmarkRunner) failureSummary() map[string]interface{} {
+	if l.failures == nil {
+		return nil
+	}
+	f := l.failures
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	classes := make([]string, 0, len(f.byClass))
+	byClass := make(map[string]interface{}, len(f.byClass))
+	for class, n := range f.byClass {
+		classes = append(classes, fmt.Sprintf("%s: %d", class, n))
+		byClass[string(class)] = n
+	}
+	sort.Strings(classes)
+	if f.batches > 0 {
+		printFn("failed to write %d metrics and %d rows in %d batches (%s)\n", f.metrics, f.rows, f.batches, strings.Join(classes, ", "))
+	}
+	return map[string]interface{}{
+		"failedMetrics": f.metrics,
+		"failedRows":    f.rows,
+		"failedBatches": f.batches,
+		"errors":        byClass,
+	}
+}
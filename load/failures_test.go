@@ -0,0 +1,99 @@
+package load
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// testResultProcessor is a ResultProcessor that returns the given results in
+// turn
+type testResultProcessor struct {
+	testProcessor
+	results []targets.BatchResult
+}
+
+func (p *testResultProcessor) ProcessBatchWithResult(targets.Batch, bool) targets.BatchResult {
+	res := p.results[0]
+	p.results = p.results[1:]
+	return res
+}
+
+func TestProcessBatch(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	br := &CommonBenchmarkRunner{}
+	br.failures = newFailures()
+	br.stop = newStopSignal()
+	br.ErrorBudget = 0.25
+
+	failed := targets.BatchResult{Metrics: 5, Rows: 1}
+	failed.Fail(5, 1, errors.New("rejected"), targets.ErrorClassRejected)
+	timedOut := targets.BatchResult{}
+	timedOut.Fail(10, 2, errors.New("timeout"), targets.ErrorClassTimeout)
+	proc := &testResultProcessor{results: []targets.BatchResult{
+		{Metrics: 20, Rows: 4},
+		failed,
+		timedOut,
+	}}
+
+	checkStopped := func(desc string, want bool) {
+		select {
+		case <-br.stop.done():
+			if !want {
+				t.Errorf("%s: stopped within the error budget", desc)
+			}
+		default:
+			if want {
+				t.Errorf("%s: not stopped when exceeding the error budget", desc)
+			}
+		}
+	}
+
+	if metrics, rows := br.processBatch(proc, nil, 0); metrics != 20 || rows != 4 {
+		t.Errorf("incorrect counts: got %d metrics %d rows want 20 metrics 4 rows", metrics, rows)
+	}
+	checkStopped("no failures", false)
+	// 5 of 30 metrics failed
+	br.processBatch(proc, nil, 1)
+	checkStopped("within budget", false)
+	// 15 of 40 metrics failed
+	br.processBatch(proc, nil, 0)
+	checkStopped("over budget", true)
+
+	if br.metricCnt != 25 || br.rowCnt != 5 {
+		t.Errorf("incorrect totals: got %d metrics %d rows want 25 metrics 5 rows", br.metricCnt, br.rowCnt)
+	}
+
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	results := br.failureSummary()
+	want := "failed to write 15 metrics and 3 rows in 2 batches (rejected: 1, timeout: 1)\n"
+	if got := b.String(); got != want {
+		t.Errorf("incorrect summary\ngot %s\nwant %s", got, want)
+	}
+	if got := results["failedMetrics"]; got != uint64(15) {
+		t.Errorf("incorrect failed metrics: got %v want 15", got)
+	}
+	errs := results["errors"].(map[string]interface{})
+	if got := errs["timeout"]; got != uint64(1) {
+		t.Errorf("incorrect timeout errors: got %v want 1", got)
+	}
+}
+
+func TestProcessBatchNoResult(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	proc := &testProcessor{}
+	metrics, rows := br.processBatch(proc, nil, 0)
+	if metrics != 1 || rows != 0 {
+		t.Errorf("incorrect counts: got %d metrics %d rows want 1 metric 0 rows", metrics, rows)
+	}
+	if br.errorBudgetExceeded() {
+		t.Errorf("error budget exceeded without failures")
+	}
+}
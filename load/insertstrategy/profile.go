@@ -0,0 +1,269 @@
+package insertstrategy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// phaseTick is how often a PhaseRegulator updates the rate and the active
+// workers, so a ramp changes the rate in small steps
+const phaseTick = 100 * time.Millisecond
+
+// Phase is a part of a load Profile, run for its duration at a rate and/or
+// with a number of workers.
+type Phase struct {
+	Name     string        `yaml:"name"`
+	Duration time.Duration `yaml:"duration"`
+	// Rate is the ingest rate per second, 0 for no limit. With RampTo set the
+	// rate changes linearly from Rate to RampTo over the phase.
+	Rate   uint64 `yaml:"rate"`
+	RampTo uint64 `yaml:"ramp-to"`
+	// Workers is the number of workers inserting, 0 for all of them
+	Workers uint `yaml:"workers"`
+}
+
+// Profile is a schedule of phases that the load follows, e.g.
+//
+//	unit: rows
+//	phases:
+//	  - name: ramp-up
+//	    duration: 10m
+//	    rate: 100000
+//	    ramp-to: 1000000
+//	  - name: steady
+//	    duration: 30m
+//	    rate: 1000000
+//	  - name: spike
+//	    duration: 60s
+//	    rate: 3000000
+//
+// The load stops when the last phase is over.
+type Profile struct {
+	// Unit of the rates, rows (default) or metrics
+	Unit   string  `yaml:"unit"`
+	Phases []Phase `yaml:"phases"`
+}
+
+// ReadProfile reads a Profile from a YAML file
+func ReadProfile(path string) (*Profile, error) {
+	in, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read load profile: %v", err)
+	}
+	return parseProfile(in)
+}
+
+func parseProfile(in []byte) (*Profile, error) {
+	var p Profile
+	if err := yaml.UnmarshalStrict(in, &p); err != nil {
+		return nil, fmt.Errorf("could not parse load profile: %v", err)
+	}
+	if p.Unit == "" {
+		p.Unit = RateUnitRows
+	}
+	return &p, nil
+}
+
+// Validate checks that the profile has phases that can be run with the
+// given number of workers
+func (p *Profile) Validate(numWorkers uint) error {
+	if err := validateRateUnit(p.Unit); err != nil {
+		return err
+	}
+	if len(p.Phases) == 0 {
+		return fmt.Errorf("load profile has no phases")
+	}
+	for i, ph := range p.Phases {
+		if ph.Name == "" {
+			return fmt.Errorf("phase %d has no name", i)
+		}
+		if ph.Duration <= 0 {
+			return fmt.Errorf("phase %s must have a positive duration", ph.Name)
+		}
+		if ph.RampTo > 0 && ph.Rate == 0 {
+			return fmt.Errorf("phase %s ramps to a rate, but has no rate to start from", ph.Name)
+		}
+		if ph.Workers > numWorkers {
+			return fmt.Errorf("phase %s has %d workers, but only %d are started", ph.Name, ph.Workers, numWorkers)
+		}
+	}
+	return nil
+}
+
+// UsesWorkers returns whether a phase runs with fewer than all workers
+func (p *Profile) UsesWorkers() bool {
+	for _, ph := range p.Phases {
+		if ph.Workers > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// at returns the index of the phase at the given time since the start of
+// the profile and the rate at that time, or len(p.Phases) once it is over
+func (p *Profile) at(elapsed time.Duration) (int, float64) {
+	for i, ph := range p.Phases {
+		if elapsed < ph.Duration {
+			rate := float64(ph.Rate)
+			if ph.RampTo > 0 {
+				progress := float64(elapsed) / float64(ph.Duration)
+				rate += (float64(ph.RampTo) - float64(ph.Rate)) * progress
+			}
+			return i, rate
+		}
+		elapsed -= ph.Duration
+	}
+	return len(p.Phases), 0
+}
+
+// PhaseRegulator makes the load follow a Profile: it sets the rate of its
+// RateRegulator and pauses the workers not used in the current phase.
+type PhaseRegulator struct {
+	profile    *Profile
+	numWorkers uint
+	rate       *RateRegulator
+	nowFn      nowProviderFn
+
+	lock    sync.Mutex
+	changed *sync.Cond // signalled when the phase or the active workers change
+	phase   int
+	active  uint
+	done    chan struct{}
+	stopped bool
+}
+
+// NewPhaseRegulator returns a PhaseRegulator for the profile, which is run
+// by the given number of workers
+func NewPhaseRegulator(profile *Profile, numWorkers uint) (*PhaseRegulator, error) {
+	if err := profile.Validate(numWorkers); err != nil {
+		return nil, err
+	}
+	r := &PhaseRegulator{
+		profile:    profile,
+		numWorkers: numWorkers,
+		rate:       newRateRegulator(0, profile.Unit),
+		nowFn:      time.Now,
+		done:       make(chan struct{}),
+	}
+	r.changed = sync.NewCond(&r.lock)
+	r.update(0)
+	return r, nil
+}
+
+// RateRegulator returns the RateRegulator whose rate follows the profile
+func (r *PhaseRegulator) RateRegulator() *RateRegulator {
+	return r.rate
+}
+
+// Phases returns the phases of the profile
+func (r *PhaseRegulator) Phases() []Phase {
+	return r.profile.Phases
+}
+
+// Start starts following the profile. onChange is called with the index of
+// each phase when it starts, and with the number of phases when the last
+// one is over.
+func (r *PhaseRegulator) Start(onChange func(phase int)) {
+	start := r.nowFn()
+	onChange(0)
+	go func() {
+		ticker := time.NewTicker(phaseTick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.done:
+				// stopped before the last phase is over
+				return
+			case <-ticker.C:
+			}
+			prev := r.Phase()
+			phase := r.update(r.nowFn().Sub(start))
+			for i := prev + 1; i <= phase; i++ {
+				onChange(i)
+			}
+			if phase == len(r.profile.Phases) {
+				return
+			}
+		}
+	}()
+}
+
+// update sets the rate and the active workers for the time since the start
+// of the profile, and returns the current phase
+func (r *PhaseRegulator) update(elapsed time.Duration) int {
+	phase, rate := r.profile.at(elapsed)
+	r.rate.SetRate(rate)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.stopped || phase == r.phase && elapsed > 0 {
+		return r.phase
+	}
+	r.phase = phase
+	if phase == len(r.profile.Phases) {
+		r.stop()
+		return phase
+	}
+	r.active = r.profile.Phases[phase].Workers
+	if r.active == 0 {
+		r.active = r.numWorkers
+	}
+	r.changed.Broadcast()
+	return phase
+}
+
+// Phase returns the index of the current phase, or the number of phases
+// once the profile is over
+func (r *PhaseRegulator) Phase() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.phase
+}
+
+// PhaseName returns the name of the current phase, or "" once the profile
+// is over
+func (r *PhaseRegulator) PhaseName() string {
+	phase := r.Phase()
+	if phase >= len(r.profile.Phases) {
+		return ""
+	}
+	return r.profile.Phases[phase].Name
+}
+
+// Done returns a channel that is closed when the last phase is over
+func (r *PhaseRegulator) Done() <-chan struct{} {
+	return r.done
+}
+
+// WaitActive blocks while the worker is not used in the current phase. It
+// returns right away once the profile is over or stopped, so the workers
+// can finish.
+func (r *PhaseRegulator) WaitActive(workerNum int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for !r.stopped && uint(workerNum) >= r.active {
+		r.changed.Wait()
+	}
+}
+
+// Stop releases all waiting workers, e.g. when the data is used up before
+// the last phase is over
+func (r *PhaseRegulator) Stop() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.stop()
+}
+
+func (r *PhaseRegulator) stop() {
+	if r.stopped {
+		return
+	}
+	r.stopped = true
+	close(r.done)
+	r.changed.Broadcast()
+}
@@ -0,0 +1,200 @@
+package insertstrategy
+
+import (
+	"testing"
+	"time"
+)
+
+const testProfile = `
+phases:
+  - name: ramp-up
+    duration: 10m
+    rate: 100000
+    ramp-to: 1000000
+  - name: steady
+    duration: 30m
+    rate: 1000000
+    workers: 2
+  - name: spike
+    duration: 60s
+    rate: 3000000
+`
+
+func TestParseProfile(t *testing.T) {
+	p, err := parseProfile([]byte(testProfile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Unit != RateUnitRows {
+		t.Errorf("expected default unit %s, got %s", RateUnitRows, p.Unit)
+	}
+	want := []Phase{
+		{Name: "ramp-up", Duration: 10 * time.Minute, Rate: 100000, RampTo: 1000000},
+		{Name: "steady", Duration: 30 * time.Minute, Rate: 1000000, Workers: 2},
+		{Name: "spike", Duration: time.Minute, Rate: 3000000},
+	}
+	if len(p.Phases) != len(want) {
+		t.Fatalf("expected %d phases, got %d", len(want), len(p.Phases))
+	}
+	for i := range want {
+		if p.Phases[i] != want[i] {
+			t.Errorf("phase %d: expected %v, got %v", i, want[i], p.Phases[i])
+		}
+	}
+	if !p.UsesWorkers() {
+		t.Errorf("expected profile to use workers")
+	}
+
+	if _, err := parseProfile([]byte("phases:\n  - name: a\n    speed: 1\n")); err == nil {
+		t.Errorf("expected error on unknown field")
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		profile   Profile
+		expectErr bool
+	}{
+		{
+			desc:      "invalid unit",
+			profile:   Profile{Unit: "points", Phases: []Phase{{Name: "a", Duration: time.Second}}},
+			expectErr: true,
+		}, {
+			desc:      "no phases",
+			profile:   Profile{Unit: RateUnitRows},
+			expectErr: true,
+		}, {
+			desc:      "no name",
+			profile:   Profile{Unit: RateUnitRows, Phases: []Phase{{Duration: time.Second}}},
+			expectErr: true,
+		}, {
+			desc:      "no duration",
+			profile:   Profile{Unit: RateUnitRows, Phases: []Phase{{Name: "a"}}},
+			expectErr: true,
+		}, {
+			desc:      "ramp without rate",
+			profile:   Profile{Unit: RateUnitRows, Phases: []Phase{{Name: "a", Duration: time.Second, RampTo: 10}}},
+			expectErr: true,
+		}, {
+			desc:      "more workers than started",
+			profile:   Profile{Unit: RateUnitRows, Phases: []Phase{{Name: "a", Duration: time.Second, Workers: 5}}},
+			expectErr: true,
+		}, {
+			desc:    "valid",
+			profile: Profile{Unit: RateUnitMetrics, Phases: []Phase{{Name: "a", Duration: time.Second, Workers: 4}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.profile.Validate(4)
+			if err != nil && !tc.expectErr {
+				t.Errorf("unexpected error: %v", err)
+			} else if err == nil && tc.expectErr {
+				t.Error("unexpected lack of error")
+			}
+		})
+	}
+}
+
+func TestProfileAt(t *testing.T) {
+	p, err := parseProfile([]byte(testProfile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testCases := []struct {
+		elapsed   time.Duration
+		wantPhase int
+		wantRate  float64
+	}{
+		{elapsed: 0, wantPhase: 0, wantRate: 100000},
+		{elapsed: 5 * time.Minute, wantPhase: 0, wantRate: 550000},
+		{elapsed: 10 * time.Minute, wantPhase: 1, wantRate: 1000000},
+		{elapsed: 40*time.Minute + time.Second, wantPhase: 2, wantRate: 3000000},
+		{elapsed: 41 * time.Minute, wantPhase: 3, wantRate: 0},
+	}
+	for _, tc := range testCases {
+		phase, rate := p.at(tc.elapsed)
+		if phase != tc.wantPhase || rate != tc.wantRate {
+			t.Errorf("at %v: expected phase %d at %f, got phase %d at %f", tc.elapsed, tc.wantPhase, tc.wantRate, phase, rate)
+		}
+	}
+}
+
+func TestPhaseRegulator(t *testing.T) {
+	p, err := parseProfile([]byte(testProfile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r, err := NewPhaseRegulator(p, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := r.PhaseName(); got != "ramp-up" {
+		t.Errorf("expected phase ramp-up, got %s", got)
+	}
+	// all workers are active in the first phase
+	r.WaitActive(3)
+
+	if got := r.update(10 * time.Minute); got != 1 {
+		t.Errorf("expected phase 1, got %d", got)
+	}
+	if got := r.RateRegulator().rate; got != 1000000 {
+		t.Errorf("expected rate 1000000, got %f", got)
+	}
+	r.WaitActive(1)
+	waited := make(chan struct{})
+	go func() {
+		r.WaitActive(2)
+		close(waited)
+	}()
+	select {
+	case <-waited:
+		t.Fatalf("worker 2 not paused in phase steady")
+	case <-time.After(20 * time.Millisecond):
+	}
+	r.update(40 * time.Minute)
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatalf("worker 2 not released in phase spike")
+	}
+
+	if got := r.update(41 * time.Minute); got != 3 {
+		t.Errorf("expected the profile to be over, got phase %d", got)
+	}
+	if got := r.PhaseName(); got != "" {
+		t.Errorf("expected no phase name, got %s", got)
+	}
+	select {
+	case <-r.Done():
+	default:
+		t.Errorf("expected done to be closed")
+	}
+	// stopping again is fine
+	r.Stop()
+}
+
+func TestPhaseRegulatorStop(t *testing.T) {
+	p := &Profile{Unit: RateUnitRows, Phases: []Phase{{Name: "a", Duration: time.Hour, Workers: 1}}}
+	r, err := NewPhaseRegulator(p, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var phases []int
+	r.Start(func(phase int) { phases = append(phases, phase) })
+	waited := make(chan struct{})
+	go func() {
+		r.WaitActive(1)
+		close(waited)
+	}()
+	r.Stop()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatalf("worker 1 not released on stop")
+	}
+	if len(phases) != 1 || phases[0] != 0 {
+		t.Errorf("expected only phase 0 to start, got %v", phases)
+	}
+}
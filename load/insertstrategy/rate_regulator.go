@@ -0,0 +1,97 @@
+package insertstrategy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// RateUnitRows makes a RateRegulator limit the rows inserted per second
+	RateUnitRows = "rows"
+	// RateUnitMetrics makes a RateRegulator limit the metrics inserted per second
+	RateUnitMetrics = "metrics"
+)
+
+type sleepFn func(time.Duration)
+
+// RateRegulator limits the number of rows or metrics inserted per second by
+// all load workers together. It is a token bucket that a worker pays into
+// after each insert, for the rows or metrics it inserted, so the rate is
+// kept with any batch size, also below one batch per second.
+type RateRegulator struct {
+	unit  string
+	lock  sync.Mutex
+	rate  float64   // 0 for no limit
+	next  time.Time // the time by which the inserts paid so far are due
+	nowFn nowProviderFn
+	sleep sleepFn
+}
+
+// NewRateRegulator returns a RateRegulator for the given rate of rows or
+// metrics (see unit) per second
+func NewRateRegulator(rate uint64, unit string) (*RateRegulator, error) {
+	if rate == 0 {
+		return nil, fmt.Errorf("rate must be positive")
+	}
+	if err := validateRateUnit(unit); err != nil {
+		return nil, err
+	}
+	return newRateRegulator(float64(rate), unit), nil
+}
+
+func newRateRegulator(rate float64, unit string) *RateRegulator {
+	return &RateRegulator{
+		rate:  rate,
+		unit:  unit,
+		nowFn: time.Now,
+		sleep: time.Sleep,
+	}
+}
+
+func validateRateUnit(unit string) error {
+	if unit != RateUnitRows && unit != RateUnitMetrics {
+		return fmt.Errorf("invalid rate unit '%s', valid: %s, %s", unit, RateUnitRows, RateUnitMetrics)
+	}
+	return nil
+}
+
+// SetRate changes the rate, with 0 removing the limit
+func (r *RateRegulator) SetRate(rate float64) {
+	r.lock.Lock()
+	r.rate = rate
+	r.lock.Unlock()
+}
+
+// Unit returns whether rows or metrics per second are limited
+func (r *RateRegulator) Unit() string {
+	return r.unit
+}
+
+// Sleep makes the worker that started an insert of the given number of
+// metrics and rows at startedWorkAt sleep until the insert is due at the
+// requested rate
+func (r *RateRegulator) Sleep(startedWorkAt time.Time, metrics, rows uint64) {
+	n := rows
+	if r.unit == RateUnitMetrics {
+		n = metrics
+	}
+
+	r.lock.Lock()
+	if r.rate == 0 {
+		r.lock.Unlock()
+		return
+	}
+	took := time.Duration(float64(n) / r.rate * float64(time.Second))
+	// tokens are not saved up while the workers are slower than the rate
+	if r.next.Before(startedWorkAt) {
+		r.next = startedWorkAt
+	}
+	r.next = r.next.Add(took)
+	due := r.next
+	r.lock.Unlock()
+
+	if wait := due.Sub(r.nowFn()); wait > 0 {
+		r.sleep(wait)
+	}
+}
@@ -0,0 +1,108 @@
+package insertstrategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRateRegulator(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		rate      uint64
+		unit      string
+		expectErr bool
+	}{
+		{desc: "Error on 0 rate", unit: RateUnitRows, expectErr: true},
+		{desc: "Error on invalid unit", rate: 10, unit: "points", expectErr: true},
+		{desc: "Rows per second", rate: 10, unit: RateUnitRows},
+		{desc: "Metrics per second", rate: 10, unit: RateUnitMetrics},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			res, err := NewRateRegulator(tc.rate, tc.unit)
+			if err != nil && !tc.expectErr {
+				t.Errorf("unexpected error: %v", err)
+			} else if err == nil && tc.expectErr {
+				t.Error("unexpected lack of error")
+			} else if err == nil && res.Unit() != tc.unit {
+				t.Errorf("expected unit %s, got %s", tc.unit, res.Unit())
+			}
+		})
+	}
+}
+
+func TestRateRegulatorSleep(t *testing.T) {
+	start := time.Unix(0, 0)
+	testCases := []struct {
+		desc    string
+		unit    string
+		now     time.Duration // since start, when the insert is done
+		started []time.Duration
+		metrics uint64
+		rows    uint64
+		want    []time.Duration
+	}{
+		{
+			desc:    "sub-second pacing of rows",
+			unit:    RateUnitRows,
+			now:     10 * time.Millisecond,
+			started: []time.Duration{0},
+			metrics: 1000,
+			rows:    100,
+			want:    []time.Duration{90 * time.Millisecond},
+		}, {
+			desc:    "metrics are limited",
+			unit:    RateUnitMetrics,
+			now:     10 * time.Millisecond,
+			started: []time.Duration{0},
+			metrics: 1000,
+			rows:    100,
+			want:    []time.Duration{990 * time.Millisecond},
+		}, {
+			desc:    "concurrent inserts queue up",
+			unit:    RateUnitRows,
+			now:     10 * time.Millisecond,
+			started: []time.Duration{0, 0, 0},
+			rows:    100,
+			want:    []time.Duration{90 * time.Millisecond, 190 * time.Millisecond, 290 * time.Millisecond},
+		}, {
+			desc:    "no sleep when slower than the rate",
+			unit:    RateUnitRows,
+			now:     150 * time.Millisecond,
+			started: []time.Duration{0},
+			rows:    100,
+			want:    []time.Duration{0},
+		}, {
+			desc:    "no tokens saved up while idle",
+			unit:    RateUnitRows,
+			now:     2010 * time.Millisecond,
+			started: []time.Duration{0, 2 * time.Second},
+			rows:    100,
+			want:    []time.Duration{0, 90 * time.Millisecond},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			r, err := NewRateRegulator(1000, tc.unit)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			r.nowFn = func() time.Time { return start.Add(tc.now) }
+			var slept []time.Duration
+			r.sleep = func(d time.Duration) { slept = append(slept, d) }
+			for i, s := range tc.started {
+				sleptBefore := len(slept)
+				r.Sleep(start.Add(s), tc.metrics, tc.rows)
+				got := time.Duration(0)
+				if len(slept) > sleptBefore {
+					got = slept[len(slept)-1]
+				}
+				if got != tc.want[i] {
+					t.Errorf("insert %d: expected sleep of %v, got %v", i, tc.want[i], got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package load
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// latencyScale is the number of histogram values (microseconds) per millisecond
+const latencyScale = 1e3
+
+// newLatencyHistogram returns a histogram of batch latencies in microseconds
+// from 1us to an hour, with 3 significant digits
+func newLatencyHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(1, 3600000000, 3)
+}
+
+// recordLatency records how long the worker took to process a batch. Each
+// worker has its own histogram, so no locking is needed.
+func (l *CommonBenchmarkRunner) recordLatency(workerNum uint, took time.Duration) {
+	if l.latencies == nil {
+		return
+	}
+	l.latencies[workerNum].RecordValue(took.Microseconds())
+}
+
+// latencyQuantiles returns the p50, p95, p99, p999 and max of the histogram
+// in milliseconds, and the number of batches
+func latencyQuantiles(h *hdrhistogram.Histogram) map[string]interface{} {
+	return map[string]interface{}{
+		"p50":   float64(h.ValueAtQuantile(50.0)) / latencyScale,
+		"p95":   float64(h.ValueAtQuantile(95.0)) / latencyScale,
+		"p99":   float64(h.ValueAtQuantile(99.0)) / latencyScale,
+		"p999":  float64(h.ValueAtQuantile(99.9)) / latencyScale,
+		"max":   float64(h.Max()) / latencyScale,
+		"count": h.TotalCount(),
+	}
+}
+
+// mergeLatencies returns the histogram of the batch latencies of all the
+// histograms
+func mergeLatencies(histograms []*hdrhistogram.Histogram) *hdrhistogram.Histogram {
+	merged := newLatencyHistogram()
+	for _, h := range histograms {
+		merged.Merge(h)
+	}
+	return merged
+}
+
+// printLatencies prints the percentiles of the batch latencies and returns
+// them
+func printLatencies(h *hdrhistogram.Histogram) map[string]interface{} {
+	all := latencyQuantiles(h)
+	printFn("batch latency: p50 %0.2fms, p95 %0.2fms, p99 %0.2fms, p999 %0.2fms, max %0.2fms (%d batches)\n",
+		all["p50"], all["p95"], all["p99"], all["p999"], all["max"], all["count"])
+	return all
+}
+
+// batchLatencies prints the percentiles of the batch latencies of all
+// workers, optionally writes their full histogram to the HDR latencies file,
+// and returns the percentiles merged and per worker
+func (l *CommonBenchmarkRunner) batchLatencies() map[string]interface{} {
+	if l.latencies == nil {
+		return nil
+	}
+	merged := mergeLatencies(l.latencies)
+	perWorker := make([]map[string]interface{}, len(l.latencies))
+	for i, h := range l.latencies {
+		perWorker[i] = latencyQuantiles(h)
+	}
+	if merged.TotalCount() == 0 {
+		return nil
+	}
+
+	all := printLatencies(merged)
+
+	if len(l.HDRLatencies) > 0 {
+		printFn("Saving High Dynamic Range (HDR) Histogram of batch latencies to %s\n", l.HDRLatencies)
+		var b bytes.Buffer
+		if _, err := merged.PercentilesPrint(&b, 10, latencyScale); err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(l.HDRLatencies, b.Bytes(), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	return map[string]interface{}{
+		"batchLatencies":          all,
+		"batchLatenciesPerWorker": perWorker,
+	}
+}
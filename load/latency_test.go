@@ -0,0 +1,73 @@
+package load
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+func TestBatchLatencies(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	hdrFile, err := ioutil.TempFile("", "hdr_latencies_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdrFile.Close()
+	defer os.Remove(hdrFile.Name())
+
+	br := &CommonBenchmarkRunner{}
+	br.HDRLatencies = hdrFile.Name()
+	br.latencies = []*hdrhistogram.Histogram{newLatencyHistogram(), newLatencyHistogram()}
+	for i := 1; i <= 100; i++ {
+		br.recordLatency(uint(i%2), time.Duration(i)*time.Millisecond)
+	}
+
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	results := br.batchLatencies()
+
+	want := "batch latency: p50 50.02ms, p95 95.04ms, p99 99.01ms, p999 100.03ms, max 100.03ms (100 batches)\n" +
+		"Saving High Dynamic Range (HDR) Histogram of batch latencies to " + hdrFile.Name() + "\n"
+	if got := b.String(); got != want {
+		t.Errorf("incorrect output\ngot %s\nwant %s", got, want)
+	}
+	all := results["batchLatencies"].(map[string]interface{})
+	if got := all["count"]; got != int64(100) {
+		t.Errorf("incorrect count: got %v want %d", got, 100)
+	}
+	perWorker := results["batchLatenciesPerWorker"].([]map[string]interface{})
+	if len(perWorker) != 2 {
+		t.Fatalf("incorrect number of workers: got %d want %d", len(perWorker), 2)
+	}
+	if got := perWorker[1]["max"]; got != 99.007 {
+		t.Errorf("incorrect max of worker 1: got %v want %v", got, 99.007)
+	}
+
+	dump, err := ioutil.ReadFile(hdrFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dump), "#[Max     =      100.031, Total count    =          100]") {
+		t.Errorf("HDR latencies file has no summary:\n%s", dump)
+	}
+}
+
+func TestBatchLatenciesNone(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	if got := br.batchLatencies(); got != nil {
+		t.Errorf("expected no results without latencies, got %v", got)
+	}
+	br.latencies = []*hdrhistogram.Histogram{newLatencyHistogram()}
+	if got := br.batchLatencies(); got != nil {
+		t.Errorf("expected no results without batches, got %v", got)
+	}
+}
@@ -0,0 +1,113 @@
+package load
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/timescale/tsbs/internal/metrics"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// liveMetrics are the metrics of the load served with --metrics-listen
+type liveMetrics struct {
+	reg      *prometheus.Registry
+	batches  prometheus.Counter
+	errors   *prometheus.CounterVec
+	inFlight prometheus.Gauge
+	latency  prometheus.Histogram
+}
+
+// newLiveMetrics returns the metrics of the load, with the totals read from
+// the counters of the runner
+func newLiveMetrics(l *CommonBenchmarkRunner) *liveMetrics {
+	m := &liveMetrics{
+		reg: prometheus.NewRegistry(),
+		batches: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tsbs_load_batches_total",
+			Help: "Batches processed by the workers, including failed ones.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tsbs_load_errors_total",
+			Help: "Batches that could not be written in whole or in part, by error class.",
+		}, []string{"class"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tsbs_load_batches_in_flight",
+			Help: "Batches being written by the workers.",
+		}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tsbs_load_batch_duration_seconds",
+			Help:    "Time taken to write a batch.",
+			Buckets: metrics.LatencyBuckets,
+		}),
+	}
+	m.reg.MustRegister(m.batches, m.errors, m.inFlight, m.latency,
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "tsbs_load_metrics_total",
+			Help: "Metrics written.",
+		}, func() float64 { return float64(atomic.LoadUint64(&l.metricCnt)) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "tsbs_load_rows_total",
+			Help: "Rows written.",
+		}, func() float64 { return float64(atomic.LoadUint64(&l.rowCnt)) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "tsbs_load_failed_metrics_total",
+			Help: "Metrics that could not be written.",
+		}, func() float64 { return float64(l.failures.failedMetrics()) }),
+	)
+	return m
+}
+
+// serveMetrics starts serving the metrics of the load if --metrics-listen is
+// set
+func (l *CommonBenchmarkRunner) serveMetrics() {
+	if l.MetricsListen == "" {
+		return
+	}
+	l.liveMetrics = newLiveMetrics(l)
+	addr, err := metrics.Listen(l.MetricsListen, l.liveMetrics.reg)
+	if err != nil {
+		fatal("could not serve metrics on %s: %v", l.MetricsListen, err)
+		return
+	}
+	printFn("serving metrics on http://%s%s\n", addr, metrics.Path)
+}
+
+// watchChannel exports the number of batches waiting in a channel to the
+// workers
+func (m *liveMetrics) watchChannel(channel int, depth func() int) {
+	if m == nil {
+		return
+	}
+	m.reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "tsbs_load_channel_depth",
+		Help:        "Batches waiting to be written, per channel to the workers.",
+		ConstLabels: prometheus.Labels{"channel": strconv.Itoa(channel)},
+	}, func() float64 { return float64(depth()) }))
+}
+
+// batchStarted counts a batch being written
+func (m *liveMetrics) batchStarted() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Inc()
+}
+
+// batchDone counts a batch that was written, or failed to be, in took
+func (m *liveMetrics) batchDone(took time.Duration, res targets.BatchResult) {
+	if m == nil {
+		return
+	}
+	m.inFlight.Dec()
+	m.batches.Inc()
+	m.latency.Observe(took.Seconds())
+	if res.Err != nil {
+		class := res.ErrorClass
+		if class == "" {
+			class = targets.ErrorClassOther
+		}
+		m.errors.WithLabelValues(string(class)).Inc()
+	}
+}
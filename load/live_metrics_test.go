@@ -0,0 +1,69 @@
+package load
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestLiveMetrics(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	br.failures = newFailures()
+	br.liveMetrics = newLiveMetrics(br)
+	channels := br.createChannels(2, 2)
+	channels[1].sendToWorker(nil)
+
+	failed := targets.BatchResult{}
+	failed.Fail(10, 1, errors.New("timeout"), targets.ErrorClassTimeout)
+	proc := &testResultProcessor{results: []targets.BatchResult{
+		{Metrics: 20, Rows: 2},
+		failed,
+	}}
+	br.processBatch(proc, nil, 0)
+	br.processBatch(proc, nil, 0)
+
+	want := `
+# HELP tsbs_load_batches_in_flight Batches being written by the workers.
+# TYPE tsbs_load_batches_in_flight gauge
+tsbs_load_batches_in_flight 0
+# HELP tsbs_load_batches_total Batches processed by the workers, including failed ones.
+# TYPE tsbs_load_batches_total counter
+tsbs_load_batches_total 2
+# HELP tsbs_load_channel_depth Batches waiting to be written, per channel to the workers.
+# TYPE tsbs_load_channel_depth gauge
+tsbs_load_channel_depth{channel="0"} 0
+tsbs_load_channel_depth{channel="1"} 1
+# HELP tsbs_load_errors_total Batches that could not be written in whole or in part, by error class.
+# TYPE tsbs_load_errors_total counter
+tsbs_load_errors_total{class="timeout"} 1
+# HELP tsbs_load_failed_metrics_total Metrics that could not be written.
+# TYPE tsbs_load_failed_metrics_total counter
+tsbs_load_failed_metrics_total 10
+# HELP tsbs_load_metrics_total Metrics written.
+# TYPE tsbs_load_metrics_total counter
+tsbs_load_metrics_total 20
+# HELP tsbs_load_rows_total Rows written.
+# TYPE tsbs_load_rows_total counter
+tsbs_load_rows_total 2
+`
+	err := testutil.GatherAndCompare(br.liveMetrics.reg, strings.NewReader(want),
+		"tsbs_load_batches_in_flight", "tsbs_load_batches_total", "tsbs_load_channel_depth", "tsbs_load_errors_total",
+		"tsbs_load_failed_metrics_total", "tsbs_load_metrics_total", "tsbs_load_rows_total")
+	if err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(br.liveMetrics.latency); got != 1 {
+		t.Errorf("incorrect number of latency histograms: got %d want 1", got)
+	}
+}
+
+func TestLiveMetricsNil(t *testing.T) {
+	var m *liveMetrics
+	m.watchChannel(0, func() int { return 0 })
+	m.batchStarted()
+	m.batchDone(time.Second, targets.BatchResult{})
+}
@@ -2,7 +2,6 @@ package load
 
 import (
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/timescale/tsbs/pkg/targets"
@@ -28,7 +27,7 @@ func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 		go l.work(b, wg, channels[i%numChannels], i)
 	}
 	// Start scan process - actual data read process
-	scanWithoutFlowControl(b.GetDataSource(), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit)
+	scanWithoutFlowControl(l.dataSource(b), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit)
 	for _, c := range channels {
 		close(c)
 	}
@@ -41,7 +40,9 @@ func (l *noFlowBenchmarkRunner) createChannels(numChannels, capacity uint) []cha
 	// Result - channels to be created
 	channels := make([]chan targets.Batch, numChannels)
 	for i := uint(0); i < numChannels; i++ {
-		channels[i] = make(chan targets.Batch, capacity)
+		c := make(chan targets.Batch, capacity)
+		l.liveMetrics.watchChannel(int(i), func() int { return len(c) })
+		channels[i] = c
 	}
 	return channels
 }
@@ -53,11 +54,19 @@ func (l *noFlowBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	proc.Init(int(workerNum), l.DoLoad, l.HashWorkers)
 
 	// Process batches coming from the incoming queue (c)
-	for batch := range c {
+	for {
+		l.waitForPhase(workerNum)
+		waitedAt := time.Now()
+		batch, ok := <-c
+		if !ok {
+			break
+		}
 		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
-		atomic.AddUint64(&l.metricCnt, metricCnt)
-		atomic.AddUint64(&l.rowCnt, rowCnt)
+		metricCnt, rowCnt := l.processBatch(proc, batch, workerNum)
+		took := time.Since(startedWorkAt)
+		l.recordLatency(workerNum, took)
+		l.recordWork(workerNum, metricCnt, rowCnt, startedWorkAt.Sub(waitedAt), took)
+		l.limitRate(startedWorkAt, metricCnt, rowCnt)
 		l.timeToSleep(workerNum, startedWorkAt)
 	}
 
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"log"
 	"math/rand"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,7 +14,10 @@ import (
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/datalayers"
 
+	"github.com/HdrHistogram/hdrhistogram-go"
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/interrupt"
+	"github.com/timescale/tsbs/internal/report"
 	"github.com/timescale/tsbs/load/insertstrategy"
 )
 
@@ -46,9 +50,30 @@ type BenchmarkRunnerConfig struct {
 	ChannelCapacity uint          `yaml:"channel-capacity" mapstructure:"channel-capacity" json:"channel-capacity"`
 	InsertIntervals string        `yaml:"insert-intervals" mapstructure:"insert-intervals" json:"insert-intervals"`
 	ResultsFile     string        `yaml:"results-file" mapstructure:"results-file" json:"results-file"`
+	Verify          bool          `yaml:"verify" mapstructure:"verify" json:"verify"`
+	IngestRate      uint64        `yaml:"ingest-rate" mapstructure:"ingest-rate" json:"ingest-rate"`
+	IngestRateUnit  string        `yaml:"ingest-rate-unit" mapstructure:"ingest-rate-unit" json:"ingest-rate-unit"`
+	LoadProfile     string        `yaml:"load-profile" mapstructure:"load-profile" json:"load-profile"`
+	HDRLatencies    string        `yaml:"hdr-latencies" mapstructure:"hdr-latencies" json:"hdr-latencies"`
+	ErrorBudget     float64       `yaml:"error-budget" mapstructure:"error-budget" json:"error-budget"`
+	MetricsListen   string        `yaml:"metrics-listen" mapstructure:"metrics-listen" json:"metrics-listen"`
+	ReportFile      string        `yaml:"report-file" mapstructure:"report-file" json:"report-file"`
+	ReportFormat    string        `yaml:"report-format" mapstructure:"report-format" json:"report-format"`
+	Duration        time.Duration `yaml:"duration" mapstructure:"duration" json:"duration"`
+	Loop            bool          `yaml:"loop" mapstructure:"loop" json:"loop"`
+
+	CheckpointFile     string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file" json:"checkpoint-file"`
+	CheckpointInterval time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval" json:"checkpoint-interval"`
+	ResumeFrom         string        `yaml:"resume-from" mapstructure:"resume-from" json:"resume-from"`
+
+	CoordinatorListen string `yaml:"coordinator-listen" mapstructure:"coordinator-listen" json:"coordinator-listen"`
+	Agents            uint   `yaml:"agents" mapstructure:"agents" json:"agents"`
+	Coordinator       string `yaml:"coordinator" mapstructure:"coordinator" json:"coordinator"`
+
 	// deprecated, should not be used in other places other than tsbs_load_xx commands
-	FileName string `yaml:"file" mapstructure:"file" json:"file"`
-	Seed     int64  `yaml:"seed" mapstructure:"seed" json:"seed"`
+	FileName  string `yaml:"file" mapstructure:"file" json:"file"`
+	Seed      int64  `yaml:"seed" mapstructure:"seed" json:"seed"`
+	ReadAhead int    `yaml:"read-ahead" mapstructure:"read-ahead" json:"read-ahead"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
@@ -57,20 +82,41 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Uint("batch-size", defaultBatchSize, "Number of items to batch together in a single insert")
 	fs.Uint("workers", 1, "Number of parallel clients inserting")
 	fs.Uint64("limit", 0, "Number of items to insert (0 = all of them).")
+	fs.Duration("duration", 0, "Stop reading data after this duration, e.g. 30m (0 = no time limit)")
+	fs.Bool("loop", false, "Read the data again each time it runs out, shifting its timestamps past the ones loaded, until stopped by duration or limit")
 	fs.Bool("do-load", true, "Whether to write data. Set this flag to false to check input read speed.")
 	fs.Bool("do-create-db", true, "Whether to create the database. Disable on all but one client if running on a multi client setup.")
 	fs.Bool("do-abort-on-exist", false, "Whether to abort if a database with the given name already exists.")
 	fs.Duration("reporting-period", 10*time.Second, "Period to report write stats")
+	fs.String("report-file", "", "Also write the periodic write stats to this file")
+	fs.String("report-format", report.FormatCSV, "Format of the report-file: csv or jsonl")
 	fs.String("file", "", "File name to read data from")
+	fs.Int("read-ahead", 0, "Number of files after the one being loaded to decompress in parallel, when file is a directory or glob pattern")
 	fs.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	fs.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
 	fs.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.Bool("verify", false, "Whether to verify after the load that the database holds all rows read, failing the run on mismatches")
+	fs.Uint64("ingest-rate", 0, "Limit the rows (or metrics, see ingest-rate-unit) inserted per second by all workers, 0 = no limit")
+	fs.String("ingest-rate-unit", insertstrategy.RateUnitRows, "Unit of the ingest-rate: rows or metrics")
+	fs.String("hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of batch write latencies to this file.")
+	fs.Float64("error-budget", 0, "Fraction of the metrics that may fail to be written before the load stops, e.g. 0.01 (0 = stop at the first failed batch)")
+	fs.String("metrics-listen", "", "Serve live metrics of the load for Prometheus at /metrics on this address, e.g. :9099")
+	fs.String("checkpoint-file", "", "Periodically save the progress of the load to this file, to continue a failed load from it with resume-from")
+	fs.Duration("checkpoint-interval", defaultCheckpointInterval, "Time between the checkpoints saved to checkpoint-file")
+	fs.String("resume-from", "", "Continue the load into the existing database after the data loaded up to this checkpoint file")
+	fs.String("load-profile", "", "YAML file with the phases (duration and rate and/or workers) the load follows, stopping after the last one")
 }
 
 type BenchmarkRunner interface {
 	DatabaseName() string
 	RunBenchmark(b targets.Benchmark)
+	// Progress returns the number of metrics and rows loaded so far; it is
+	// safe to call while the benchmark runs
+	Progress() (metrics, rows uint64)
+	// Ready returns a channel that is closed once the database is created and
+	// the data is about to be loaded
+	Ready() <-chan struct{}
 }
 
 // CommonBenchmarkRunner is responsible for initializing and storing common
@@ -81,11 +127,52 @@ type CommonBenchmarkRunner struct {
 	rowCnt         uint64
 	initialRand    *rand.Rand
 	sleepRegulator insertstrategy.SleepRegulator
+	// rateRegulator limits the ingest rate of all workers, if set
+	rateRegulator *insertstrategy.RateRegulator
+	// phases makes the load follow a profile, if set, with phaseMarks
+	// recording the start of each phase
+	phases     *insertstrategy.PhaseRegulator
+	phaseMarks *phaseMarks
+	// latencies are the batch latencies of each worker
+	latencies []*hdrhistogram.Histogram
+	failures  *failures
+	// stop stops reading data early, e.g. at the deadline of --duration
+	stop     *stopSignal
+	deadline *time.Timer
+	// loop reads the data again for --loop, if set
+	loop *loopingDataSource
+	// checkpoints are saved while scanning with --checkpoint-file, and
+	// resumed is the checkpoint of --resume-from
+	checkpoints *checkpointer
+	resumed     *checkpoint
+	// interrupt stops reading data on SIGINT or SIGTERM
+	interrupt *interrupt.Handler
+	dbCreator targets.DBCreator
+	// liveMetrics are served with --metrics-listen, if set
+	liveMetrics *liveMetrics
+	// reportWriter writes the periodic report to the report file, if set
+	reportWriter *report.Writer
+	// reportStop stops the periodic report, which closes reportDone once it
+	// wrote its last row
+	reportStop chan struct{}
+	reportDone chan struct{}
+	// loadStats tracks the data read, if the load is verified
+	loadStats *targets.DataStats
+	// agent loads a shard of the data for a coordinator, if set
+	agent *Agent
+	// partial is set if the results cover only part of the load, e.g. when
+	// agents of a coordinator failed
+	partial bool
+	// workerLoads are the rows, batches, busy and idle time of each worker
+	workerLoads []workerStats
+	// ready is closed once the database is created
+	ready chan struct{}
 }
 
 func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 	loader := CommonBenchmarkRunner{}
 	loader.BenchmarkRunnerConfig = c
+	loader.ready = make(chan struct{})
 	// If the configuration batch size is 0 use the default batch size.
 	if loader.BatchSize == 0 {
 		loader.BatchSize = defaultBatchSize
@@ -102,7 +189,49 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
 		}
 	}
-	if !c.NoFlowControl {
+	if c.ResumeFrom != "" {
+		loader.resumed, err = readCheckpoint(c.ResumeFrom)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+		if c.Limit > 0 {
+			if c.Limit <= loader.resumed.Items {
+				panic(fmt.Sprintf("could not initialize BenchmarkRunner: the checkpoint already covers the limit of %d items", c.Limit))
+			}
+			loader.Limit -= loader.resumed.Items
+		}
+		// the data loaded before is in the database
+		loader.DoCreateDB = false
+	}
+	if c.CoordinatorListen != "" && c.Agents == 0 {
+		panic("could not initialize BenchmarkRunner: agents must be set for coordinator-listen")
+	}
+	if (c.CoordinatorListen != "" || c.Coordinator != "") && c.Verify {
+		// each agent loads only a part of the data in the database
+		panic("could not initialize BenchmarkRunner: verify can't be used with a coordinator")
+	}
+	if c.CheckpointFile != "" && c.NoFlowControl {
+		// the checkpoints need the workers to acknowledge the batches
+		panic("could not initialize BenchmarkRunner: checkpoint-file can't be used with no-flow-control")
+	}
+	if c.LoadProfile != "" {
+		loader.phases, err = newPhaseRegulator(c)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+		loader.rateRegulator = loader.phases.RateRegulator()
+		loader.phaseMarks = &phaseMarks{}
+	} else if c.IngestRate > 0 {
+		unit := c.IngestRateUnit
+		if unit == "" {
+			unit = insertstrategy.RateUnitRows
+		}
+		loader.rateRegulator, err = insertstrategy.NewRateRegulator(c.IngestRate, unit)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+	}
+	if !c.NoFlowControl || c.CoordinatorListen != "" {
 		return &loader
 	}
 
@@ -117,20 +246,78 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 	return &noFlowBenchmarkRunner{loader}
 }
 
+func newPhaseRegulator(c BenchmarkRunnerConfig) (*insertstrategy.PhaseRegulator, error) {
+	if c.IngestRate > 0 {
+		return nil, fmt.Errorf("ingest-rate can't be combined with a load-profile, set the rates of its phases")
+	}
+	profile, err := insertstrategy.ReadProfile(c.LoadProfile)
+	if err != nil {
+		return nil, err
+	}
+	if profile.UsesWorkers() && c.HashWorkers {
+		// the data for the paused workers would not be loaded
+		return nil, fmt.Errorf("phases with a number of workers can't be used with hash-workers")
+	}
+	return insertstrategy.NewPhaseRegulator(profile, c.Workers)
+}
+
 // DatabaseName returns the value of the --db-name flag (name of the database to store data)
 func (l *CommonBenchmarkRunner) DatabaseName() string {
 	return l.DBName
 }
 
+// Progress returns the number of metrics and rows loaded so far
+func (l *CommonBenchmarkRunner) Progress() (metrics, rows uint64) {
+	return atomic.LoadUint64(&l.metricCnt), atomic.LoadUint64(&l.rowCnt)
+}
+
+// Ready returns a channel that is closed once the database is created, e.g.
+// for queries to run on it while the data is loaded
+func (l *CommonBenchmarkRunner) Ready() <-chan struct{} {
+	return l.ready
+}
+
+// markReady closes the channel returned by Ready
+func (l *CommonBenchmarkRunner) markReady() {
+	if l.ready != nil {
+		close(l.ready)
+	}
+}
+
 func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*sync.WaitGroup, *time.Time) {
+	l.interrupt = interrupt.Notify()
 	// Create required DB
-	if b.GetDBCreator() != nil {
-		cleanupFn := l.useDBCreator(b.GetDBCreator())
+	if dbc := b.GetDBCreator(); dbc != nil {
+		l.dbCreator = dbc
+		cleanupFn := l.useDBCreator(dbc)
 		defer cleanupFn()
 	}
-
+	if l.Verify && l.DoLoad {
+		if _, ok := l.dbCreator.(targets.Verifier); !ok {
+			panic("--verify is not supported by this target")
+		}
+		l.loadStats = targets.NewDataStats()
+	}
+	l.markReady()
+	// all agents of a coordinator start loading at the same time
+	l.agent.waitForStart()
+
+	l.failures = newFailures()
+	l.stop = newStopSignal()
+	l.startDeadline()
+	l.checkpoints = l.newCheckpointer()
+	l.serveMetrics()
+	l.latencies = make([]*hdrhistogram.Histogram, l.Workers)
+	for i := range l.latencies {
+		l.latencies[i] = newLatencyHistogram()
+	}
+	l.workerLoads = make([]workerStats, l.Workers)
+	if l.phases != nil {
+		l.phases.Start(l.markPhase)
+	}
 	if l.ReportingPeriod.Nanoseconds() > 0 {
-		go l.report(l.ReportingPeriod)
+		l.reportWriter = l.newReportWriter()
+		l.startReport(l.ReportingPeriod)
 	}
 	wg := &sync.WaitGroup{}
 	wg.Add(int(l.Workers))
@@ -139,24 +326,133 @@ func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*sync.WaitGroup, *t
 }
 
 func (l *CommonBenchmarkRunner) postRun(wg *sync.WaitGroup, start *time.Time) {
+	defer l.interrupt.Stop()
+	if l.phases != nil {
+		// all data is read, the workers paused by a phase must finish
+		l.phases.Stop()
+	}
 	// Wait for all workers to finish
 	wg.Wait()
 	end := time.Now()
+	l.stopDeadline()
+	l.stopReport()
+	if err := l.reportWriter.Close(); err != nil {
+		fatal("could not close report file: %v", err)
+	}
 	took := end.Sub(*start)
 	l.summary(took)
+	if l.interrupt.Interrupted() {
+		printFn("interrupted: the results cover the data loaded until then\n")
+	}
+	loopResults := l.loopSummary()
+	checkpointResults := l.checkpointSummary()
+	failureResults := l.failureSummary()
+	latencyResults := l.batchLatencies()
+	workerResults := l.workerSummary()
+	rateResults := l.ingestRate(took)
+	phaseResults := l.phaseSummary(end)
+	postLoadResults := l.postLoad()
+	// measured after the post load step, which may e.g. compress the data
+	storageResults := l.storage()
+	verifyResults := l.verify()
+	metricRate := float64(l.metricCnt) / took.Seconds()
+	rowRate := float64(l.rowCnt) / took.Seconds()
+	extraTotals := []map[string]interface{}{loopResults, checkpointResults, failureResults, latencyResults, workerResults, rateResults, phaseResults, postLoadResults, storageResults, verifyResults}
 	if l.BenchmarkRunnerConfig.ResultsFile != "" {
-		metricRate := float64(l.metricCnt) / took.Seconds()
-		rowRate := float64(l.rowCnt) / took.Seconds()
-		l.saveTestResult(took, *start, end, metricRate, rowRate)
+		l.saveTestResult(took, *start, end, metricRate, rowRate, extraTotals...)
+	}
+	l.reportToCoordinator(*start, end, testTotals(l.rowCnt, metricRate, rowRate, extraTotals...))
+	if l.errorBudgetExceeded() {
+		fatal("error budget exceeded: %d of %d metrics failed to be written", l.failures.failedMetrics(), l.metricCnt+l.failures.failedMetrics())
+	}
+	if verifyResults != nil && verifyResults["verifyError"] != nil {
+		fatal("verification failed: could not verify the load: %s", verifyResults["verifyError"])
+	}
+	if verifyResults != nil && !verifyResults["verified"].(bool) {
+		fatal("verification failed: the database does not hold the data read")
+	}
+}
+
+// postLoad runs the post load step of the DBCreator, if it has one, and
+// prints its results
+func (l *CommonBenchmarkRunner) postLoad() map[string]interface{} {
+	// the coordinator of an agent runs it once all agents are done
+	if !l.DoLoad || l.agent != nil {
+		return nil
+	}
+	dbcp, ok := l.dbCreator.(targets.DBCreatorPostLoad)
+	if !ok {
+		return nil
+	}
+	results, err := dbcp.PostLoad(l.DBName)
+	if err != nil {
+		log.Println("could not execute PostLoad:" + err.Error())
+		panic(err)
 	}
+
+	keys := make([]string, 0, len(results))
+	for k := range results {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		printFn("%s: %v\n", k, results[k])
+	}
+	return results
 }
 
-func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64) {
+// storage prints the size of the database on disk, and the bytes used per
+// metric and row, if the DBCreator is a StorageReporter
+func (l *CommonBenchmarkRunner) storage() map[string]interface{} {
+	if !l.DoLoad || l.agent != nil {
+		return nil
+	}
+	sr, ok := l.dbCreator.(targets.StorageReporter)
+	if !ok {
+		return nil
+	}
+	bytes, err := sr.StorageBytes(l.DBName)
+	if err != nil {
+		// the data is loaded already, so the run is still worth reporting
+		log.Println("could not get storage size: " + err.Error())
+		return nil
+	}
+
+	results := map[string]interface{}{"storageBytes": bytes}
+	if l.metricCnt == 0 {
+		printFn("stored %d bytes on disk\n", bytes)
+		return results
+	}
+	bytesPerMetric := float64(bytes) / float64(l.metricCnt)
+	results["bytesPerMetric"] = bytesPerMetric
+	if l.rowCnt > 0 {
+		bytesPerRow := float64(bytes) / float64(l.rowCnt)
+		results["bytesPerRow"] = bytesPerRow
+		printFn("stored %d bytes on disk (%0.2f bytes/metric, %0.2f bytes/row)\n", bytes, bytesPerMetric, bytesPerRow)
+	} else {
+		printFn("stored %d bytes on disk (%0.2f bytes/metric)\n", bytes, bytesPerMetric)
+	}
+	return results
+}
+
+// testTotals returns the totals of the test results, the mean rates and the
+// extra totals of each part of the summary
+func testTotals(rows uint64, metricRate, rowRate float64, extraTotals ...map[string]interface{}) map[string]interface{} {
 	totals := make(map[string]interface{})
 	totals["metricRate"] = metricRate
-	if l.rowCnt > 0 {
+	if rows > 0 {
 		totals["rowRate"] = rowRate
 	}
+	for _, extra := range extraTotals {
+		for k, v := range extra {
+			totals[k] = v
+		}
+	}
+	return totals
+}
+
+func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64, extraTotals ...map[string]interface{}) {
+	totals := testTotals(l.rowCnt, metricRate, rowRate, extraTotals...)
 
 	testResult := LoaderTestResult{
 		ResultFormatVersion: LoaderTestResultVersion,
@@ -164,6 +460,7 @@ func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Ti
 		StartTime:           start.Unix(),
 		EndTime:             end.Unix(),
 		DurationMillis:      took.Milliseconds(),
+		Partial:             l.partial || l.interrupt.Interrupted(),
 		Totals:              totals,
 	}
 
@@ -181,6 +478,10 @@ func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Ti
 
 // RunBenchmark takes in a Benchmark b and uses it to run the load benchmark
 func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
+	if l.CoordinatorListen != "" {
+		l.coordinate(b)
+		return
+	}
 	wg, start := l.preRun(b)
 	var numChannels, capacity uint
 	if l.HashWorkers {
@@ -199,7 +500,7 @@ func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 	}
 
 	// Start scan process - actual data read process
-	scanWithFlowControl(channels, l.BatchSize, l.Limit, b.GetDataSource(), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))))
+	scanWithFlowControl(channels, l.BatchSize, l.Limit, l.dataSource(b), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))), l.checkpoints)
 	// After scan process completed (no more data to come) - begin shutdown process
 
 	// Close all communication channels to/from workers
@@ -267,7 +568,9 @@ func (l *CommonBenchmarkRunner) createChannels(numChannels, capacity uint) []*du
 	var channels []*duplexChannel
 	// Create duplex communication channels
 	for i := uint(0); i < numChannels; i++ {
-		channels = append(channels, newDuplexChannel(int(capacity)))
+		dc := newDuplexChannel(int(capacity))
+		l.liveMetrics.watchChannel(int(i), func() int { return len(dc.toWorker) })
+		channels = append(channels, dc)
 	}
 
 	return channels
@@ -282,12 +585,20 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 
 	// Process batches coming from duplexChannel.toWorker queue
 	// and send ACKs into duplexChannel.toScanner queue
-	for batch := range c.toWorker {
+	for {
+		l.waitForPhase(workerNum)
+		waitedAt := time.Now()
+		batch, ok := <-c.toWorker
+		if !ok {
+			break
+		}
 		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
-		atomic.AddUint64(&l.metricCnt, metricCnt)
-		atomic.AddUint64(&l.rowCnt, rowCnt)
+		metricCnt, rowCnt := l.processBatch(proc, batch, workerNum)
+		took := time.Since(startedWorkAt)
+		l.recordLatency(workerNum, took)
+		l.recordWork(workerNum, metricCnt, rowCnt, startedWorkAt.Sub(waitedAt), took)
 		c.sendToScanner()
+		l.limitRate(startedWorkAt, metricCnt, rowCnt)
 		l.timeToSleep(workerNum, startedWorkAt)
 	}
 
@@ -300,6 +611,12 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	wg.Done()
 }
 
+func (l *CommonBenchmarkRunner) limitRate(startedWorkAt time.Time, metricCnt, rowCnt uint64) {
+	if l.rateRegulator != nil {
+		l.rateRegulator.Sleep(startedWorkAt, metricCnt, rowCnt)
+	}
+}
+
 func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.Time) {
 	if l.sleepRegulator != nil {
 		l.sleepRegulator.Sleep(int(workerNum), startedWorkAt)
@@ -317,15 +634,101 @@ func (l *CommonBenchmarkRunner) summary(took time.Duration) {
 	}
 }
 
-// report handles periodic reporting of loading stats
-func (l *CommonBenchmarkRunner) report(period time.Duration) {
+// ingestRate prints the achieved ingest rate next to the requested one, if
+// the rate is limited
+func (l *CommonBenchmarkRunner) ingestRate(took time.Duration) map[string]interface{} {
+	if l.rateRegulator == nil || l.phases != nil {
+		// the rates of a load profile are reported per phase
+		return nil
+	}
+	unit := l.rateRegulator.Unit()
+	count := l.rowCnt
+	if unit == insertstrategy.RateUnitMetrics {
+		count = l.metricCnt
+	}
+	achieved := float64(count) / took.Seconds()
+	printFn("achieved %0.2f %s/sec of requested %d %s/sec (%0.2f%%)\n", achieved, unit, l.IngestRate, unit, 100*achieved/float64(l.IngestRate))
+	return map[string]interface{}{
+		"requestedRate": l.IngestRate,
+		"achievedRate":  achieved,
+		"rateUnit":      unit,
+	}
+}
+
+// reportColumns are the columns of the report file
+var reportColumns = []string{"time", "metricRate", "metrics", "overallMetricRate", "rowRate", "rows", "overallRowRate", "failedMetrics"}
+
+// newReportWriter creates the report file, if set
+func (l *CommonBenchmarkRunner) newReportWriter() *report.Writer {
+	if l.ReportFile == "" {
+		return nil
+	}
+	format := l.ReportFormat
+	if format == "" {
+		format = report.FormatCSV
+	}
+	columns := reportColumns
+	if l.phases != nil {
+		columns = append(columns[:len(columns):len(columns)], "phase")
+	}
+	w, err := report.NewWriter(l.ReportFile, format, columns)
+	if err != nil {
+		fatal("could not create report file: %v", err)
+		return nil
+	}
+	return w
+}
+
+// startReport prints the report of the load every period, until stopReport
+// is called
+func (l *CommonBenchmarkRunner) startReport(period time.Duration) {
+	l.reportStop = make(chan struct{})
+	l.reportDone = make(chan struct{})
+	go func() {
+		defer close(l.reportDone)
+		l.report(period, l.reportStop)
+	}()
+}
+
+// stopReport stops the periodic report, once it reported the time since its
+// last row
+func (l *CommonBenchmarkRunner) stopReport() {
+	if l.reportStop == nil {
+		return
+	}
+	close(l.reportStop)
+	<-l.reportDone
+	l.reportStop = nil
+}
+
+// report handles periodic reporting of loading stats, also writing them to
+// the report file if set. Once stop is closed it reports the time since the
+// last row and returns.
+func (l *CommonBenchmarkRunner) report(period time.Duration, stop <-chan struct{}) {
 	start := time.Now()
 	prevTime := start
 	prevColCount := uint64(0)
 	prevRowCount := uint64(0)
 
-	printFn("time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s\n")
-	for now := range time.NewTicker(period).C {
+	header := "time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s,failed metric total"
+	if l.phases != nil {
+		header += ",phase"
+	}
+	printFn("%s\n", header)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		var now time.Time
+		stopped := false
+		select {
+		case now = <-ticker.C:
+		case <-stop:
+			now = time.Now()
+			stopped = true
+		}
+		if !now.After(prevTime) {
+			return
+		}
 		cCount := atomic.LoadUint64(&l.metricCnt)
 		rCount := atomic.LoadUint64(&l.rowCnt)
 
@@ -333,12 +736,28 @@ func (l *CommonBenchmarkRunner) report(period time.Duration) {
 		took := now.Sub(prevTime)
 		colrate := float64(cCount-prevColCount) / float64(took.Seconds())
 		overallColRate := float64(cCount) / float64(sinceStart.Seconds())
+		failed := l.failures.failedMetrics()
+		record := []interface{}{now.Unix(), colrate, cCount, overallColRate, nil, nil, nil, failed}
+		phase := ""
+		if l.phases != nil {
+			phase = "," + l.phaseName()
+			record = append(record, l.phaseName())
+		}
 		if rCount > 0 {
 			rowrate := float64(rCount-prevRowCount) / float64(took.Seconds())
 			overallRowRate := float64(rCount) / float64(sinceStart.Seconds())
-			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate)
+			record[4], record[5], record[6] = rowrate, rCount, overallRowRate
+			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f,%d%s\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate, failed, phase)
 		} else {
-			printFn("%d,%0.2f,%E,%0.2f,-,-,-\n", now.Unix(), colrate, float64(cCount), overallColRate)
+			printFn("%d,%0.2f,%E,%0.2f,-,-,-,%d%s\n", now.Unix(), colrate, float64(cCount), overallColRate, failed, phase)
+		}
+		if l.reportWriter != nil {
+			if err := l.reportWriter.Write(record...); err != nil {
+				fatal("could not write report file: %v", err)
+			}
+		}
+		if stopped {
+			return
 		}
 
 		prevColCount = cCount
@@ -2,11 +2,17 @@ package load
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"github.com/timescale/tsbs/internal/interrupt"
+	"github.com/timescale/tsbs/load/insertstrategy"
 	"github.com/timescale/tsbs/pkg/targets"
+	"io/ioutil"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -78,6 +84,27 @@ func (c *testCreatorClose) Close() {
 	c.closedCalled = true
 }
 
+type testCreatorPostLoad struct {
+	testCreator
+	results map[string]interface{}
+	err     error
+}
+
+func (c *testCreatorPostLoad) PostLoad(string) (map[string]interface{}, error) {
+	c.postCalled = true
+	return c.results, c.err
+}
+
+type testCreatorStorage struct {
+	testCreator
+	bytes int64
+	err   error
+}
+
+func (c *testCreatorStorage) StorageBytes(string) (int64, error) {
+	return c.bytes, c.err
+}
+
 type testBenchmark struct {
 	processors []*testProcessor
 	offset     int64
@@ -431,10 +458,235 @@ func TestSummary(t *testing.T) {
 	}
 }
 
+func TestIngestRate(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	rows, err := insertstrategy.NewRateRegulator(20, insertstrategy.RateUnitRows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := insertstrategy.NewRateRegulator(200, insertstrategy.RateUnitMetrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		desc      string
+		regulator *insertstrategy.RateRegulator
+		rate      uint64
+		want      string
+		wantRate  float64
+	}{
+		{
+			desc: "no rate limit",
+		},
+		{
+			desc:      "rows per second",
+			regulator: rows,
+			rate:      20,
+			want:      "achieved 10.00 rows/sec of requested 20 rows/sec (50.00%)\n",
+			wantRate:  10,
+		},
+		{
+			desc:      "metrics per second",
+			regulator: metrics,
+			rate:      200,
+			want:      "achieved 100.00 metrics/sec of requested 200 metrics/sec (50.00%)\n",
+			wantRate:  100,
+		},
+	}
+
+	for _, c := range cases {
+		br := &CommonBenchmarkRunner{rateRegulator: c.regulator}
+		br.IngestRate = c.rate
+		br.metricCnt = 100
+		br.rowCnt = 10
+		var b bytes.Buffer
+		printFn = func(s string, args ...interface{}) (n int, err error) {
+			return fmt.Fprintf(&b, s, args...)
+		}
+		results := br.ingestRate(time.Second)
+		if got := b.String(); got != c.want {
+			t.Errorf("%s: incorrect output\ngot %s\nwant %s", c.desc, got, c.want)
+		}
+		if c.regulator == nil {
+			if results != nil {
+				t.Errorf("%s: unexpected results: %v", c.desc, results)
+			}
+			continue
+		}
+		if got := results["achievedRate"]; got != c.wantRate {
+			t.Errorf("%s: incorrect achievedRate: got %v want %v", c.desc, got, c.wantRate)
+		}
+		if got := results["rateUnit"]; got != c.regulator.Unit() {
+			t.Errorf("%s: incorrect rateUnit: got %v want %v", c.desc, got, c.regulator.Unit())
+		}
+	}
+}
+
+func TestPostLoad(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	cases := []struct {
+		desc        string
+		doLoad      bool
+		dbc         targets.DBCreator
+		want        map[string]interface{}
+		wantOutput  string
+		shouldPanic bool
+	}{
+		{
+			desc:   "no creator",
+			doLoad: true,
+		},
+		{
+			desc:   "creator without post load",
+			doLoad: true,
+			dbc:    &testCreator{},
+		},
+		{
+			desc:   "doLoad is false",
+			doLoad: false,
+			dbc:    &testCreatorPostLoad{results: map[string]interface{}{"b": 1}},
+		},
+		{
+			desc:       "results are printed sorted",
+			doLoad:     true,
+			dbc:        &testCreatorPostLoad{results: map[string]interface{}{"b": 1, "a": "x"}},
+			want:       map[string]interface{}{"b": 1, "a": "x"},
+			wantOutput: "a: x\nb: 1\n",
+		},
+		{
+			desc:        "post load errs, should panic",
+			doLoad:      true,
+			dbc:         &testCreatorPostLoad{err: fmt.Errorf("post load error")},
+			shouldPanic: true,
+		},
+	}
+
+	for _, c := range cases {
+		var b bytes.Buffer
+		printFn = func(s string, args ...interface{}) (n int, err error) {
+			return fmt.Fprintf(&b, s, args...)
+		}
+		r := &CommonBenchmarkRunner{
+			BenchmarkRunnerConfig: BenchmarkRunnerConfig{DoLoad: c.doLoad},
+			dbCreator:             c.dbc,
+		}
+		if c.shouldPanic {
+			func() {
+				defer func() {
+					if re := recover(); re == nil {
+						t.Errorf("%s: did not panic when should", c.desc)
+					}
+				}()
+				r.postLoad()
+			}()
+			continue
+		}
+
+		got := r.postLoad()
+		if len(got) != len(c.want) {
+			t.Errorf("%s: incorrect results: got %v want %v", c.desc, got, c.want)
+		}
+		for k, v := range c.want {
+			if got[k] != v {
+				t.Errorf("%s: incorrect result for %s: got %v want %v", c.desc, k, got[k], v)
+			}
+		}
+		if b.String() != c.wantOutput {
+			t.Errorf("%s: incorrect output: got %q want %q", c.desc, b.String(), c.wantOutput)
+		}
+	}
+}
+
+func TestStorage(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	cases := []struct {
+		desc       string
+		doLoad     bool
+		dbc        targets.DBCreator
+		metrics    uint64
+		rows       uint64
+		want       map[string]interface{}
+		wantOutput string
+	}{
+		{
+			desc:   "creator without storage reporting",
+			doLoad: true,
+			dbc:    &testCreator{},
+		},
+		{
+			desc:    "doLoad is false",
+			doLoad:  false,
+			dbc:     &testCreatorStorage{bytes: 1000},
+			metrics: 100,
+		},
+		{
+			desc:       "nothing loaded",
+			doLoad:     true,
+			dbc:        &testCreatorStorage{bytes: 1000},
+			want:       map[string]interface{}{"storageBytes": int64(1000)},
+			wantOutput: "stored 1000 bytes on disk\n",
+		},
+		{
+			desc:       "metrics only",
+			doLoad:     true,
+			dbc:        &testCreatorStorage{bytes: 1000},
+			metrics:    400,
+			want:       map[string]interface{}{"storageBytes": int64(1000), "bytesPerMetric": 2.5},
+			wantOutput: "stored 1000 bytes on disk (2.50 bytes/metric)\n",
+		},
+		{
+			desc:       "metrics and rows",
+			doLoad:     true,
+			dbc:        &testCreatorStorage{bytes: 1000},
+			metrics:    400,
+			rows:       40,
+			want:       map[string]interface{}{"storageBytes": int64(1000), "bytesPerMetric": 2.5, "bytesPerRow": 25.0},
+			wantOutput: "stored 1000 bytes on disk (2.50 bytes/metric, 25.00 bytes/row)\n",
+		},
+		{
+			desc:    "storage errs, nothing reported",
+			doLoad:  true,
+			dbc:     &testCreatorStorage{err: fmt.Errorf("storage error")},
+			metrics: 100,
+		},
+	}
+
+	for _, c := range cases {
+		var b bytes.Buffer
+		printFn = func(s string, args ...interface{}) (n int, err error) {
+			return fmt.Fprintf(&b, s, args...)
+		}
+		r := &CommonBenchmarkRunner{
+			BenchmarkRunnerConfig: BenchmarkRunnerConfig{DoLoad: c.doLoad},
+			dbCreator:             c.dbc,
+			metricCnt:             c.metrics,
+			rowCnt:                c.rows,
+		}
+
+		got := r.storage()
+		if len(got) != len(c.want) {
+			t.Errorf("%s: incorrect results: got %v want %v", c.desc, got, c.want)
+		}
+		for k, v := range c.want {
+			if got[k] != v {
+				t.Errorf("%s: incorrect result for %s: got %v want %v", c.desc, k, got[k], v)
+			}
+		}
+		if b.String() != c.wantOutput {
+			t.Errorf("%s: incorrect output: got %q want %q", c.desc, b.String(), c.wantOutput)
+		}
+	}
+}
+
 func TestReport(t *testing.T) {
 	var b bytes.Buffer
 	counter := int64(0)
 	var m sync.Mutex
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
 	printFn = func(s string, args ...interface{}) (n int, err error) {
 		atomic.AddInt64(&counter, 1)
 		m.Lock()
@@ -443,7 +695,8 @@ func TestReport(t *testing.T) {
 	}
 	br := &CommonBenchmarkRunner{}
 	duration := 200 * time.Millisecond
-	go br.report(duration)
+	br.startReport(duration)
+	defer br.stopReport()
 
 	time.Sleep(25 * time.Millisecond)
 	if got := atomic.LoadInt64(&counter); got != 1 {
@@ -462,8 +715,8 @@ func TestReport(t *testing.T) {
 	m.Lock()
 	end := strings.TrimSpace(string(b.Bytes()))
 	m.Unlock()
-	if end[len(end)-1:len(end)] != "-" {
-		t.Errorf("TestReport: non-row report does not end in -")
+	if !strings.HasSuffix(end, ",-,0") {
+		t.Errorf("TestReport: non-row report does not end in -,0")
 	}
 
 	// update row count so line is different
@@ -475,7 +728,112 @@ func TestReport(t *testing.T) {
 	m.Lock()
 	end = strings.TrimSpace(string(b.Bytes()))
 	m.Unlock()
-	if end[len(end)-1:len(end)] == "-" {
-		t.Errorf("TestReport: row report ends in -")
+	if strings.HasSuffix(end, ",-,0") {
+		t.Errorf("TestReport: row report ends in -,0")
+	}
+}
+
+func TestReportFile(t *testing.T) {
+	reportFile, err := ioutil.TempFile("", "report_*.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportFile.Close()
+	defer os.Remove(reportFile.Name())
+
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return 0, nil
+	}
+	br := &CommonBenchmarkRunner{}
+	br.ReportFile = reportFile.Name()
+	br.ReportFormat = "jsonl"
+	br.reportWriter = br.newReportWriter()
+	duration := 100 * time.Millisecond
+	atomic.StoreUint64(&br.metricCnt, 10)
+	br.startReport(duration)
+
+	time.Sleep(duration + duration/2)
+	atomic.StoreUint64(&br.rowCnt, 2)
+	time.Sleep(duration)
+	atomic.StoreUint64(&br.rowCnt, 5)
+	// the last row covers the time since the previous one
+	br.stopReport()
+	if err := br.reportWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(reportFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("incorrect number of records: got %d want 3\n%s", len(lines), content)
+	}
+	var records []map[string]interface{}
+	for _, line := range lines {
+		record := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %s: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if got := records[0]["metrics"]; got != float64(10) {
+		t.Errorf("incorrect metrics: got %v want 10", got)
+	}
+	if got := records[0]["rows"]; got != nil {
+		t.Errorf("incorrect rows without rows: got %v want null", got)
+	}
+	if got := records[1]["rows"]; got != float64(2) {
+		t.Errorf("incorrect rows: got %v want 2", got)
+	}
+	if got := records[1]["failedMetrics"]; got != float64(0) {
+		t.Errorf("incorrect failed metrics: got %v want 0", got)
+	}
+	if got := records[2]["rows"]; got != float64(5) {
+		t.Errorf("incorrect rows of the last row: got %v want 5", got)
+	}
+}
+
+func TestSaveTestResultPartial(t *testing.T) {
+	resultsFile, err := ioutil.TempFile("", "results_*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultsFile.Close()
+	defer os.Remove(resultsFile.Name())
+
+	br := &CommonBenchmarkRunner{}
+	br.ResultsFile = resultsFile.Name()
+	br.interrupt = interrupt.Notify()
+	defer br.interrupt.Stop()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-br.interrupt.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("interrupt not caught")
+	}
+	// reading data stops once interrupted
+	ds := &stoppingDataSource{DataSource: &testDataSource{}, done: br.interrupt.Done()}
+	if p := ds.NextItem(); p.Data != nil {
+		t.Errorf("expected no data after the interrupt, got %v", p.Data)
+	}
+
+	now := time.Now()
+	br.saveTestResult(time.Second, now.Add(-time.Second), now, 1, 1)
+	content, err := ioutil.ReadFile(resultsFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result LoaderTestResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Partial {
+		t.Errorf("results of an interrupted load not marked as partial:\n%s", content)
 	}
 }
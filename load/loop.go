@@ -0,0 +1,63 @@
+package load
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// loopingDataSource is a DataSource that rewinds to read its data again each
+// time it runs out of it, counting the passes completed
+type loopingDataSource struct {
+	targets.RewindableDataSource
+	passes uint64
+}
+
+func (d *loopingDataSource) NextItem() data.LoadedPoint {
+	for {
+		item := d.RewindableDataSource.NextItem()
+		if item.Data != nil {
+			return item
+		}
+		d.passes++
+		if !d.Rewind() {
+			return item
+		}
+	}
+}
+
+// loopDataSource makes ds loop over its data for --loop
+func (l *CommonBenchmarkRunner) loopDataSource(ds targets.DataSource) targets.DataSource {
+	rds, ok := ds.(targets.RewindableDataSource)
+	if !ok {
+		panic("--loop is not supported by this target")
+	}
+	l.loop = &loopingDataSource{RewindableDataSource: rds}
+	return l.loop
+}
+
+// startDeadline stops reading data once the load ran for --duration
+func (l *CommonBenchmarkRunner) startDeadline() {
+	if l.Duration <= 0 {
+		return
+	}
+	l.deadline = time.AfterFunc(l.Duration, l.stop.now)
+}
+
+// stopDeadline stops the --duration timer of a load that ended before it
+func (l *CommonBenchmarkRunner) stopDeadline() {
+	if l.deadline != nil {
+		l.deadline.Stop()
+	}
+}
+
+// loopSummary prints how many passes over the data were completed, if the
+// load loops over it
+func (l *CommonBenchmarkRunner) loopSummary() map[string]interface{} {
+	if l.loop == nil {
+		return nil
+	}
+	printFn("completed %d passes over the data\n", l.loop.passes)
+	return map[string]interface{}{"passes": l.loop.passes}
+}
@@ -0,0 +1,159 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRewindableDataSource reads its bytes again up to rewinds times
+type testRewindableDataSource struct {
+	testDataSource
+	data    []byte
+	rewinds int
+}
+
+func (d *testRewindableDataSource) Rewind() bool {
+	if d.rewinds == 0 {
+		return false
+	}
+	d.rewinds--
+	d.br = bufio.NewReader(bytes.NewReader(d.data))
+	return true
+}
+
+func TestLoopingDataSource(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	data := []byte("abc")
+	rds := &testRewindableDataSource{data: data, rewinds: 2}
+	rds.br = bufio.NewReader(bytes.NewReader(data))
+
+	br := &CommonBenchmarkRunner{}
+	ds := br.loopDataSource(rds)
+	var read []byte
+	for p := ds.NextItem(); p.Data != nil; p = ds.NextItem() {
+		read = append(read, p.Data.(byte))
+	}
+	if got, want := string(read), "abcabcabc"; got != want {
+		t.Errorf("incorrect data read: got %s want %s", got, want)
+	}
+
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	results := br.loopSummary()
+	if got, want := b.String(), "completed 3 passes over the data\n"; got != want {
+		t.Errorf("incorrect summary: got %q want %q", got, want)
+	}
+	if got := results["passes"]; got != uint64(3) {
+		t.Errorf("incorrect passes: got %v want %d", got, 3)
+	}
+}
+
+func TestLoopNotSupported(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a DataSource that can't be rewound")
+		}
+	}()
+	br := &CommonBenchmarkRunner{}
+	br.loopDataSource(&testDataSource{})
+}
+
+func TestDeadline(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	br.stop = newStopSignal()
+	br.startDeadline()
+	if br.deadline != nil {
+		t.Errorf("expected no deadline without a duration")
+	}
+
+	br.Duration = time.Millisecond
+	br.startDeadline()
+	select {
+	case <-br.stop.done():
+	case <-time.After(time.Second):
+		t.Errorf("reading data did not stop at the deadline")
+	}
+	br.stopDeadline()
+}
+
+func TestRewindableReader(t *testing.T) {
+	f, err := ioutil.TempFile("", "rewindable_reader_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("line\n")
+	f.Close()
+
+	r := GetRewindableReader(f.Name(), 0)
+	for pass := 0; pass < 2; pass++ {
+		if pass > 0 && !r.Rewind() {
+			t.Fatalf("could not rewind for pass %d", pass)
+		}
+		line, err := r.ReadString('\n')
+		if err != nil || line != "line\n" {
+			t.Errorf("pass %d: incorrect line %q (error %v)", pass, line, err)
+		}
+	}
+
+	if GetRewindableReader("", 0).Rewind() {
+		t.Errorf("expected STDIN not to be rewound")
+	}
+}
+
+func TestRewindableReaderFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rewindable_reader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte("two\n"))
+	w.Close()
+	if err := ioutil.WriteFile(filepath.Join(dir, "1"), []byte("one\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "2.gz"), gz.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := GetRewindableReader(dir, 1)
+	for pass := 0; pass < 2; pass++ {
+		if pass > 0 && !r.Rewind() {
+			t.Fatalf("could not rewind for pass %d", pass)
+		}
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			if err == io.EOF {
+				if !r.NextFile() {
+					break
+				}
+				continue
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			lines = append(lines, line)
+		}
+		if got, want := strings.Join(lines, ""), "one\ntwo\n"; got != want {
+			t.Errorf("pass %d: incorrect data: got %q want %q", pass, got, want)
+		}
+	}
+
+	if got, err := ioutil.ReadAll(GetBufferedReader(dir)); err != nil || string(got) != "one\ntwo\n" {
+		t.Errorf("incorrect data of the buffered reader: got %q (error %v)", got, err)
+	}
+}
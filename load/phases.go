@@ -0,0 +1,90 @@
+package load
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// phaseMark is the progress of the load when a phase started
+type phaseMark struct {
+	phase   int
+	at      time.Time
+	metrics uint64
+	rows    uint64
+}
+
+// phaseMarks are the phaseMarks of the phases started so far
+type phaseMarks struct {
+	lock  sync.Mutex
+	marks []phaseMark
+}
+
+// markPhase records the start of a phase of the load profile
+func (l *CommonBenchmarkRunner) markPhase(phase int) {
+	l.phaseMarks.lock.Lock()
+	defer l.phaseMarks.lock.Unlock()
+	l.phaseMarks.marks = append(l.phaseMarks.marks, phaseMark{
+		phase:   phase,
+		at:      time.Now(),
+		metrics: atomic.LoadUint64(&l.metricCnt),
+		rows:    atomic.LoadUint64(&l.rowCnt),
+	})
+}
+
+// waitForPhase blocks while the worker is not used in the current phase
+func (l *CommonBenchmarkRunner) waitForPhase(workerNum uint) {
+	if l.phases != nil {
+		l.phases.WaitActive(int(workerNum))
+	}
+}
+
+// phaseName returns the name of the current phase, if the load follows a
+// profile
+func (l *CommonBenchmarkRunner) phaseName() string {
+	if l.phases == nil {
+		return ""
+	}
+	return l.phases.PhaseName()
+}
+
+// phaseSummary prints what was loaded in each phase of the load profile
+// that was started, up to the end of the load
+func (l *CommonBenchmarkRunner) phaseSummary(end time.Time) map[string]interface{} {
+	if l.phases == nil {
+		return nil
+	}
+	l.phaseMarks.lock.Lock()
+	marks := append([]phaseMark{}, l.phaseMarks.marks...)
+	l.phaseMarks.lock.Unlock()
+	marks = append(marks, phaseMark{at: end, metrics: l.metricCnt, rows: l.rowCnt})
+
+	phases := l.phases.Phases()
+	var results []map[string]interface{}
+	for i := 0; i < len(marks)-1; i++ {
+		if marks[i].phase >= len(phases) {
+			// the profile is over, the rest is the end of the load
+			break
+		}
+		name := phases[marks[i].phase].Name
+		took := marks[i+1].at.Sub(marks[i].at)
+		metrics := marks[i+1].metrics - marks[i].metrics
+		rows := marks[i+1].rows - marks[i].rows
+		metricRate := float64(metrics) / took.Seconds()
+		rowRate := float64(rows) / took.Seconds()
+		if rows > 0 {
+			printFn("phase %s: loaded %d metrics and %d rows in %0.3fsec (mean rate %0.2f metrics/sec, %0.2f rows/sec)\n", name, metrics, rows, took.Seconds(), metricRate, rowRate)
+		} else {
+			printFn("phase %s: loaded %d metrics in %0.3fsec (mean rate %0.2f metrics/sec)\n", name, metrics, took.Seconds(), metricRate)
+		}
+		results = append(results, map[string]interface{}{
+			"phase":          name,
+			"durationMillis": took.Milliseconds(),
+			"metrics":        metrics,
+			"rows":           rows,
+			"metricRate":     metricRate,
+			"rowRate":        rowRate,
+		})
+	}
+	return map[string]interface{}{"phases": results}
+}
@@ -0,0 +1,72 @@
+package load
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/load/insertstrategy"
+)
+
+func TestPhaseSummary(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	profile := &insertstrategy.Profile{
+		Unit: insertstrategy.RateUnitRows,
+		Phases: []insertstrategy.Phase{
+			{Name: "ramp-up", Duration: time.Second, Rate: 10, RampTo: 100},
+			{Name: "steady", Duration: time.Second, Rate: 100},
+			{Name: "spike", Duration: time.Second, Rate: 1000},
+		},
+	}
+	phases, err := insertstrategy.NewPhaseRegulator(profile, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(0, 0)
+	br := &CommonBenchmarkRunner{phases: phases, phaseMarks: &phaseMarks{}}
+	br.phaseMarks.marks = []phaseMark{
+		{phase: 0, at: start},
+		{phase: 1, at: start.Add(time.Second), metrics: 500, rows: 50},
+		{phase: 2, at: start.Add(2 * time.Second), metrics: 1500, rows: 150},
+		{phase: 3, at: start.Add(3 * time.Second), metrics: 11500, rows: 1150},
+	}
+	br.metricCnt = 12000
+	br.rowCnt = 1200
+
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	results := br.phaseSummary(start.Add(4 * time.Second))
+
+	want := []string{
+		"phase ramp-up: loaded 500 metrics and 50 rows in 1.000sec (mean rate 500.00 metrics/sec, 50.00 rows/sec)",
+		"phase steady: loaded 1000 metrics and 100 rows in 1.000sec (mean rate 1000.00 metrics/sec, 100.00 rows/sec)",
+		"phase spike: loaded 10000 metrics and 1000 rows in 1.000sec (mean rate 10000.00 metrics/sec, 1000.00 rows/sec)",
+	}
+	if got := strings.TrimSpace(b.String()); got != strings.Join(want, "\n") {
+		t.Errorf("incorrect phase summary\ngot %s\nwant %s", got, strings.Join(want, "\n"))
+	}
+	phaseResults := results["phases"].([]map[string]interface{})
+	if len(phaseResults) != 3 {
+		t.Fatalf("expected 3 phase results, got %d", len(phaseResults))
+	}
+	if got := phaseResults[2]["phase"]; got != "spike" {
+		t.Errorf("expected phase spike, got %v", got)
+	}
+	if got := phaseResults[2]["rows"]; got != uint64(1000) {
+		t.Errorf("expected 1000 rows, got %v", got)
+	}
+
+	// a load that runs out of data ends its phase
+	b.Reset()
+	br.phaseMarks.marks = br.phaseMarks.marks[:1]
+	br.phaseSummary(start.Add(500 * time.Millisecond))
+	want = []string{"phase ramp-up: loaded 12000 metrics and 1200 rows in 0.500sec (mean rate 24000.00 metrics/sec, 2400.00 rows/sec)"}
+	if got := strings.TrimSpace(b.String()); got != want[0] {
+		t.Errorf("incorrect phase summary\ngot %s\nwant %s", got, want[0])
+	}
+}
@@ -43,9 +43,11 @@ func sendOrQueueBatch(ch *duplexChannel, count *int, batch targets.Batch, unsent
 // which are then dispatched to workers (duplexChannel chosen by PointIndexer).
 // Scan does flow control to make sure workers are not left idle for too long
 // and also that the scanning process does not starve them of CPU.
+// If cp is set, checkpoints are saved when they are due, once the workers
+// acknowledged every batch sent to them.
 func scanWithFlowControl(
 	channels []*duplexChannel, batchSize uint, limit uint64,
-	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer,
+	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer, cp *checkpointer,
 ) uint64 {
 	var itemsRead uint64
 	numChannels := len(channels)
@@ -110,9 +112,18 @@ func scanWithFlowControl(
 		// Only receive an 'ok' when it's from a channel, default does not return 'ok'
 		chosen, _, ok := reflect.Select(cases[:caseLimit])
 		if ok {
+			cp.acked()
 			unsentBatches[chosen] = ackAndMaybeSend(channels[chosen], &ocnt, unsentBatches[chosen])
 		}
 
+		if cp.due() {
+			// Send out the batches being filled, and wait for all of them
+			// to be written before saving the checkpoint
+			flushBatches(channels, &ocnt, fillingBatches, unsentBatches, factory)
+			waitForAcks(cases, channels, &ocnt, unsentBatches, cp)
+			cp.save(itemsRead)
+		}
+
 		// Prepare new batch - decode new item and append it to batch
 		item := ds.NextItem()
 		// fmt.Printf("Read the %v-th item\n", itemsRead)
@@ -141,27 +152,40 @@ func scanWithFlowControl(
 
 	// Finished reading input - no more items to come
 	// Make sure last batch goes out - it may be smaller than batchSize requested - there is not more items
+	flushBatches(channels, &ocnt, fillingBatches, unsentBatches, nil)
+
+	// Wait until all the outstanding batches get acknowledged,
+	// so we don't prematurely close the acknowledge channels
+	waitForAcks(cases, channels, &ocnt, unsentBatches, cp)
+	cp.save(itemsRead)
+
+	return itemsRead
+}
+
+// flushBatches sends or queues the batches being filled that have items in
+// them, replacing them with new ones from factory if set
+func flushBatches(channels []*duplexChannel, count *int, fillingBatches []targets.Batch, unsentBatches [][]targets.Batch, factory targets.BatchFactory) {
 	for idx, b := range fillingBatches {
 		// Do not enqueue empty batches (with 0 items)
 		if b.Len() > 0 {
-			unsentBatches[idx] = sendOrQueueBatch(channels[idx], &ocnt, fillingBatches[idx], unsentBatches[idx])
+			unsentBatches[idx] = sendOrQueueBatch(channels[idx], count, b, unsentBatches[idx])
+			if factory != nil {
+				fillingBatches[idx] = factory.New()
+			}
 		}
 	}
+}
 
-	// Wait until all the outstanding batches get acknowledged,
-	// so we don't prematurely close the acknowledge channels
-	for {
-		if ocnt == 0 {
-			// No outstanding batches any more
-			break
-		}
-
+// waitForAcks sends the queued batches to the workers until all outstanding
+// batches are acknowledged. cases are the receive cases of the acknowledge
+// channels, followed by the default case.
+func waitForAcks(cases []reflect.SelectCase, channels []*duplexChannel, count *int, unsentBatches [][]targets.Batch, cp *checkpointer) {
+	for *count > 0 {
 		// Try to send batches to workers
 		chosen, _, ok := reflect.Select(cases[:len(cases)-1])
 		if ok {
-			unsentBatches[chosen] = ackAndMaybeSend(channels[chosen], &ocnt, unsentBatches[chosen])
+			cp.acked()
+			unsentBatches[chosen] = ackAndMaybeSend(channels[chosen], count, unsentBatches[chosen])
 		}
 	}
-
-	return itemsRead
 }
@@ -0,0 +1,53 @@
+package load
+
+import (
+	"sync"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// stopSignal stops reading data before the DataSource is used up, e.g. when
+// the error budget is exceeded or the --duration is over. The data read so far
+// is still loaded.
+type stopSignal struct {
+	once sync.Once
+	c    chan struct{}
+}
+
+func newStopSignal() *stopSignal {
+	return &stopSignal{c: make(chan struct{})}
+}
+
+// now stops reading data; it can be called more than once
+func (s *stopSignal) now() {
+	if s == nil {
+		return
+	}
+	s.once.Do(func() { close(s.c) })
+}
+
+// done returns a channel that is closed when reading data stops early
+func (s *stopSignal) done() <-chan struct{} {
+	if s == nil {
+		// never closed
+		return nil
+	}
+	return s.c
+}
+
+// stoppingDataSource is a DataSource that runs out of data when done is
+// closed
+type stoppingDataSource struct {
+	targets.DataSource
+	done <-chan struct{}
+}
+
+func (d *stoppingDataSource) NextItem() data.LoadedPoint {
+	select {
+	case <-d.done:
+		return data.LoadedPoint{}
+	default:
+		return d.DataSource.NextItem()
+	}
+}
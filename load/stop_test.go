@@ -0,0 +1,31 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestStoppingDataSource(t *testing.T) {
+	stop := newStopSignal()
+	ds := &stoppingDataSource{
+		DataSource: &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte("abc")))},
+		done:       stop.done(),
+	}
+	if p := ds.NextItem(); p.Data == nil {
+		t.Errorf("expected a point before stopping")
+	}
+	stop.now()
+	stop.now()
+	if p := ds.NextItem(); p.Data != nil {
+		t.Errorf("expected no point after stopping, got %v", p.Data)
+	}
+}
+
+func TestStopSignalNil(t *testing.T) {
+	var stop *stopSignal
+	stop.now()
+	if stop.done() != nil {
+		t.Errorf("expected a nil stop signal to never be done")
+	}
+}
@@ -0,0 +1,111 @@
+package load
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// trackingDataSource is a DataSource that adds every point read from it to
+// DataStats, so the load can be verified afterwards
+type trackingDataSource struct {
+	targets.DataSource
+	verifier targets.Verifier
+	stats    *targets.DataStats
+}
+
+func (d *trackingDataSource) NextItem() data.LoadedPoint {
+	item := d.DataSource.NextItem()
+	if item.Data != nil {
+		d.verifier.TrackPoint(item, d.stats)
+	}
+	return item
+}
+
+// dataSource returns the DataSource of the benchmark, looping over its data
+// with --loop, reading only the shard of an agent of a coordinator, past the
+// data loaded before with --resume-from, tracking the points read from it if
+// the load is verified, and ending when the load is stopped early or
+// interrupted, or the load profile, if any, is over
+func (l *CommonBenchmarkRunner) dataSource(b targets.Benchmark) targets.DataSource {
+	ds := b.GetDataSource()
+	if l.Loop {
+		ds = l.loopDataSource(ds)
+	}
+	ds = l.agent.shardDataSource(ds)
+	l.skipResumed(ds)
+	ds = &stoppingDataSource{DataSource: ds, done: l.stop.done()}
+	ds = &stoppingDataSource{DataSource: ds, done: l.interrupt.Done()}
+	if l.phases != nil {
+		ds = &stoppingDataSource{DataSource: ds, done: l.phases.Done()}
+	}
+	if l.loadStats == nil {
+		return ds
+	}
+	return &trackingDataSource{DataSource: ds, verifier: l.dbCreator.(targets.Verifier), stats: l.loadStats}
+}
+
+// verify compares the data in the database with the data read from the
+// DataSource, prints every mismatch and returns whether they matched. If the
+// database could not be queried, the load is not verified and the error is
+// returned with the results, so they are still saved before the run fails.
+func (l *CommonBenchmarkRunner) verify() map[string]interface{} {
+	if l.loadStats == nil {
+		return nil
+	}
+	measurements := l.loadStats.Measurements()
+	got, err := l.dbCreator.(targets.Verifier).Verify(l.DBName, measurements)
+	if err != nil {
+		printFn("could not verify the load: %v\n", err)
+		return map[string]interface{}{
+			"verified":    false,
+			"verifyError": err.Error(),
+		}
+	}
+
+	mismatches := 0
+	for _, m := range measurements {
+		for _, diff := range compareMeasurementStats(got[m], l.loadStats.Get(m)) {
+			printFn("verify %s: %s\n", m, diff)
+			mismatches++
+		}
+	}
+	if mismatches == 0 {
+		printFn("verified %d measurements: row counts, time ranges and tag sets match\n", len(measurements))
+	} else {
+		printFn("verification failed with %d mismatches\n", mismatches)
+	}
+	return map[string]interface{}{
+		"verified":         mismatches == 0,
+		"verifyMismatches": mismatches,
+	}
+}
+
+// compareMeasurementStats returns a description of every difference between
+// the stats of the database and those of the DataSource
+func compareMeasurementStats(got, want targets.MeasurementStats) []string {
+	var diffs []string
+	if got.Rows != want.Rows {
+		diffs = append(diffs, fmt.Sprintf("got %d rows, want %d", got.Rows, want.Rows))
+	}
+	if got.TagSets != want.TagSets {
+		diffs = append(diffs, fmt.Sprintf("got %d tag sets, want %d", got.TagSets, want.TagSets))
+	}
+	// time ranges are only meaningful with rows on both sides
+	if got.Rows == 0 || want.Rows == 0 {
+		return diffs
+	}
+	if !got.MinTime.Equal(want.MinTime) {
+		diffs = append(diffs, fmt.Sprintf("got min time %s, want %s", formatVerifyTime(got.MinTime), formatVerifyTime(want.MinTime)))
+	}
+	if !got.MaxTime.Equal(want.MaxTime) {
+		diffs = append(diffs, fmt.Sprintf("got max time %s, want %s", formatVerifyTime(got.MaxTime), formatVerifyTime(want.MaxTime)))
+	}
+	return diffs
+}
+
+func formatVerifyTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
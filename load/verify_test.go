@@ -0,0 +1,165 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// testCreatorVerifier tracks every byte read as a row of measurement 'cpu',
+// timestamped with the byte value and tagged with its parity
+type testCreatorVerifier struct {
+	testCreator
+	stored map[string]targets.MeasurementStats
+	err    error
+}
+
+func (c *testCreatorVerifier) TrackPoint(p data.LoadedPoint, stats *targets.DataStats) {
+	b := p.Data.(byte)
+	stats.Add("cpu", time.Unix(int64(b), 0), fmt.Sprintf("%d", b%2))
+}
+
+func (c *testCreatorVerifier) Verify(string, []string) (map[string]targets.MeasurementStats, error) {
+	return c.stored, c.err
+}
+
+func TestTrackingDataSource(t *testing.T) {
+	stats := targets.NewDataStats()
+	ds := &trackingDataSource{
+		DataSource: &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{5, 3, 9, 4}))},
+		verifier:   &testCreatorVerifier{},
+		stats:      stats,
+	}
+	for ds.NextItem().Data != nil {
+	}
+
+	if got := stats.Measurements(); len(got) != 1 || got[0] != "cpu" {
+		t.Fatalf("incorrect measurements: got %v", got)
+	}
+	want := targets.MeasurementStats{Rows: 4, MinTime: time.Unix(3, 0), MaxTime: time.Unix(9, 0), TagSets: 2}
+	if got := stats.Get("cpu"); got != want {
+		t.Errorf("incorrect stats: got %+v want %+v", got, want)
+	}
+	if got := stats.Get("mem"); got != (targets.MeasurementStats{}) {
+		t.Errorf("expected empty stats for unknown measurement, got %+v", got)
+	}
+}
+
+func TestCompareMeasurementStats(t *testing.T) {
+	want := targets.MeasurementStats{Rows: 10, MinTime: time.Unix(0, 0), MaxTime: time.Unix(100, 0), TagSets: 2}
+	cases := []struct {
+		desc string
+		got  targets.MeasurementStats
+		want []string
+	}{
+		{
+			desc: "equal",
+			got:  want,
+		},
+		{
+			desc: "equal in another time zone",
+			got:  targets.MeasurementStats{Rows: 10, MinTime: time.Unix(0, 0).UTC(), MaxTime: time.Unix(100, 0).UTC(), TagSets: 2},
+		},
+		{
+			desc: "dropped rows",
+			got:  targets.MeasurementStats{Rows: 9, MinTime: time.Unix(0, 0), MaxTime: time.Unix(90, 0), TagSets: 2},
+			want: []string{
+				"got 9 rows, want 10",
+				"got max time 1970-01-01T00:01:30Z, want 1970-01-01T00:01:40Z",
+			},
+		},
+		{
+			desc: "empty measurement",
+			got:  targets.MeasurementStats{},
+			want: []string{"got 0 rows, want 10", "got 0 tag sets, want 2"},
+		},
+	}
+	for _, c := range cases {
+		got := compareMeasurementStats(c.got, want)
+		if strings.Join(got, "|") != strings.Join(c.want, "|") {
+			t.Errorf("%s: incorrect diffs: got %q want %q", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	loaded := targets.NewDataStats()
+	loaded.Add("cpu", time.Unix(1, 0), "a")
+	loaded.Add("cpu", time.Unix(2, 0), "b")
+	loaded.Add("mem", time.Unix(1, 0), "a")
+	cpu := targets.MeasurementStats{Rows: 2, MinTime: time.Unix(1, 0), MaxTime: time.Unix(2, 0), TagSets: 2}
+	mem := targets.MeasurementStats{Rows: 1, MinTime: time.Unix(1, 0), MaxTime: time.Unix(1, 0), TagSets: 1}
+
+	cases := []struct {
+		desc           string
+		stored         map[string]targets.MeasurementStats
+		wantVerified   bool
+		wantMismatches int
+		wantOutput     string
+	}{
+		{
+			desc:         "all data stored",
+			stored:       map[string]targets.MeasurementStats{"cpu": cpu, "mem": mem},
+			wantVerified: true,
+			wantOutput:   "verified 2 measurements: row counts, time ranges and tag sets match\n",
+		},
+		{
+			desc:           "measurement missing",
+			stored:         map[string]targets.MeasurementStats{"cpu": cpu},
+			wantMismatches: 2,
+			wantOutput: "verify mem: got 0 rows, want 1\n" +
+				"verify mem: got 0 tag sets, want 1\n" +
+				"verification failed with 2 mismatches\n",
+		},
+	}
+	for _, c := range cases {
+		var b bytes.Buffer
+		printFn = func(s string, args ...interface{}) (n int, err error) {
+			return fmt.Fprintf(&b, s, args...)
+		}
+		r := &CommonBenchmarkRunner{
+			dbCreator: &testCreatorVerifier{stored: c.stored},
+			loadStats: loaded,
+		}
+		got := r.verify()
+		if got["verified"] != c.wantVerified || got["verifyMismatches"] != c.wantMismatches {
+			t.Errorf("%s: incorrect results: got %v", c.desc, got)
+		}
+		if b.String() != c.wantOutput {
+			t.Errorf("%s: incorrect output: got %q want %q", c.desc, b.String(), c.wantOutput)
+		}
+	}
+
+	r := &CommonBenchmarkRunner{}
+	if got := r.verify(); got != nil {
+		t.Errorf("expected no results without --verify, got %v", got)
+	}
+}
+
+func TestVerifyError(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	r := &CommonBenchmarkRunner{
+		dbCreator: &testCreatorVerifier{err: fmt.Errorf("verify error")},
+		loadStats: targets.NewDataStats(),
+	}
+	got := r.verify()
+	if got["verified"] != false || got["verifyError"] != "verify error" {
+		t.Errorf("incorrect results: got %v", got)
+	}
+	if want := "could not verify the load: verify error\n"; b.String() != want {
+		t.Errorf("incorrect output: got %q want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,129 @@
+package load
+
+import (
+	"sort"
+	"time"
+)
+
+// stragglerFraction is the fraction of the median load of the workers below
+// which a worker is reported as a straggler
+const stragglerFraction = 0.5
+
+// workerStats is the load of one worker. Each worker updates only its own,
+// so no locking is needed; they are read once all workers are done.
+type workerStats struct {
+	metrics uint64
+	rows    uint64
+	batches uint64
+	// busy is the time spent processing batches, idle the time spent
+	// waiting for the next one
+	busy time.Duration
+	idle time.Duration
+}
+
+// recordWork counts a batch processed by the worker, that it waited idle
+// for before processing it for busy
+func (l *CommonBenchmarkRunner) recordWork(workerNum uint, metricCnt, rowCnt uint64, idle, busy time.Duration) {
+	if l.workerLoads == nil {
+		return
+	}
+	s := &l.workerLoads[workerNum]
+	s.metrics += metricCnt
+	s.rows += rowCnt
+	s.batches++
+	s.idle += idle
+	s.busy += busy
+}
+
+// workerSummary prints the spread of the load among the workers and the
+// workers far below the median, and returns the load of each worker
+func (l *CommonBenchmarkRunner) workerSummary() map[string]interface{} {
+	if len(l.workerLoads) == 0 {
+		return nil
+	}
+	// the rows tell the load of the workers apart if the target counts them
+	unit := "metrics"
+	counts := make([]uint64, len(l.workerLoads))
+	for i, s := range l.workerLoads {
+		counts[i] = s.metrics
+	}
+	if l.rowCnt > 0 {
+		unit = "rows"
+		for i, s := range l.workerLoads {
+			counts[i] = s.rows
+		}
+	}
+
+	perWorker := make([]map[string]interface{}, len(l.workerLoads))
+	for i, s := range l.workerLoads {
+		perWorker[i] = map[string]interface{}{
+			"metrics":     s.metrics,
+			"rows":        s.rows,
+			"batches":     s.batches,
+			"busyMillis":  s.busy.Milliseconds(),
+			"idleMillis":  s.idle.Milliseconds(),
+			"busyPercent": busyPercent(s),
+		}
+	}
+	results := map[string]interface{}{"workers": perWorker}
+	if len(l.workerLoads) == 1 {
+		return results
+	}
+
+	sorted := append([]uint64(nil), counts...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := medianCount(sorted)
+	minBusy, maxBusy := 100.0, 0.0
+	for _, s := range l.workerLoads {
+		p := busyPercent(s)
+		if p < minBusy {
+			minBusy = p
+		}
+		if p > maxBusy {
+			maxBusy = p
+		}
+	}
+	printFn("%s per worker: min %d, median %0.1f, max %d; busy %0.1f%% to %0.1f%% of the time, idle waiting for batches otherwise\n",
+		unit, sorted[0], median, sorted[len(sorted)-1], minBusy, maxBusy)
+	results["workerSpread"] = map[string]interface{}{
+		"unit":       unit,
+		"min":        sorted[0],
+		"median":     median,
+		"max":        sorted[len(sorted)-1],
+		"minBusyPct": minBusy,
+		"maxBusyPct": maxBusy,
+	}
+
+	var stragglers []int
+	for i, s := range l.workerLoads {
+		if float64(counts[i]) >= stragglerFraction*median {
+			continue
+		}
+		printFn("worker %d is a straggler: loaded %d %s, %0.1f%% of the median, in %d batches (busy %0.3fsec, idle %0.3fsec)\n",
+			i, counts[i], unit, 100*float64(counts[i])/median, s.batches, s.busy.Seconds(), s.idle.Seconds())
+		stragglers = append(stragglers, i)
+	}
+	if len(stragglers) > 0 {
+		results["stragglers"] = stragglers
+	}
+	return results
+}
+
+// medianCount returns the median of the sorted counts
+func medianCount(sorted []uint64) float64 {
+	n := len(sorted)
+	if n%2 == 1 {
+		return float64(sorted[n/2])
+	}
+	return float64(sorted[n/2-1]+sorted[n/2]) / 2
+}
+
+// busyPercent returns the percentage of the time the worker was busy out of
+// the time it was busy or idle
+func busyPercent(s workerStats) float64 {
+	total := s.busy + s.idle
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(s.busy) / float64(total)
+}
@@ -0,0 +1,58 @@
+package load
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWorkerSummary(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	br := &CommonBenchmarkRunner{}
+	br.workerLoads = make([]workerStats, 3)
+	for i, rows := range []uint64{100, 90, 20} {
+		br.recordWork(uint(i), rows, rows, 3*time.Second, time.Second)
+		br.rowCnt += rows
+	}
+
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	results := br.workerSummary()
+
+	want := "rows per worker: min 20, median 90.0, max 100; busy 25.0% to 25.0% of the time, idle waiting for batches otherwise\n" +
+		"worker 2 is a straggler: loaded 20 rows, 22.2% of the median, in 1 batches (busy 1.000sec, idle 3.000sec)\n"
+	if got := b.String(); got != want {
+		t.Errorf("incorrect output\ngot %s\nwant %s", got, want)
+	}
+	stragglers := results["stragglers"].([]int)
+	if len(stragglers) != 1 || stragglers[0] != 2 {
+		t.Errorf("incorrect stragglers: got %v want %v", stragglers, []int{2})
+	}
+	perWorker := results["workers"].([]map[string]interface{})
+	if got := perWorker[1]["idleMillis"]; got != int64(3000) {
+		t.Errorf("incorrect idle time of worker 1: got %v want %d", got, 3000)
+	}
+	if got := perWorker[0]["batches"]; got != uint64(1) {
+		t.Errorf("incorrect batches of worker 0: got %v want %d", got, 1)
+	}
+}
+
+func TestMedianCount(t *testing.T) {
+	cases := []struct {
+		sorted []uint64
+		want   float64
+	}{
+		{[]uint64{1}, 1},
+		{[]uint64{1, 4}, 2.5},
+		{[]uint64{1, 2, 9}, 2},
+	}
+	for _, c := range cases {
+		if got := medianCount(c.sorted); got != c.want {
+			t.Errorf("incorrect median of %v: got %v want %v", c.sorted, got, c.want)
+		}
+	}
+}
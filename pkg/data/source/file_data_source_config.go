@@ -2,4 +2,7 @@ package source
 
 type FileDataSourceConfig struct {
 	Location string `yaml:"location"`
+	// ReadAhead is the number of files after the one being read decompressed
+	// in parallel, when Location is a directory or glob pattern
+	ReadAhead int `yaml:"read-ahead" mapstructure:"read-ahead"`
 }
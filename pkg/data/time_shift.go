@@ -0,0 +1,86 @@
+package data
+
+import "time"
+
+// TimeShift shifts the timestamps of a data set that is read more than once,
+// e.g. by a load with --loop. Each pass over the data is shifted past the time
+// span of the passes before it, so the timestamps keep increasing and none of
+// them are repeated.
+type TimeShift struct {
+	offset int64
+	passes int
+
+	// span of the first pass, in nanoseconds
+	seen     bool
+	min, max int64
+	last     int64
+	step     int64
+}
+
+// Shift returns the timestamp ns, in nanoseconds, shifted for the current
+// pass. The timestamps of the first pass are recorded to find its span.
+func (s *TimeShift) Shift(ns int64) int64 {
+	if s.passes > 0 {
+		return ns + s.offset
+	}
+	if !s.seen {
+		s.seen, s.min, s.max = true, ns, ns
+	} else {
+		if ns < s.min {
+			s.min = ns
+		}
+		if ns > s.max {
+			s.max = ns
+		}
+		step := ns - s.last
+		if step < 0 {
+			step = -step
+		}
+		if step > 0 && (s.step == 0 || step < s.step) {
+			s.step = step
+		}
+	}
+	s.last = ns
+	return ns
+}
+
+// ShiftTime is Shift for a time.Time
+func (s *TimeShift) ShiftTime(t time.Time) time.Time {
+	if s.passes == 0 {
+		s.Shift(t.UnixNano())
+		return t
+	}
+	return t.Add(time.Duration(s.offset))
+}
+
+// Offset returns how far the timestamps of the current pass are shifted
+func (s *TimeShift) Offset() time.Duration {
+	return time.Duration(s.offset)
+}
+
+// Span returns the time span of the first pass: from its first timestamp to a
+// step after its last one, the step being the smallest interval between its
+// timestamps
+func (s *TimeShift) Span() time.Duration {
+	if !s.seen {
+		return 0
+	}
+	step := s.step
+	if step == 0 {
+		// a single timestamp
+		step = 1
+	}
+	return time.Duration(s.max - s.min + step)
+}
+
+// Next moves on to the next pass. It returns false if there is nothing to
+// shift, i.e. the first pass had no timestamps.
+func (s *TimeShift) Next() bool {
+	span := s.Span()
+	if span == 0 {
+		return false
+	}
+	s.passes++
+	s.offset += int64(span)
+	return true
+}
@@ -0,0 +1,66 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeShift(t *testing.T) {
+	var s TimeShift
+	if s.Next() {
+		t.Errorf("expected no next pass without timestamps")
+	}
+
+	first := []int64{100, 110, 105, 120, 120}
+	for _, ns := range first {
+		if got := s.Shift(ns); got != ns {
+			t.Errorf("first pass shifted %d to %d", ns, got)
+		}
+	}
+	// 100 to 120, and the smallest step of 5 after it
+	if got, want := s.Span(), 25*time.Nanosecond; got != want {
+		t.Fatalf("incorrect span: got %v want %v", got, want)
+	}
+
+	for pass := int64(1); pass <= 2; pass++ {
+		if !s.Next() {
+			t.Fatalf("expected pass %d", pass)
+		}
+		for _, ns := range first {
+			if got, want := s.Shift(ns), ns+pass*25; got != want {
+				t.Errorf("pass %d: incorrect shift of %d: got %d want %d", pass, ns, got, want)
+			}
+		}
+		if got, want := s.Offset(), time.Duration(pass*25); got != want {
+			t.Errorf("pass %d: incorrect offset: got %v want %v", pass, got, want)
+		}
+	}
+	// the span is the one of the first pass
+	if got, want := s.Span(), 25*time.Nanosecond; got != want {
+		t.Errorf("incorrect span after the first pass: got %v want %v", got, want)
+	}
+}
+
+func TestTimeShiftTime(t *testing.T) {
+	var s TimeShift
+	s.ShiftTime(testNow)
+	s.ShiftTime(testNow.Add(10 * time.Second))
+	if !s.Next() {
+		t.Fatal("expected a next pass")
+	}
+	if got, want := s.ShiftTime(testNow), testNow.Add(20*time.Second); !got.Equal(want) {
+		t.Errorf("incorrect shifted time: got %v want %v", got, want)
+	}
+}
+
+func TestTimeShiftSingleTimestamp(t *testing.T) {
+	var s TimeShift
+	s.Shift(100)
+	s.Shift(100)
+	if !s.Next() {
+		t.Fatal("expected a next pass")
+	}
+	if got := s.Shift(100); got != 101 {
+		t.Errorf("incorrect shift of a single timestamp: got %d want %d", got, 101)
+	}
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// LoopingSimulator is a Simulator that keeps going after it is Finished, if
+// rewound. Each pass generates the time range of the simulation again, shifted
+// past the timestamps of the passes before it.
+type LoopingSimulator struct {
+	Simulator
+	newSimulator func() Simulator
+	shift        data.TimeShift
+}
+
+// NewLoopingSimulator returns a LoopingSimulator running the simulators made
+// by newSimulator, one per pass
+func NewLoopingSimulator(newSimulator func() Simulator) *LoopingSimulator {
+	return &LoopingSimulator{
+		Simulator:    newSimulator(),
+		newSimulator: newSimulator,
+	}
+}
+
+// Next advances p to the next point, shifting its timestamp for the current
+// pass
+func (s *LoopingSimulator) Next(p *data.Point) bool {
+	write := s.Simulator.Next(p)
+	if p.Timestamp() != nil {
+		// a new time, the simulator may share its own with the point
+		ts := s.shift.ShiftTime(*p.Timestamp())
+		p.SetTimestamp(&ts)
+	}
+	return write
+}
+
+// Rewind starts the next pass of the simulation. It returns false if the
+// first pass generated no points.
+func (s *LoopingSimulator) Rewind() bool {
+	if !s.shift.Next() {
+		return false
+	}
+	s.Simulator = s.newSimulator()
+	return true
+}
+
+// RewindSimulator starts the next pass of sim if it is a LoopingSimulator,
+// returning whether it did
+func RewindSimulator(sim Simulator) bool {
+	ls, ok := sim.(*LoopingSimulator)
+	return ok && ls.Rewind()
+}
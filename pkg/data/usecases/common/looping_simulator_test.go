@@ -0,0 +1,66 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// sliceSimulator generates a point for each of its timestamps
+type sliceSimulator struct {
+	timestamps []time.Time
+	next       int
+}
+
+func (s *sliceSimulator) Finished() bool { return s.next >= len(s.timestamps) }
+
+func (s *sliceSimulator) Next(p *data.Point) bool {
+	p.SetMeasurementName(dummyMeasurementName)
+	p.SetTimestamp(&s.timestamps[s.next])
+	s.next++
+	return true
+}
+
+func (s *sliceSimulator) Fields() map[string][]string    { return nil }
+func (s *sliceSimulator) TagKeys() []string              { return nil }
+func (s *sliceSimulator) TagTypes() []string             { return nil }
+func (s *sliceSimulator) Headers() *GeneratedDataHeaders { return nil }
+
+func TestLoopingSimulator(t *testing.T) {
+	newSimulator := func() Simulator {
+		return &sliceSimulator{timestamps: []time.Time{testTime, testTime.Add(time.Second), testTime.Add(2 * time.Second)}}
+	}
+	s := NewLoopingSimulator(newSimulator)
+	p := data.NewPoint()
+	var got []time.Time
+	for pass := 0; pass < 2; pass++ {
+		if pass > 0 && !RewindSimulator(s) {
+			t.Fatalf("could not rewind for pass %d", pass)
+		}
+		for !s.Finished() {
+			s.Next(p)
+			got = append(got, *p.Timestamp())
+			p.Reset()
+		}
+	}
+
+	if len(got) != 6 {
+		t.Fatalf("incorrect number of points: got %d want %d", len(got), 6)
+	}
+	for i, ts := range got {
+		if want := testTime.Add(time.Duration(i) * time.Second); !ts.Equal(want) {
+			t.Errorf("incorrect timestamp of point %d: got %v want %v", i, ts, want)
+		}
+	}
+}
+
+func TestLoopingSimulatorNoPoints(t *testing.T) {
+	s := NewLoopingSimulator(func() Simulator { return &sliceSimulator{} })
+	if RewindSimulator(s) {
+		t.Errorf("rewound a simulation without points")
+	}
+	if RewindSimulator(&sliceSimulator{}) {
+		t.Errorf("rewound a simulator that does not loop")
+	}
+}
@@ -79,6 +79,26 @@ type Simulator interface {
 	Headers() *GeneratedDataHeaders
 }
 
+// GroupedSimulator is a Simulator that can simulate only the generators
+// (e.g. hosts) of one of several interleaved groups, so that each generator
+// belongs to exactly one group.
+type GroupedSimulator interface {
+	Simulator
+	// KeepGroup drops the generators not in the group groupID, the generators
+	// with index i where i % numGroups == groupID. It must be called before
+	// the first call to Next.
+	KeepGroup(groupID, numGroups uint)
+}
+
+// GroupCount returns how many of the first n generators are in the group
+// groupID of numGroups interleaved groups.
+func GroupCount(n uint64, groupID, numGroups uint) uint64 {
+	if n <= uint64(groupID) {
+		return 0
+	}
+	return (n-uint64(groupID)-1)/uint64(numGroups) + 1
+}
+
 // BaseSimulator generates data similar to truck readings.
 type BaseSimulator struct {
 	madePoints uint64
@@ -137,6 +157,19 @@ func (s *BaseSimulator) Next(p *data.Point) bool {
 	return ret
 }
 
+// KeepGroup simulates only the generators of the group groupID, and the
+// share of the points they make.
+func (s *BaseSimulator) KeepGroup(groupID, numGroups uint) {
+	var generators []Generator
+	for i := groupID; i < uint(len(s.generators)); i += numGroups {
+		generators = append(generators, s.generators[i])
+	}
+	s.maxPoints = s.maxPoints * uint64(len(generators)) / uint64(len(s.generators))
+	s.initGenerators = GroupCount(s.initGenerators, groupID, numGroups)
+	s.epochGenerators = s.initGenerators
+	s.generators = generators
+}
+
 // Fields returns all the simulated measurements for the device.
 func (s *BaseSimulator) Fields() map[string][]string {
 	if len(s.generators) <= 0 {
@@ -249,3 +249,40 @@ func TestBaseSimulatorConfigNewSimulator(t *testing.T) {
 	}
 
 }
+
+func TestBaseSimulatorKeepGroup(t *testing.T) {
+	s := testBaseConf.NewSimulator(time.Second, 0).(*BaseSimulator)
+	maxPoints := s.maxPoints
+	s.KeepGroup(1, 3)
+	// generators 1, 4, ..., 97 of the 100
+	if got := len(s.generators); got != 33 {
+		t.Errorf("incorrect generators kept: got %d want %d", got, 33)
+	}
+	if got := s.initGenerators; got != 3 {
+		t.Errorf("incorrect initial generators kept: got %d want %d", got, 3)
+	}
+	if got, want := s.maxPoints, maxPoints*33/100; got != want {
+		t.Errorf("incorrect max points: got %d want %d", got, want)
+	}
+}
+
+func TestGroupCount(t *testing.T) {
+	cases := []struct {
+		n         uint64
+		groupID   uint
+		numGroups uint
+		want      uint64
+	}{
+		{n: 0, groupID: 0, numGroups: 3, want: 0},
+		{n: 1, groupID: 1, numGroups: 3, want: 0},
+		{n: 2, groupID: 1, numGroups: 3, want: 1},
+		{n: 10, groupID: 0, numGroups: 3, want: 4},
+		{n: 10, groupID: 2, numGroups: 3, want: 3},
+		{n: 10, groupID: 0, numGroups: 1, want: 10},
+	}
+	for _, c := range cases {
+		if got := GroupCount(c.n, c.groupID, c.numGroups); got != c.want {
+			t.Errorf("GroupCount(%d, %d, %d): got %d want %d", c.n, c.groupID, c.numGroups, got, c.want)
+		}
+	}
+}
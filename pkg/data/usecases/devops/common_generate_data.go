@@ -134,6 +134,19 @@ func (s *commonDevopsSimulator) populatePoint(p *data.Point, measureIdx int) boo
 	return ret
 }
 
+// KeepGroup simulates only the hosts of the group groupID, and the share of
+// the points they make
+func (s *commonDevopsSimulator) KeepGroup(groupID, numGroups uint) {
+	var hosts []Host
+	for i := groupID; i < uint(len(s.hosts)); i += numGroups {
+		hosts = append(hosts, s.hosts[i])
+	}
+	s.maxPoints = s.maxPoints * uint64(len(hosts)) / uint64(len(s.hosts))
+	s.initHosts = common.GroupCount(s.initHosts, groupID, numGroups)
+	s.epochHosts = s.initHosts
+	s.hosts = hosts
+}
+
 // TODO(rrk) - Can probably turn this logic into a separate interface and implement other
 // types of scale up, e.g., exponential
 //
@@ -2,6 +2,7 @@ package devops
 
 import (
 	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"testing"
 	"time"
 )
@@ -99,3 +100,32 @@ func TestDevopsSimulatorConfigNewSimulator(t *testing.T) {
 	}
 
 }
+
+func TestDevopsSimulatorKeepGroup(t *testing.T) {
+	conf := *testDevopsConf
+	conf.InitHostCount = testDevopsHostCount
+	hostPoints := make(map[string]int)
+	for group := uint(0); group < 3; group++ {
+		s := conf.NewSimulator(time.Second, 0).(*DevopsSimulator)
+		s.KeepGroup(group, 3)
+		if got, want := len(s.hosts), int(common.GroupCount(testDevopsHostCount, group, 3)); got != want {
+			t.Errorf("group %d: incorrect hosts: got %d want %d", group, got, want)
+		}
+		p := data.NewPoint()
+		for !s.Finished() {
+			if s.Next(p) {
+				hostPoints[p.GetTagValue(MachineTagKeys[0]).(string)]++
+			}
+			p.Reset()
+		}
+	}
+	// every host was simulated by one of the groups, for all its points
+	if len(hostPoints) != testDevopsHostCount {
+		t.Errorf("incorrect hosts simulated by all groups: got %d want %d", len(hostPoints), testDevopsHostCount)
+	}
+	for host, points := range hostPoints {
+		if points != 9*3 {
+			t.Errorf("incorrect points of %s: got %d want %d", host, points, 9*3)
+		}
+	}
+}
@@ -101,6 +101,14 @@ func (s *Simulator) Headers() *common.GeneratedDataHeaders {
 	}
 }
 
+// KeepGroup simulates only the trucks of the group groupID, if the base
+// simulator can keep to a group.
+func (s *Simulator) KeepGroup(groupID, numGroups uint) {
+	if gs, ok := s.base.(common.GroupedSimulator); ok {
+		gs.KeepGroup(groupID, numGroups)
+	}
+}
+
 // pendingOutOfOrderItems returns whether the simulator has pending
 // items (batches or separate entries) that need to be inserted.
 func (s *Simulator) pendingOutOfOrderItems() bool {
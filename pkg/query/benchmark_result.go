@@ -14,6 +14,8 @@ type LoaderTestResult struct {
 	StartTime      int64 `json:"StartTime`
 	EndTime        int64 `json:"EndTime"`
 	DurationMillis int64 `json:"DurationMillis"`
+	// Partial is set if the run was interrupted before it was done
+	Partial bool `json:"Partial,omitempty"`
 
 	// Totals
 	Totals map[string]interface{} `json:"Totals"`
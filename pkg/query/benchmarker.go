@@ -12,6 +12,9 @@ import (
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/datafile"
+	"github.com/timescale/tsbs/internal/interrupt"
+	"github.com/timescale/tsbs/internal/report"
 	"golang.org/x/time/rate"
 )
 
@@ -38,6 +41,13 @@ type BenchmarkRunnerConfig struct {
 	PrintInterval    uint64 `mapstructure:"print-interval"`
 	PrewarmQueries   bool   `mapstructure:"prewarm-queries"`
 	ResultsFile      string `mapstructure:"results-file"`
+	// LoadConfig is a tsbs_load config file of data to load while the queries run
+	LoadConfig      string        `mapstructure:"load-config"`
+	ReportingPeriod time.Duration `mapstructure:"reporting-period"`
+	MetricsListen   string        `mapstructure:"metrics-listen"`
+	ReportFile      string        `mapstructure:"report-file"`
+	ReportFormat    string        `mapstructure:"report-format"`
+	ReadAhead       int           `mapstructure:"read-ahead"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
@@ -53,8 +63,14 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Bool("prewarm-queries", false, "Run each query twice in a row so the warm query is guaranteed to be a cache hit")
 	fs.Bool("print-responses", false, "Pretty print response bodies for correctness checking (default false).")
 	fs.Int("debug", 0, "Whether to print debug messages.")
-	fs.String("file", "", "File name to read queries from")
+	fs.String("file", "", "File name to read queries from: a file, possibly compressed with gzip, zstd or lz4, or a directory or glob pattern of files read one after another")
+	fs.Int("read-ahead", 0, "Number of files after the one being read to decompress in parallel, when file is a directory or glob pattern")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.String("load-config", "", "Load the data described by this tsbs_load config file while running the queries, repeating them until the load is done")
+	fs.Duration("reporting-period", 10*time.Second, "Period to report query latency percentiles and ingest rate while loading data (0 to disable)")
+	fs.String("report-file", "", "Also write the timing stats printed every print-interval, and at the end, to this file")
+	fs.String("report-format", report.FormatCSV, "Format of the report-file: csv or jsonl")
+	fs.String("metrics-listen", "", "Serve live metrics of the run for Prometheus at /metrics on this address, e.g. :9099")
 }
 
 // BenchmarkRunner contains the common components for running a query benchmarking
@@ -62,9 +78,18 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 type BenchmarkRunner struct {
 	BenchmarkRunnerConfig
 	br      *bufio.Reader
+	files   *datafile.Files
 	sp      statProcessor
 	scanner *scanner
 	ch      chan Query
+	// workload runs in the background, if set, with latencies collecting
+	// the query latencies of each reporting period
+	workload  Workload
+	latencies *periodLatencies
+	// liveMetrics are served with --metrics-listen, if set
+	liveMetrics *liveMetrics
+	// interrupt stops reading queries on SIGINT or SIGTERM
+	interrupt *interrupt.Handler
 }
 
 // NewBenchmarkRunner creates a new instance of BenchmarkRunner which is
@@ -72,12 +97,15 @@ type BenchmarkRunner struct {
 func NewBenchmarkRunner(config BenchmarkRunnerConfig) *BenchmarkRunner {
 	runner := &BenchmarkRunner{BenchmarkRunnerConfig: config}
 	runner.scanner = newScanner(&runner.Limit)
+	runner.scanner.next = runner.nextFile
 	spArgs := &statProcessorArgs{
 		limit:            &runner.Limit,
 		printInterval:    runner.PrintInterval,
 		prewarmQueries:   runner.PrewarmQueries,
 		burnIn:           runner.BurnIn,
 		hdrLatenciesFile: runner.HDRLatenciesFile,
+		reportFile:       runner.ReportFile,
+		reportFormat:     runner.ReportFormat,
 	}
 
 	runner.sp = newStatProcessor(spArgs)
@@ -116,24 +144,46 @@ type Processor interface {
 	ProcessQuery(q Query, isWarm bool) ([]*Stat, error)
 }
 
-// GetBufferedReader returns the buffered Reader that should be used by the loader
+// GetBufferedReader returns the buffered Reader that should be used by the loader.
+// When the queries are read from several files, it reads the current one.
 func (b *BenchmarkRunner) GetBufferedReader() *bufio.Reader {
 	if b.br == nil {
 		if len(b.FileName) > 0 {
-			// Read from specified file
-			file, err := os.Open(b.FileName)
+			// Read from specified files
+			files, err := datafile.OpenFiles(b.FileName, b.ReadAhead)
 			if err != nil {
 				panic(fmt.Sprintf("cannot open file for read %s: %v", b.FileName, err))
 			}
-			b.br = bufio.NewReaderSize(file, defaultReadSize)
+			b.files = files
+			b.br = bufio.NewReaderSize(files, defaultReadSize)
 		} else {
 			// Read from STDIN
-			b.br = bufio.NewReaderSize(os.Stdin, defaultReadSize)
+			r, err := datafile.NewReader(os.Stdin, "")
+			if err != nil {
+				panic(fmt.Sprintf("cannot read STDIN: %v", err))
+			}
+			b.br = bufio.NewReaderSize(r, defaultReadSize)
 		}
 	}
 	return b.br
 }
 
+// nextFile moves the buffered Reader on to the next file of queries. It
+// returns false if there are no more files.
+func (b *BenchmarkRunner) nextFile() bool {
+	if b.files == nil {
+		return false
+	}
+	ok, err := b.files.Next()
+	if err != nil {
+		panic(fmt.Sprintf("cannot open file for read: %v", err))
+	}
+	if ok {
+		b.br.Reset(b.files)
+	}
+	return ok
+}
+
 // Run does the bulk of the benchmark execution.
 // It launches a gorountine to track stats, creates workers to process queries,
 // read in the input, execute the queries, and then does cleanup.
@@ -147,10 +197,19 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 		panic("burn-in is larger than limit")
 	}
 	b.ch = make(chan Query, b.Workers)
+	b.serveMetrics()
+	b.interrupt = interrupt.Notify()
+	defer b.interrupt.Stop()
+	b.scanner.stop = b.interrupt.Done()
 
 	// Launch the stats processor:
 	go b.sp.process(b.Workers)
 
+	var workloadDone chan struct{}
+	if b.workload != nil {
+		workloadDone = b.startWorkload()
+	}
+
 	rateLimiter := getRateLimiter(b.LimitRPS, b.Workers)
 
 	// Launch query processors
@@ -163,7 +222,11 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	// Read in jobs, closing the job channel when done:
 	// Wall clock start time
 	wallStart := time.Now()
-	b.scanner.setReader(b.GetBufferedReader()).scan(queryPool, b.ch)
+	if b.workload != nil {
+		b.scanUntil(queryPool, workloadDone)
+	} else {
+		b.scanner.setReader(b.GetBufferedReader()).scan(queryPool, b.ch)
+	}
 	close(b.ch)
 
 	// Block for workers to finish sending requests, closing the stats channel when done:
@@ -177,6 +240,9 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	if err != nil {
 		log.Fatal(err)
 	}
+	if b.interrupt.Interrupted() {
+		fmt.Printf("interrupted: the results cover the queries run until then\n")
+	}
 
 	// (Optional) create a memory profile:
 	if len(b.MemProfile) > 0 {
@@ -192,6 +258,11 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	if len(b.BenchmarkRunnerConfig.ResultsFile) > 0 {
 		b.saveTestResult(wallTook, wallStart, wallEnd)
 	}
+
+	// The queries may be done before the data is loaded, e.g. due to the limit
+	if workloadDone != nil {
+		<-workloadDone
+	}
 }
 
 func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time) {
@@ -201,6 +272,7 @@ func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, en
 		StartTime:           start.UTC().Unix() * 1000,
 		EndTime:             end.UTC().Unix() * 1000,
 		DurationMillis:      took.Milliseconds(),
+		Partial:             b.interrupt.Interrupted(),
 		Totals:              b.sp.GetTotalsMap(),
 	}
 
@@ -222,10 +294,16 @@ func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate
 		r := rateLimiter.Reserve()
 		time.Sleep(r.Delay())
 
+		b.liveMetrics.queryStarted()
 		stats, err := processor.ProcessQuery(query, false)
 		if err != nil {
 			panic(err)
 		}
+		b.liveMetrics.queryDone()
+		if b.latencies != nil {
+			// before sending, as the stats processor reuses the stats
+			b.latencies.record(stats)
+		}
 		b.sp.send(stats)
 
 		// If PrewarmQueries is set, we run the query as 'cold' first (see above),
@@ -234,10 +312,12 @@ func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate
 		spArgs := b.sp.getArgs()
 		if spArgs.prewarmQueries {
 			// Warm run
+			b.liveMetrics.queryStarted()
 			stats, err = processor.ProcessQuery(query, true)
 			if err != nil {
 				panic(err)
 			}
+			b.liveMetrics.queryDone()
 			b.sp.sendWarm(stats)
 		}
 		queryPool.Put(query)
@@ -1,10 +1,13 @@
 package query
 
 import (
+	"bytes"
+	"compress/gzip"
 	"golang.org/x/time/rate"
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -362,3 +365,43 @@ func TestGetRateLimiter(t *testing.T) {
 		})
 	}
 }
+
+func TestBenchmarkRunnerScanFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queries")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// each file is a stream of queries of its own, the second one gzipped
+	gen := func(i uint64) Query { return &testQuery{} }
+	for i, name := range []string{"1", "2.gz"} {
+		var b bytes.Buffer
+		if err := encodeQueries(&b, 2, gen); err != nil {
+			t.Fatal(err)
+		}
+		data := b.Bytes()
+		if i == 1 {
+			var gz bytes.Buffer
+			w := gzip.NewWriter(&gz)
+			w.Write(data)
+			w.Close()
+			data = gz.Bytes()
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b := NewBenchmarkRunner(BenchmarkRunnerConfig{FileName: dir, ReadAhead: 1})
+	b.ch = make(chan Query, 10)
+	b.scanner.setReader(b.GetBufferedReader()).scan(&testQueryPool, b.ch)
+	close(b.ch)
+	var ids []uint64
+	for q := range b.ch {
+		ids = append(ids, q.GetID())
+	}
+	if len(ids) != 4 || ids[3] != 3 {
+		t.Errorf("incorrect queries read from the files: got IDs %v want %v", ids, []uint64{0, 1, 2, 3})
+	}
+}
@@ -0,0 +1,71 @@
+package query
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ClickHouse encodes a ClickHouse request. This will be serialized for use
+// by the tsbs_run_queries_clickhouse program.
+type ClickHouse struct {
+	HumanLabel       []byte
+	HumanDescription []byte
+
+	Table    []byte // e.g. "cpu"
+	SqlQuery []byte
+	id       uint64
+}
+
+// ClickHousePool is a sync.Pool of ClickHouse Query types
+var ClickHousePool = sync.Pool{
+	New: func() interface{} {
+		return &ClickHouse{
+			HumanLabel:       make([]byte, 0, 1024),
+			HumanDescription: make([]byte, 0, 1024),
+			Table:            make([]byte, 0, 1024),
+			SqlQuery:         make([]byte, 0, 1024),
+		}
+	},
+}
+
+// NewClickHouse returns a new ClickHouse Query instance
+func NewClickHouse() *ClickHouse {
+	return ClickHousePool.Get().(*ClickHouse)
+}
+
+// GetID returns the ID of this Query
+func (q *ClickHouse) GetID() uint64 {
+	return q.id
+}
+
+// SetID sets the ID for this Query
+func (q *ClickHouse) SetID(n uint64) {
+	q.id = n
+}
+
+// String produces a debug-ready description of a Query.
+func (q *ClickHouse) String() string {
+	return fmt.Sprintf("HumanLabel: %s, HumanDescription: %s, Table:      %s, Query: %s", q.HumanLabel, q.HumanDescription, q.Table, q.SqlQuery)
+}
+
+// HumanLabelName returns the human readable name of this Query
+func (q *ClickHouse) HumanLabelName() []byte {
+	return q.HumanLabel
+}
+
+// HumanDescriptionName returns the human readable description of this Query
+func (q *ClickHouse) HumanDescriptionName() []byte {
+	return q.HumanDescription
+}
+
+// Release resets and returns this Query to its pool
+func (q *ClickHouse) Release() {
+	q.HumanLabel = q.HumanLabel[:0]
+	q.HumanDescription = q.HumanDescription[:0]
+	q.id = 0
+
+	q.Table = q.Table[:0]
+	q.SqlQuery = q.SqlQuery[:0]
+
+	ClickHousePool.Put(q)
+}
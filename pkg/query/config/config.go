@@ -7,7 +7,15 @@ import (
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
-const ErrEmptyQueryType = "query type cannot be empty"
+const (
+	ErrEmptyQueryType        = "query type cannot be empty"
+	errInvalidInfluxLanguage = "invalid influx language '%s', valid: influxql, flux"
+
+	// InfluxLanguageInfluxQL generates InfluxQL queries for the /query endpoint.
+	InfluxLanguageInfluxQL = "influxql"
+	// InfluxLanguageFlux generates Flux queries for the /api/v2/query endpoint.
+	InfluxLanguageFlux = "flux"
+)
 
 // QueryGeneratorConfig is the GeneratorConfig that should be used with a
 // QueryGenerator. It includes all the fields from a BaseConfig, as well as
@@ -24,9 +32,12 @@ type QueryGeneratorConfig struct {
 	TimescaleUseJSON       bool `mapstructure:"timescale-use-json"`
 	TimescaleUseTags       bool `mapstructure:"timescale-use-tags"`
 	TimescaleUseTimeBucket bool `mapstructure:"timescale-use-time-bucket"`
+	TimescaleUseCaggs      bool `mapstructure:"timescale-use-caggs"`
 
 	ClickhouseUseTags bool `mapstructure:"clickhouse-use-tags"`
 
+	InfluxLanguage string `mapstructure:"influx-language"`
+
 	MongoUseNaive bool   `mapstructure:"mongo-use-native"`
 	DbName        string `mapstructure:"db-name"`
 }
@@ -42,6 +53,12 @@ func (c *QueryGeneratorConfig) Validate() error {
 		return fmt.Errorf(ErrEmptyQueryType)
 	}
 
+	switch c.InfluxLanguage {
+	case "", InfluxLanguageInfluxQL, InfluxLanguageFlux:
+	default:
+		return fmt.Errorf(errInvalidInfluxLanguage, c.InfluxLanguage)
+	}
+
 	err = utils.ValidateGroups(c.InterleavedGroupID, c.InterleavedNumGroups)
 	return err
 }
@@ -57,10 +74,13 @@ func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 
 	fs.Bool("clickhouse-use-tags", true, "ClickHouse only: Use separate tags table when querying")
+	fs.String("influx-language", InfluxLanguageInfluxQL, "Influx only: Query language to generate. Choices: influxql, flux (InfluxDB 2.x /api/v2/query)")
 	fs.Bool("mongo-use-naive", true, "MongoDB only: Generate queries for the 'naive' data storage format for Mongo")
 	fs.Bool("timescale-use-json", false, "TimescaleDB only: Use separate JSON tags table when querying")
 	fs.Bool("timescale-use-tags", true, "TimescaleDB only: Use separate tags table when querying")
-	fs.Bool("timescale-use-time-bucket", true, "TimescaleDB only: Use time bucket. Set to false to test on native PostgreSQL")
+	fs.Bool("timescale-use-time-bucket", true, "TimescaleDB only: Use time bucket. Set to false to test on native PostgreSQL (date_trunc/date_bin are used instead)")
+
+	fs.Bool("timescale-use-caggs", false, "TimescaleDB only: Read 1m and 1h devops rollups from the cpu_1m and cpu_1h continuous aggregates created by tsbs_load")
 
 	fs.String("db-name", "benchmark", "Specify database name. Timestream requires it in order to generate the queries")
 }
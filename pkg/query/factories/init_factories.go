@@ -1,21 +1,35 @@
 package factories
 
 import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/clickhouse"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/datalayers"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/influx"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/prometheus"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/questdb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/timescaledb"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/victoriametrics"
 	"github.com/timescale/tsbs/pkg/query/config"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func InitQueryFactories(config *config.QueryGeneratorConfig) map[string]interface{} {
+func InitQueryFactories(conf *config.QueryGeneratorConfig) map[string]interface{} {
 	factories := make(map[string]interface{})
-	factories[constants.FormatInflux] = &influx.BaseGenerator{}
+	factories[constants.FormatInflux] = &influx.BaseGenerator{
+		UseFlux: conf.InfluxLanguage == config.InfluxLanguageFlux,
+		Bucket:  conf.DbName,
+	}
 	factories[constants.FormatTimescaleDB] = &timescaledb.BaseGenerator{
-		UseJSON:       config.TimescaleUseJSON,
-		UseTags:       config.TimescaleUseTags,
-		UseTimeBucket: config.TimescaleUseTimeBucket,
+		UseJSON:       conf.TimescaleUseJSON,
+		UseTags:       conf.TimescaleUseTags,
+		UseTimeBucket: conf.TimescaleUseTimeBucket,
+		UseCaggs:      conf.TimescaleUseCaggs,
 	}
 	factories[constants.FormatDatalayers] = &datalayers.BaseGenerator{}
+	factories[constants.FormatPrometheus] = &prometheus.BaseGenerator{}
+	factories[constants.FormatClickhouse] = &clickhouse.BaseGenerator{
+		UseTags: conf.ClickhouseUseTags,
+	}
+	factories[constants.FormatQuestDB] = &questdb.BaseGenerator{}
+	factories[constants.FormatVictoriaMetrics] = &victoriametrics.BaseGenerator{}
 	return factories
 }
@@ -0,0 +1,82 @@
+package query
+
+import (
+	"log"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/timescale/tsbs/internal/metrics"
+)
+
+// liveMetrics are the metrics of the query run served with --metrics-listen
+type liveMetrics struct {
+	reg       *prometheus.Registry
+	queries   prometheus.Counter
+	inFlight  prometheus.Gauge
+	latencies *prometheus.HistogramVec
+}
+
+func newLiveMetrics() *liveMetrics {
+	m := &liveMetrics{
+		reg: prometheus.NewRegistry(),
+		queries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tsbs_queries_total",
+			Help: "Queries run, including the warm runs of prewarmed queries.",
+		}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tsbs_queries_in_flight",
+			Help: "Queries being run by the workers.",
+		}),
+		latencies: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tsbs_query_duration_seconds",
+			Help:    "Query latencies after the burn-in, by the label of the statistics.",
+			Buckets: metrics.LatencyBuckets,
+		}, []string{"label"}),
+	}
+	m.reg.MustRegister(m.queries, m.inFlight, m.latencies)
+	return m
+}
+
+// serveMetrics starts serving the metrics of the run if --metrics-listen is
+// set
+func (b *BenchmarkRunner) serveMetrics() {
+	if b.MetricsListen == "" {
+		return
+	}
+	b.liveMetrics = newLiveMetrics()
+	b.liveMetrics.reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "tsbs_query_queue_depth",
+		Help: "Queries read and waiting for a worker.",
+	}, func() float64 { return float64(len(b.ch)) }))
+	b.sp.getArgs().liveMetrics = b.liveMetrics
+
+	addr, err := metrics.Listen(b.MetricsListen, b.liveMetrics.reg)
+	if err != nil {
+		log.Fatalf("could not serve metrics on %s: %v", b.MetricsListen, err)
+	}
+	log.Printf("serving metrics on http://%s%s", addr, metrics.Path)
+}
+
+// queryStarted counts a query being run
+func (m *liveMetrics) queryStarted() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Inc()
+}
+
+// queryDone counts a query that was run
+func (m *liveMetrics) queryDone() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Dec()
+	m.queries.Inc()
+}
+
+// observe records the latency, in milliseconds, of a statistic
+func (m *liveMetrics) observe(stat *Stat) {
+	if m == nil {
+		return
+	}
+	m.latencies.WithLabelValues(string(stat.label)).Observe(stat.value / 1e3)
+}
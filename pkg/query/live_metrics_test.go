@@ -0,0 +1,52 @@
+package query
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/time/rate"
+)
+
+func TestLiveMetrics(t *testing.T) {
+	b := NewBenchmarkRunner(BenchmarkRunnerConfig{MetricsListen: "127.0.0.1:0", PrewarmQueries: true})
+	b.ch = make(chan Query, 4)
+	b.serveMetrics()
+	if b.liveMetrics == nil || b.sp.getArgs().liveMetrics != b.liveMetrics {
+		t.Fatalf("metrics not set up")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	qPool := &testQueryPool
+	go b.processorHandler(&wg, rate.NewLimiter(rate.Inf, 0), qPool, &testProcessor{}, 0)
+	for i := 0; i < 3; i++ {
+		b.ch <- qPool.Get().(*testQuery)
+	}
+	close(b.ch)
+	wg.Wait()
+
+	s := GetStat().Init([]byte("query A"), 20)
+	b.liveMetrics.observe(s)
+
+	want := `
+# HELP tsbs_queries_in_flight Queries being run by the workers.
+# TYPE tsbs_queries_in_flight gauge
+tsbs_queries_in_flight 0
+# HELP tsbs_queries_total Queries run, including the warm runs of prewarmed queries.
+# TYPE tsbs_queries_total counter
+tsbs_queries_total 6
+# HELP tsbs_query_queue_depth Queries read and waiting for a worker.
+# TYPE tsbs_query_queue_depth gauge
+tsbs_query_queue_depth 0
+`
+	err := testutil.GatherAndCompare(b.liveMetrics.reg, strings.NewReader(want),
+		"tsbs_queries_in_flight", "tsbs_queries_total", "tsbs_query_queue_depth")
+	if err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(b.liveMetrics.latencies); got != 1 {
+		t.Errorf("incorrect number of latency histograms: got %d want 1", got)
+	}
+}
@@ -0,0 +1,141 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Workload is run in the background while the queries run, to benchmark the
+// queries under a mixed read/write workload
+type Workload interface {
+	// Run runs the workload, returning when it is done
+	Run()
+	// Progress returns the number of metrics and rows written so far
+	Progress() (metrics, rows uint64)
+	// Ready returns a channel that is closed once the queries can run, e.g.
+	// once the workload created the database
+	Ready() <-chan struct{}
+}
+
+// SetWorkload sets a Workload, such as a data load, to run while the queries
+// run. The queries are then read from the file again and again until the
+// workload is done (or the query limit is reached).
+func (b *BenchmarkRunner) SetWorkload(w Workload) {
+	b.workload = w
+}
+
+// startWorkload runs the workload in the background and returns a channel
+// that is closed when it is done. It returns once the workload is ready for
+// the queries, as it may e.g. create the database they run on first.
+func (b *BenchmarkRunner) startWorkload() chan struct{} {
+	if len(b.FileName) == 0 {
+		panic("queries must be read from a file to run them while loading data")
+	}
+	b.latencies = newPeriodLatencies()
+	done := make(chan struct{})
+	go func() {
+		b.workload.Run()
+		close(done)
+	}()
+	select {
+	case <-b.workload.Ready():
+	case <-done:
+	}
+	if b.ReportingPeriod > 0 {
+		go b.reportMixed(os.Stdout, b.ReportingPeriod, done)
+	}
+	return done
+}
+
+// scanUntil reads the queries from the files, starting over at the end of the
+// last one, until done is closed or the query limit is reached
+func (b *BenchmarkRunner) scanUntil(queryPool *sync.Pool, done chan struct{}) {
+	br := b.GetBufferedReader()
+	n := uint64(0)
+	for {
+		next := b.scanner.setReader(br).scanFrom(queryPool, b.ch, n, done)
+		if next == n {
+			// no queries in the files or no more wanted
+			return
+		}
+		n = next
+		if err := b.files.Rewind(); err != nil {
+			panic(fmt.Sprintf("cannot open file for read %s: %v", b.FileName, err))
+		}
+		br.Reset(b.files)
+	}
+}
+
+// reportMixed prints the ingest rate of the workload and the latency
+// percentiles of the queries completed in each period, until done is closed
+func (b *BenchmarkRunner) reportMixed(w io.Writer, period time.Duration, done chan struct{}) {
+	prevTime := time.Now()
+	prevMetrics, prevRows := uint64(0), uint64(0)
+
+	fmt.Fprintf(w, "time,per. metric/s,per. row/s,queries,per. query/s,p50 ms,p95 ms,p99 ms\n")
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			metrics, rows := b.workload.Progress()
+			took := now.Sub(prevTime).Seconds()
+			count, p50, p95, p99 := b.latencies.next()
+			fmt.Fprintf(w, "%d,%0.2f,%0.2f,%d,%0.2f,%0.2f,%0.2f,%0.2f\n",
+				now.Unix(),
+				float64(metrics-prevMetrics)/took,
+				float64(rows-prevRows)/took,
+				count,
+				float64(count)/took,
+				p50, p95, p99,
+			)
+			prevMetrics, prevRows = metrics, rows
+			prevTime = now
+		}
+	}
+}
+
+// periodLatencies collects the latencies of the queries completed in the
+// current reporting period
+type periodLatencies struct {
+	mu   sync.Mutex
+	hist *hdrhistogram.Histogram
+}
+
+func newPeriodLatencies() *periodLatencies {
+	// same range and precision as the latencies of a statGroup
+	return &periodLatencies{hist: hdrhistogram.New(1, 3600000000, 4)}
+}
+
+// record adds the latencies of the (complete) queries in stats
+func (l *periodLatencies) record(stats []*Stat) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range stats {
+		if !s.isPartial {
+			l.hist.RecordValue(int64(s.value * hdrScaleFactor))
+		}
+	}
+}
+
+// next returns the number of queries of the period and their median, 95th
+// and 99th percentile latencies in milliseconds, and starts the next period
+func (l *periodLatencies) next() (count int64, p50, p95, p99 float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count = l.hist.TotalCount()
+	if count > 0 {
+		p50 = float64(l.hist.ValueAtQuantile(50.0)) / hdrScaleFactor
+		p95 = float64(l.hist.ValueAtQuantile(95.0)) / hdrScaleFactor
+		p99 = float64(l.hist.ValueAtQuantile(99.0)) / hdrScaleFactor
+	}
+	l.hist.Reset()
+	return count, p50, p95, p99
+}
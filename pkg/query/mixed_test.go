@@ -0,0 +1,165 @@
+package query
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testWorkload struct {
+	run     chan struct{}
+	ready   chan struct{}
+	metrics uint64
+	rows    uint64
+}
+
+func (w *testWorkload) Run()                             { <-w.run }
+func (w *testWorkload) Progress() (metrics, rows uint64) { return w.metrics, w.rows }
+func (w *testWorkload) Ready() <-chan struct{}           { return w.ready }
+
+func TestPeriodLatencies(t *testing.T) {
+	l := newPeriodLatencies()
+	stats := []*Stat{
+		GetStat().Init([]byte("a"), 1.0),
+		GetStat().Init([]byte("a"), 2.0),
+		GetPartialStat().Init([]byte("a"), 1000.0),
+		GetStat().Init([]byte("a"), 3.0),
+	}
+	l.record(stats)
+	count, p50, p95, p99 := l.next()
+	if count != 3 {
+		t.Errorf("incorrect count: got %d want %d", count, 3)
+	}
+	if p50 != 2.0 || p95 != 3.0 || p99 != 3.0 {
+		t.Errorf("incorrect percentiles: got %f, %f, %f", p50, p95, p99)
+	}
+
+	// the next period starts empty
+	count, p50, p95, p99 = l.next()
+	if count != 0 || p50 != 0 || p95 != 0 || p99 != 0 {
+		t.Errorf("period not reset: got %d, %f, %f, %f", count, p50, p95, p99)
+	}
+}
+
+func TestBenchmarkRunnerScanUntil(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeQueries(&buf, 3, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte("label"), HumanDescription: []byte("desc")}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "mixed_queries*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// the queries are repeated until the limit
+	limit := uint64(8)
+	b := &BenchmarkRunner{
+		BenchmarkRunnerConfig: BenchmarkRunnerConfig{FileName: f.Name()},
+		scanner:               newScanner(&limit),
+		ch:                    make(chan Query, limit),
+	}
+	b.scanUntil(&testQueryPool, make(chan struct{}))
+	close(b.ch)
+	var ids []uint64
+	for q := range b.ch {
+		ids = append(ids, q.GetID())
+	}
+	if uint64(len(ids)) != limit {
+		t.Fatalf("incorrect number of queries: got %d want %d", len(ids), limit)
+	}
+	for i, id := range ids {
+		if id != uint64(i) {
+			t.Errorf("incorrect id of query %d: got %d", i, id)
+		}
+	}
+
+	// or until done
+	limit = 0
+	done := make(chan struct{})
+	close(done)
+	b.ch = make(chan Query, 1)
+	b.scanUntil(&testQueryPool, done)
+	if len(b.ch) != 0 {
+		t.Errorf("queries scanned after done: got %d", len(b.ch))
+	}
+}
+
+func TestBenchmarkRunnerStartWorkloadPanicsWithoutFile(t *testing.T) {
+	b := &BenchmarkRunner{}
+	b.SetWorkload(&testWorkload{run: make(chan struct{})})
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("the code did not panic")
+		}
+	}()
+	b.startWorkload()
+}
+
+func TestBenchmarkRunnerStartWorkloadWaitsForReady(t *testing.T) {
+	w := &testWorkload{run: make(chan struct{}), ready: make(chan struct{})}
+	b := &BenchmarkRunner{workload: w}
+	b.FileName = "queries"
+	started := make(chan struct{})
+	go func() {
+		b.startWorkload()
+		close(started)
+	}()
+
+	select {
+	case <-started:
+		t.Fatalf("workload started before it was ready")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(w.ready)
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("workload not started once it was ready")
+	}
+	close(w.run)
+}
+
+func TestBenchmarkRunnerReportMixed(t *testing.T) {
+	w := &testWorkload{run: make(chan struct{}), metrics: 100, rows: 10}
+	b := &BenchmarkRunner{workload: w, latencies: newPeriodLatencies()}
+	b.latencies.record([]*Stat{GetStat().Init([]byte("a"), 5.0)})
+
+	var out bytes.Buffer
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		b.reportMixed(&out, 50*time.Millisecond, done)
+		wg.Done()
+	}()
+	time.Sleep(75 * time.Millisecond)
+	close(done)
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("incorrect number of lines: got %d want 2\n%s", len(lines), out.String())
+	}
+	if lines[0] != "time,per. metric/s,per. row/s,queries,per. query/s,p50 ms,p95 ms,p99 ms" {
+		t.Errorf("incorrect header: %s", lines[0])
+	}
+	fields := strings.Split(lines[1], ",")
+	if len(fields) != 8 {
+		t.Fatalf("incorrect number of fields: got %d want 8", len(fields))
+	}
+	if fields[3] != "1" || fields[5] != "5.00" || fields[7] != "5.00" {
+		t.Errorf("incorrect query stats: %s", lines[1])
+	}
+}
@@ -0,0 +1,188 @@
+// Package prometheus runs queries against the Prometheus HTTP API, or any
+// database serving a compatible one such as VictoriaMetrics.
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const statusSuccess = "success"
+
+// HTTPClient is a reusable HTTP Client.
+type HTTPClient struct {
+	client     *http.Client
+	Host       []byte
+	HostString string
+	uri        []byte
+}
+
+// HTTPClientDoOptions wraps options uses when calling `Do`.
+type HTTPClientDoOptions struct {
+	Debug                int
+	PrettyPrintResponses bool
+	// QueryLanguage names the query in the pretty printed responses,
+	// "promql" if empty
+	QueryLanguage string
+}
+
+// apiResponse is the envelope of every response of the Prometheus HTTP API.
+type apiResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// resultSeries is a single series of a matrix or vector result. Matrix series
+// carry their samples in Values, vector series a single sample in Value.
+type resultSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []json.RawMessage `json:"values"`
+	Value  json.RawMessage   `json:"value"`
+}
+
+var httpClientOnce = sync.Once{}
+var httpClient *http.Client
+
+func getHttpClient() *http.Client {
+	httpClientOnce.Do(func() {
+		tr := &http.Transport{
+			MaxIdleConnsPerHost: 1024,
+		}
+		httpClient = &http.Client{Transport: tr}
+	})
+	return httpClient
+}
+
+// NewHTTPClient creates a new HTTPClient.
+func NewHTTPClient(host string) *HTTPClient {
+	return &HTTPClient{
+		client:     getHttpClient(),
+		Host:       []byte(host),
+		HostString: host,
+		uri:        []byte{}, // heap optimization
+	}
+}
+
+// Do performs the action specified by the given Query and checks that the
+// query succeeded.
+func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, err error) {
+	// populate uri from the reusable byte slice:
+	w.uri = w.uri[:0]
+	w.uri = append(w.uri, w.Host...)
+	w.uri = append(w.uri, q.Path...)
+
+	req, err := http.NewRequest(string(q.Method), string(w.uri), nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Perform the request while tracking latency:
+	start := time.Now()
+	resp, err := w.client.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
+
+	lag = float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("query %s returned status %d: %s", q.HumanLabel, resp.StatusCode, respBody)
+	}
+
+	series, samples, err := countSeriesAndSamples(respBody)
+	if err != nil {
+		return 0, fmt.Errorf("query %s: %v", q.HumanLabel, err)
+	}
+
+	if opts != nil {
+		// Print debug messages, if applicable:
+		switch opts.Debug {
+		case 1:
+			fmt.Fprintf(os.Stderr, "debug: %s in %7.2fms -- %d series, %d samples\n", q.HumanLabel, lag, series, samples)
+		case 2:
+			fmt.Fprintf(os.Stderr, "debug: %s in %7.2fms -- %s -- %d series, %d samples\n", q.HumanLabel, lag, q.HumanDescription, series, samples)
+		case 3:
+			fmt.Fprintf(os.Stderr, "debug: %s in %7.2fms -- %s -- %d series, %d samples\n", q.HumanLabel, lag, q.HumanDescription, series, samples)
+			fmt.Fprintf(os.Stderr, "debug:   request: %s\n", string(q.String()))
+		case 4:
+			fmt.Fprintf(os.Stderr, "debug: %s in %7.2fms -- %s -- %d series, %d samples\n", q.HumanLabel, lag, q.HumanDescription, series, samples)
+			fmt.Fprintf(os.Stderr, "debug:   request: %s\n", string(q.String()))
+			fmt.Fprintf(os.Stderr, "debug:   response: %s\n", string(respBody))
+		default:
+		}
+
+		// Pretty print JSON responses, if applicable:
+		if opts.PrettyPrintResponses {
+			prefix := fmt.Sprintf("ID %d: ", q.GetID())
+			var v interface{}
+			json.Unmarshal(respBody, &v)
+			language := opts.QueryLanguage
+			if language == "" {
+				language = "promql"
+			}
+			full := map[string]interface{}{
+				language:   string(q.RawQuery),
+				"series":   series,
+				"samples":  samples,
+				"response": v,
+			}
+			line, err := json.MarshalIndent(full, prefix, "  ")
+			if err != nil {
+				return lag, err
+			}
+			fmt.Println(string(line) + "\n")
+		}
+	}
+
+	return lag, nil
+}
+
+// countSeriesAndSamples parses a Prometheus HTTP API response and returns the
+// number of series and samples in its result. A response with a status other
+// than success is returned as an error.
+func countSeriesAndSamples(body []byte) (series, samples int, err error) {
+	var resp apiResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, 0, fmt.Errorf("could not parse response: %v", err)
+	}
+	if resp.Status != statusSuccess {
+		return 0, 0, fmt.Errorf("query failed with %s: %s", resp.ErrorType, resp.Error)
+	}
+
+	switch resp.Data.ResultType {
+	case "matrix", "vector":
+		var result []resultSeries
+		if err := json.Unmarshal(resp.Data.Result, &result); err != nil {
+			return 0, 0, fmt.Errorf("could not parse %s result: %v", resp.Data.ResultType, err)
+		}
+		for _, s := range result {
+			if len(s.Value) > 0 {
+				samples++
+			}
+			samples += len(s.Values)
+		}
+		return len(result), samples, nil
+	case "scalar", "string":
+		return 1, 1, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown result type %q", resp.Data.ResultType)
+	}
+}
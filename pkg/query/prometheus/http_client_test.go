@@ -0,0 +1,69 @@
+package prometheus
+
+import "testing"
+
+func TestCountSeriesAndSamples(t *testing.T) {
+	cases := []struct {
+		desc        string
+		body        string
+		wantSeries  int
+		wantSamples int
+		wantErr     bool
+	}{
+		{
+			desc: "matrix",
+			body: `{"status":"success","data":{"resultType":"matrix","result":[` +
+				`{"metric":{"hostname":"host_0"},"values":[[1451606400,"1"],[1451606460,"2"]]},` +
+				`{"metric":{"hostname":"host_1"},"values":[[1451606400,"3"]]}]}}`,
+			wantSeries:  2,
+			wantSamples: 3,
+		},
+		{
+			desc: "vector",
+			body: `{"status":"success","data":{"resultType":"vector","result":[` +
+				`{"metric":{"hostname":"host_0"},"value":[1451606400,"1"]},` +
+				`{"metric":{"hostname":"host_1"},"value":[1451606400,"2"]}]}}`,
+			wantSeries:  2,
+			wantSamples: 2,
+		},
+		{
+			desc:        "empty matrix",
+			body:        `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			wantSeries:  0,
+			wantSamples: 0,
+		},
+		{
+			desc:        "scalar",
+			body:        `{"status":"success","data":{"resultType":"scalar","result":[1451606400,"1"]}}`,
+			wantSeries:  1,
+			wantSamples: 1,
+		},
+		{
+			desc:    "error status",
+			body:    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr: true,
+		},
+		{
+			desc:    "invalid json",
+			body:    `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		series, samples, err := countSeriesAndSamples([]byte(c.body))
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+			continue
+		}
+		if series != c.wantSeries || samples != c.wantSamples {
+			t.Errorf("%s: got %d series %d samples, want %d series %d samples", c.desc, series, samples, c.wantSeries, c.wantSamples)
+		}
+	}
+}
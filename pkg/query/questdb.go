@@ -0,0 +1,71 @@
+package query
+
+import (
+	"fmt"
+	"sync"
+)
+
+// QuestDB encodes a QuestDB request. This will be serialized for use
+// by the tsbs_run_queries_questdb program.
+type QuestDB struct {
+	HumanLabel       []byte
+	HumanDescription []byte
+
+	Table    []byte // e.g. "cpu"
+	SqlQuery []byte
+	id       uint64
+}
+
+// QuestDBPool is a sync.Pool of QuestDB Query types
+var QuestDBPool = sync.Pool{
+	New: func() interface{} {
+		return &QuestDB{
+			HumanLabel:       make([]byte, 0, 1024),
+			HumanDescription: make([]byte, 0, 1024),
+			Table:            make([]byte, 0, 1024),
+			SqlQuery:         make([]byte, 0, 1024),
+		}
+	},
+}
+
+// NewQuestDB returns a new QuestDB Query instance
+func NewQuestDB() *QuestDB {
+	return QuestDBPool.Get().(*QuestDB)
+}
+
+// GetID returns the ID of this Query
+func (q *QuestDB) GetID() uint64 {
+	return q.id
+}
+
+// SetID sets the ID for this Query
+func (q *QuestDB) SetID(n uint64) {
+	q.id = n
+}
+
+// String produces a debug-ready description of a Query.
+func (q *QuestDB) String() string {
+	return fmt.Sprintf("HumanLabel: %s, HumanDescription: %s, Table:      %s, Query: %s", q.HumanLabel, q.HumanDescription, q.Table, q.SqlQuery)
+}
+
+// HumanLabelName returns the human readable name of this Query
+func (q *QuestDB) HumanLabelName() []byte {
+	return q.HumanLabel
+}
+
+// HumanDescriptionName returns the human readable description of this Query
+func (q *QuestDB) HumanDescriptionName() []byte {
+	return q.HumanDescription
+}
+
+// Release resets and returns this Query to its pool
+func (q *QuestDB) Release() {
+	q.HumanLabel = q.HumanLabel[:0]
+	q.HumanDescription = q.HumanDescription[:0]
+	q.id = 0
+
+	q.Table = q.Table[:0]
+	q.SqlQuery = q.SqlQuery[:0]
+
+	QuestDBPool.Put(q)
+}
@@ -12,6 +12,11 @@ import (
 type scanner struct {
 	r     io.Reader
 	limit *uint64
+	// stop ends scanning when closed, e.g. when the run is interrupted
+	stop <-chan struct{}
+	// next moves r on to the next file at EOF, if set, returning false if
+	// there are none. Each file is a stream of Queries of its own.
+	next func() bool
 }
 
 // newScanner returns a new scanner for a given Reader and its limit
@@ -27,18 +32,38 @@ func (s *scanner) setReader(r io.Reader) *scanner {
 
 // scan reads encoded Queries and places them into a channel
 func (s *scanner) scan(pool *sync.Pool, c chan Query) {
+	s.scanFrom(pool, c, 0, nil)
+}
+
+// scanFrom reads encoded Queries and places them into a channel, numbering
+// them starting at n, until EOF of the last file, the limit or until done or
+// stop is closed.
+// It returns the number of the next query.
+func (s *scanner) scanFrom(pool *sync.Pool, c chan Query, n uint64, done <-chan struct{}) uint64 {
 	decoder := gob.NewDecoder(s.r)
 
-	n := uint64(0)
 	for {
 		if *s.limit > 0 && n >= *s.limit {
 			// request queries limit reached, time to quit
 			break
 		}
+		select {
+		case <-done:
+			// the caller does not need more queries
+			return n
+		case <-s.stop:
+			return n
+		default:
+		}
 
 		q := pool.Get().(Query)
 		err := decoder.Decode(q)
 		if err == io.EOF {
+			if s.next != nil && s.next() {
+				pool.Put(q)
+				decoder = gob.NewDecoder(s.r)
+				continue
+			}
 			// EOF, all done
 			break
 		}
@@ -54,4 +79,5 @@ func (s *scanner) scan(pool *sync.Pool, c chan Query) {
 		// Queries counter
 		n++
 	}
+	return n
 }
@@ -113,6 +113,31 @@ func TestScannerLimit(t *testing.T) {
 	}
 }
 
+func TestScannerStop(t *testing.T) {
+	var b bytes.Buffer
+	err := encodeQueries(&b, 3, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte("testlabel")}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	limit := uint64(0)
+	scanner := newScanner(&limit)
+	scanner.stop = stop
+	queryChan := make(chan Query, 3)
+	scanner.setReader(bytes.NewReader(b.Bytes()))
+	if next := scanner.scanFrom(&testQueryPool, queryChan, 0, nil); next != 3 {
+		t.Errorf("incorrect queries scanned before stopping: got %d want %d", next, 3)
+	}
+	close(stop)
+	scanner.setReader(bytes.NewReader(b.Bytes()))
+	if next := scanner.scanFrom(&testQueryPool, queryChan, 3, nil); next != 3 {
+		t.Errorf("queries scanned after stopping: got %d want %d", next-3, 0)
+	}
+}
+
 func TestScanTimescaleDB(t *testing.T) {
 	labelFmt := "tslabel%d"
 	descFmt := "tsdesc%d"
@@ -5,10 +5,12 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/internal/report"
 	"io/ioutil"
 	"log"
 	"os"
 	"regexp"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -30,7 +32,11 @@ type statProcessorArgs struct {
 	burnIn           uint64  // burnIn is the number of statistics to ignore before analyzing
 	printInterval    uint64  // printInterval is how often print intermediate stats (number of queries)
 	hdrLatenciesFile string  // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	reportFile       string  // reportFile is the filename to also write the intermediate and final stats to
+	reportFormat     string  // reportFormat is the format of the reportFile, csv or jsonl
 
+	// liveMetrics, if set, are served with the latencies after the burn-in
+	liveMetrics *liveMetrics
 }
 
 // statProcessor is used to collect, analyze, and print query execution statistics.
@@ -91,6 +97,19 @@ func (sp *defaultStatProcessor) process(workers uint) {
 		sp.statMapping[labelWarmQueries] = newStatGroup(*sp.args.limit)
 	}
 
+	var rw *report.Writer
+	if len(sp.args.reportFile) > 0 {
+		format := sp.args.reportFormat
+		if format == "" {
+			format = report.FormatCSV
+		}
+		var err error
+		rw, err = report.NewWriter(sp.args.reportFile, format, reportColumns)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	i := uint64(0)
 	sp.startTime = time.Now()
 	prevTime := sp.startTime
@@ -113,6 +132,7 @@ func (sp *defaultStatProcessor) process(workers uint) {
 		}
 
 		sp.statMapping[string(stat.label)].push(stat.value)
+		sp.args.liveMetrics.observe(stat)
 
 		if !stat.isPartial {
 			sp.statMapping[allQueriesLabel].push(stat.value)
@@ -160,12 +180,29 @@ func (sp *defaultStatProcessor) process(workers uint) {
 			if err != nil {
 				log.Fatal(err)
 			}
+			if rw != nil {
+				err = writeReport(rw, now, i-sp.args.burnIn, intervalQueryRate, overallQueryRate, sp.statMapping)
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
 			prevRequestCount = sp.opsCount
 			prevTime = now
 		}
 	}
-	sinceStart := time.Now().Sub(sp.startTime)
+	now := time.Now()
+	sinceStart := now.Sub(sp.startTime)
 	overallQueryRate := float64(sp.opsCount) / float64(sinceStart.Seconds())
+	if rw != nil {
+		intervalQueryRate := float64(sp.opsCount-prevRequestCount) / float64(now.Sub(prevTime).Seconds())
+		err := writeReport(rw, now, i-sp.args.burnIn, intervalQueryRate, overallQueryRate, sp.statMapping)
+		if err == nil {
+			err = rw.Close()
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 	// the final stats output goes to stdout:
 	_, err := fmt.Printf("Run complete after %d queries with %d workers (Overall query rate %0.2f queries/sec):\n", i-sp.args.burnIn, workers, overallQueryRate)
 	if err != nil {
@@ -194,6 +231,34 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	sp.wg.Done()
 }
 
+// reportColumns are the columns of the report file, which has a record for
+// each label every time the stats are printed
+var reportColumns = []string{"time", "queries", "queryRate", "overallQueryRate", "label", "count", "min", "p50", "p95", "p99", "p999", "max", "mean"}
+
+// writeReport writes a record for each of the statGroups, with their latency
+// percentiles in milliseconds, to the report file
+func writeReport(rw *report.Writer, now time.Time, queries uint64, intervalQueryRate, overallQueryRate float64, statGroups map[string]*statGroup) error {
+	labels := make([]string, 0, len(statGroups))
+	for label := range statGroups {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		s := statGroups[label]
+		h := s.latencyHDRHistogram
+		err := rw.Write(now.Unix(), queries, intervalQueryRate, overallQueryRate, label, s.count, s.Min(),
+			float64(h.ValueAtQuantile(50.0))/hdrScaleFactor,
+			float64(h.ValueAtQuantile(95.0))/hdrScaleFactor,
+			float64(h.ValueAtQuantile(99.0))/hdrScaleFactor,
+			float64(h.ValueAtQuantile(99.9))/hdrScaleFactor,
+			s.Max(), s.Mean())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func generateQuantileMap(hist *hdrhistogram.Histogram) (int64, map[string]float64) {
 	ops := hist.TotalCount()
 	q0 := 0.0
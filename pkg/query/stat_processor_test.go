@@ -1,8 +1,12 @@
 package query
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
+
+	"github.com/timescale/tsbs/internal/report"
 )
 
 func TestStatProcessorSend(t *testing.T) {
@@ -77,3 +81,41 @@ func TestStatProcessorSendWarm(t *testing.T) {
 		t.Errorf("empty stat array changed channel length: got %d want %d", got, wantLen)
 	}
 }
+
+func TestWriteReport(t *testing.T) {
+	reportFile, err := ioutil.TempFile("", "report_*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportFile.Close()
+	defer os.Remove(reportFile.Name())
+
+	rw, err := report.NewWriter(reportFile.Name(), report.FormatCSV, reportColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statGroups := map[string]*statGroup{
+		labelAllQueries: newStatGroup(0),
+		"query A":       newStatGroup(0),
+	}
+	for i := 1; i <= 4; i++ {
+		statGroups[labelAllQueries].push(float64(i))
+		statGroups["query A"].push(float64(i))
+	}
+	err = writeReport(rw, time.Unix(1600000000, 0), 4, 2.5, 2, statGroups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw.Close()
+
+	got, err := ioutil.ReadFile(reportFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "time,queries,queryRate,overallQueryRate,label,count,min,p50,p95,p99,p999,max,mean\n" +
+		"1600000000,4,2.5,2,all queries,4,1,2,4,4,4,4,2.5\n" +
+		"1600000000,4,2.5,2,query A,4,1,2,4,4,4,4,2.5\n"
+	if string(got) != want {
+		t.Errorf("incorrect report\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
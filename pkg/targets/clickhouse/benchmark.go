@@ -0,0 +1,83 @@
+package clickhouse
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// ClickhouseConfig holds the ClickHouse specific loading options.
+type ClickhouseConfig struct {
+	Host     string `yaml:"host" mapstructure:"host"`
+	Port     string `yaml:"port" mapstructure:"port"`
+	User     string `yaml:"user" mapstructure:"user"`
+	Password string `yaml:"password" mapstructure:"password"`
+	UseTags  bool   `yaml:"use-tags" mapstructure:"use-tags"`
+	Debug    int    `yaml:"debug" mapstructure:"debug"`
+
+	DbName string `yaml:"-" mapstructure:"-"`
+}
+
+// getConnectString returns the DSN of the native protocol connection to
+// dbName. An empty dbName connects to the default database.
+func (c *ClickhouseConfig) getConnectString(dbName string) string {
+	connStr := fmt.Sprintf("tcp://%s:%s?username=%s&password=%s", c.Host, c.Port, c.User, c.Password)
+	if dbName != "" {
+		connStr += "&database=" + dbName
+	}
+	if c.Debug > 0 {
+		connStr += "&debug=true"
+	}
+	return connStr
+}
+
+func NewBenchmark(conf *ClickhouseConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(dataSourceConfig.File.Location, dataSourceConfig.File.ReadAhead)
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = newSimulationDataSource(simulator)
+	}
+
+	return &benchmark{
+		conf: conf,
+		ds:   ds,
+		tags: newTagIndex(),
+	}, nil
+}
+
+type benchmark struct {
+	conf *ClickhouseConfig
+	ds   targets.DataSource
+	tags *tagIndex
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	if maxPartitions > 1 {
+		return &hostnameIndexer{partitions: maxPartitions}
+	}
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{conf: b.conf, ds: b.ds, tags: b.tags}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{conf: b.conf, ds: b.ds}
+}
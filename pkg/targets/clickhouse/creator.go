@@ -0,0 +1,170 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/timescale/tsbs/pkg/targets"
+
+	_ "github.com/kshvakov/clickhouse"
+)
+
+const (
+	driver             = "clickhouse"
+	tagsKey            = "tags"
+	tagsTable          = "tags"
+	additionalTagsCol  = "additional_tags"
+	serializedTypeText = "string"
+)
+
+// allows for testing
+var fatal = log.Fatalf
+
+type dbCreator struct {
+	conf *ClickhouseConfig
+	ds   targets.DataSource
+}
+
+func (d *dbCreator) Init() {
+	// read the headers before all else
+	d.ds.Headers()
+}
+
+func (d *dbCreator) DBExists(dbName string) bool {
+	db := mustConnect(d.conf.getConnectString(""))
+	defer db.Close()
+	r := mustQuery(db, "SELECT name FROM system.databases WHERE name = ?", dbName)
+	defer r.Close()
+	return r.Next()
+}
+
+func (d *dbCreator) RemoveOldDB(dbName string) error {
+	db := mustConnect(d.conf.getConnectString(""))
+	defer db.Close()
+	mustExec(db, "DROP DATABASE IF EXISTS "+dbName)
+	return nil
+}
+
+func (d *dbCreator) CreateDB(dbName string) error {
+	db := mustConnect(d.conf.getConnectString(""))
+	defer db.Close()
+	mustExec(db, "CREATE DATABASE "+dbName)
+	return nil
+}
+
+// PostCreateDB creates the tags table, when tags are stored separately, and
+// one MergeTree table per measurement.
+func (d *dbCreator) PostCreateDB(dbName string) error {
+	db := mustConnect(d.conf.getConnectString(dbName))
+	defer db.Close()
+
+	headers := d.ds.Headers()
+	if d.conf.UseTags {
+		mustExec(db, generateTagsTableQuery(headers.TagKeys, headers.TagTypes))
+	}
+	for tableName, fieldKeys := range headers.FieldKeys {
+		mustExec(db, generateDataTableQuery(tableName, fieldKeys, headers.TagKeys, headers.TagTypes, d.conf.UseTags))
+	}
+	return nil
+}
+
+// StorageBytes returns the size of the active data parts of all tables of the
+// database. Parts not merged yet are counted as they are on disk.
+func (d *dbCreator) StorageBytes(dbName string) (int64, error) {
+	db, err := sql.Open(driver, d.conf.getConnectString(""))
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var bytes uint64
+	err = db.QueryRow("SELECT sum(bytes_on_disk) FROM system.parts WHERE database = ? AND active", dbName).Scan(&bytes)
+	return int64(bytes), err
+}
+
+// generateTagsTableQuery returns the DDL of the tags table. Every tag set is
+// stored under the id the data tables refer to in tags_id; a tag set inserted
+// by more than one load, e.g. by several agents, is merged into one row.
+func generateTagsTableQuery(tagNames, tagTypes []string) string {
+	cols := make([]string, 0, len(tagNames)+1)
+	cols = append(cols, "id UInt64")
+	cols = append(cols, tagColumnDefinitions(tagNames, tagTypes)...)
+	return fmt.Sprintf("CREATE TABLE %s(%s) ENGINE = ReplacingMergeTree() ORDER BY id", tagsTable, strings.Join(cols, ", "))
+}
+
+// generateDataTableQuery returns the DDL of a measurement table. Rows are
+// ordered by the tag set they belong to and then by time, which is either
+// the tags_id or, without a tags table, the first (primary) tag.
+func generateDataTableQuery(tableName string, fieldKeys, tagNames, tagTypes []string, useTags bool) string {
+	cols := []string{"time DateTime"}
+	var orderBy string
+	if useTags {
+		cols = append(cols, "tags_id UInt64")
+		orderBy = "tags_id"
+	} else {
+		cols = append(cols, tagColumnDefinitions(tagNames, tagTypes)...)
+		orderBy = tagNames[0]
+	}
+	for _, field := range fieldKeys {
+		cols = append(cols, field+" Nullable(Float64)")
+	}
+	cols = append(cols, additionalTagsCol+" String DEFAULT ''")
+
+	return fmt.Sprintf("CREATE TABLE %s(%s) ENGINE = MergeTree() PARTITION BY toYYYYMM(time) ORDER BY (%s, time)",
+		tableName, strings.Join(cols, ", "), orderBy)
+}
+
+func tagColumnDefinitions(tagNames, tagTypes []string) []string {
+	defs := make([]string, len(tagNames))
+	for i, tagName := range tagNames {
+		defs[i] = fmt.Sprintf("%s %s", tagName, serializedTypeToClickhouseType(tagTypes[i]))
+	}
+	return defs
+}
+
+// serializedTypeToClickhouseType maps the tag types of the data header to
+// column types. Missing string tags are stored as empty strings, missing
+// numeric tags as NULL.
+func serializedTypeToClickhouseType(serializedType string) string {
+	switch serializedType {
+	case serializedTypeText:
+		return "String"
+	case "float32":
+		return "Nullable(Float32)"
+	case "float64":
+		return "Nullable(Float64)"
+	case "int64":
+		return "Nullable(Int64)"
+	case "int32":
+		return "Nullable(Int32)"
+	default:
+		panic(fmt.Sprintf("unrecognized type %s", serializedType))
+	}
+}
+
+func mustConnect(connStr string) *sql.DB {
+	db, err := sql.Open(driver, connStr)
+	if err != nil {
+		panic(err)
+	}
+	return db
+}
+
+func mustExec(db *sql.DB, query string, args ...interface{}) sql.Result {
+	r, err := db.Exec(query, args...)
+	if err != nil {
+		fmt.Printf("could not execute sql: %s", query)
+		panic(err)
+	}
+	return r
+}
+
+func mustQuery(db *sql.DB, query string, args ...interface{}) *sql.Rows {
+	r, err := db.Query(query, args...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
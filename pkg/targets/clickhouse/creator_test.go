@@ -0,0 +1,115 @@
+package clickhouse
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestGenerateTagsTableQuery(t *testing.T) {
+	got := generateTagsTableQuery([]string{"hostname", "load_capacity"}, []string{"string", "float32"})
+	want := "CREATE TABLE tags(id UInt64, hostname String, load_capacity Nullable(Float32)) ENGINE = ReplacingMergeTree() ORDER BY id"
+	if got != want {
+		t.Errorf("incorrect tags table query:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGenerateDataTableQuery(t *testing.T) {
+	cases := []struct {
+		desc    string
+		useTags bool
+		want    string
+	}{
+		{
+			desc:    "with tags table",
+			useTags: true,
+			want: "CREATE TABLE cpu(time DateTime, tags_id UInt64, usage_user Nullable(Float64), usage_system Nullable(Float64), " +
+				"additional_tags String DEFAULT '') ENGINE = MergeTree() PARTITION BY toYYYYMM(time) ORDER BY (tags_id, time)",
+		},
+		{
+			desc:    "tags in table",
+			useTags: false,
+			want: "CREATE TABLE cpu(time DateTime, hostname String, rack Nullable(Int64), usage_user Nullable(Float64), usage_system Nullable(Float64), " +
+				"additional_tags String DEFAULT '') ENGINE = MergeTree() PARTITION BY toYYYYMM(time) ORDER BY (hostname, time)",
+		},
+	}
+	for _, c := range cases {
+		got := generateDataTableQuery("cpu", []string{"usage_user", "usage_system"}, []string{"hostname", "rack"}, []string{"string", "int64"}, c.useTags)
+		if got != c.want {
+			t.Errorf("%s: incorrect data table query:\ngot\n%s\nwant\n%s", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestSerializedTypeToClickhouseTypePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic for unknown type")
+		}
+	}()
+	serializedTypeToClickhouseType("bool")
+}
+
+func TestFileDataSourceHeaders(t *testing.T) {
+	input := "tags,hostname string,rack int64\n" +
+		"cpu,usage_user,usage_system\n" +
+		"disk,free\n" +
+		"\n" +
+		"tags,hostname=host_0,rack=1\n" +
+		"cpu,1451606400000000000,1,2\n"
+	ds := &fileDataSource{scanner: bufio.NewScanner(strings.NewReader(input))}
+
+	headers := ds.Headers()
+	if !reflect.DeepEqual(headers.TagKeys, []string{"hostname", "rack"}) {
+		t.Errorf("incorrect tag keys: %v", headers.TagKeys)
+	}
+	if !reflect.DeepEqual(headers.TagTypes, []string{"string", "int64"}) {
+		t.Errorf("incorrect tag types: %v", headers.TagTypes)
+	}
+	wantFields := map[string][]string{"cpu": {"usage_user", "usage_system"}, "disk": {"free"}}
+	if !reflect.DeepEqual(headers.FieldKeys, wantFields) {
+		t.Errorf("incorrect field keys: %v", headers.FieldKeys)
+	}
+
+	p := ds.NextItem().Data.(*point)
+	if p.table != "cpu" || p.row.tags != "hostname=host_0,rack=1" || p.row.fields != "1451606400000000000,1,2" {
+		t.Errorf("incorrect point: %s %v", p.table, p.row)
+	}
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("expected no more items, got %v", item.Data)
+	}
+}
+
+func TestDBCreatorTrackPoint(t *testing.T) {
+	input := "tags,hostname string,rack int64\n" +
+		"cpu,usage_user\n" +
+		"\n" +
+		"tags,hostname=host_0,rack=1\n" +
+		"cpu,1451606400500000000,1\n" +
+		"tags,hostname=host_1,rack=1,extra=x\n" +
+		"cpu,1451606410000000000,1\n" +
+		"tags,hostname=host_0,rack=1,extra=y\n" +
+		"cpu,1451606420000000000,1\n"
+	ds := &fileDataSource{scanner: bufio.NewScanner(strings.NewReader(input))}
+	d := &dbCreator{conf: &ClickhouseConfig{}, ds: ds}
+	ds.Headers()
+
+	stats := targets.NewDataStats()
+	for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+		d.TrackPoint(item, stats)
+	}
+
+	want := targets.MeasurementStats{
+		Rows:    3,
+		MinTime: time.Unix(1451606400, 0),
+		MaxTime: time.Unix(1451606420, 0),
+		TagSets: 2,
+	}
+	if got := stats.Get("cpu"); got != want {
+		t.Errorf("incorrect stats: got %+v want %+v", got, want)
+	}
+}
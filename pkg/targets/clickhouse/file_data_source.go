@@ -0,0 +1,191 @@
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func newFileDataSource(fileName string, readAhead int) targets.DataSource {
+	br := load.GetRewindableReader(fileName, readAhead)
+	return &fileDataSource{reader: br, scanner: bufio.NewScanner(br)}
+}
+
+// tagsPrefix starts the line of tags of every row
+var tagsPrefix = []byte(tagsKey + ",")
+
+type fileDataSource struct {
+	reader  *load.RewindableReader
+	scanner *bufio.Scanner
+	headers *common.GeneratedDataHeaders
+	shift   data.TimeShift
+}
+
+func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
+	// headers are read from the input file, and should be read first
+	if d.headers != nil {
+		return d.headers
+	}
+	// First N lines are header, with the first line containing the tags
+	// and their names, the second through N-1 line containing the column
+	// names, and last line being blank to separate from the data
+	var tags string
+	var cols []string
+	i := 0
+	for {
+		var line string
+		ok := d.scanner.Scan()
+		if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+			fatal("ended too soon, no tags or cols read")
+			return nil
+		} else if !ok {
+			fatal("scan error: %v", d.scanner.Err())
+			return nil
+		}
+		if i == 0 {
+			tags = d.scanner.Text()
+			tags = strings.TrimSpace(tags)
+		} else {
+			line = d.scanner.Text()
+			line = strings.TrimSpace(line)
+			if len(line) == 0 {
+				break
+			}
+			cols = append(cols, line)
+		}
+		i++
+	}
+
+	tagsarr := strings.Split(tags, ",")
+	if tagsarr[0] != tagsKey {
+		fatal("input header in wrong format. got '%s', expected 'tags'", tagsarr[0])
+	}
+	tagNames, tagTypes := extractTagNamesAndTypes(tagsarr[1:])
+	fieldKeys := make(map[string][]string)
+	for _, tableDef := range cols {
+		columns := strings.Split(tableDef, ",")
+		tableName := columns[0]
+		colNames := columns[1:]
+		fieldKeys[tableName] = colNames
+	}
+	d.headers = &common.GeneratedDataHeaders{
+		TagTypes:  tagTypes,
+		TagKeys:   tagNames,
+		FieldKeys: fieldKeys,
+	}
+	return d.headers
+}
+
+func (d *fileDataSource) NextItem() data.LoadedPoint {
+	if d.headers == nil {
+		fatal("headers not read before starting to decode points")
+		return data.LoadedPoint{}
+	}
+	newPoint := &insertData{}
+	ok := d.scanner.Scan()
+	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+		if d.reader.NextFile() {
+			d.readHeadersAgain()
+			return d.NextItem()
+		}
+		return data.LoadedPoint{}
+	} else if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+
+	// The first line is a CSV line of tags with the first element being "tags"
+	parts := strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
+	prefix := parts[0]
+	if prefix != tagsKey {
+		fatal("data file in invalid format; got %s expected %s", prefix, tagsKey)
+		return data.LoadedPoint{}
+	}
+	newPoint.tags = parts[1]
+
+	// Scan again to get the data line
+	ok = d.scanner.Scan()
+	if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+	parts = strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
+	prefix = parts[0]
+	newPoint.fields = d.shiftFields(parts[1])
+
+	return data.NewLoadedPoint(&point{
+		table: prefix,
+		row:   newPoint,
+	})
+}
+
+// Shard reads only the rows of the shard-th of shards byte ranges of the data
+// file after its headers, if it is a single uncompressed file. Each row starts
+// with its line of tags.
+func (d *fileDataSource) Shard(shard, shards int) bool {
+	isStart := func(line []byte) bool { return bytes.HasPrefix(line, tagsPrefix) }
+	if !d.reader.ShardLines(shard, shards, load.LineShards{Header: true, IsStart: isStart}) {
+		return false
+	}
+	d.readHeadersAgain()
+	return true
+}
+
+// Rewind reads the file again, after its headers, with the timestamps shifted
+// past the ones read before
+func (d *fileDataSource) Rewind() bool {
+	if !d.shift.Next() || !d.reader.Rewind() {
+		return false
+	}
+	d.readHeadersAgain()
+	return true
+}
+
+// readHeadersAgain reads past the headers each data file starts with, once the
+// reader starts a file again or moves on to the next one
+func (d *fileDataSource) readHeadersAgain() {
+	d.scanner = bufio.NewScanner(d.reader)
+	d.headers = nil
+	d.Headers()
+}
+
+// shiftFields shifts the timestamp the fields of a row start with
+func (d *fileDataSource) shiftFields(fields string) string {
+	i := strings.IndexByte(fields, ',')
+	if i < 0 {
+		i = len(fields)
+	}
+	ns, err := strconv.ParseInt(fields[:i], 10, 64)
+	if err != nil {
+		fatal("invalid timestamp %q: %v", fields[:i], err)
+		return fields
+	}
+	shifted := d.shift.Shift(ns)
+	if shifted == ns {
+		return fields
+	}
+	return strconv.FormatInt(shifted, 10) + fields[i:]
+}
+
+// extractTagNamesAndTypes splits the "<name> <type>" entries of the tags
+// header line.
+func extractTagNamesAndTypes(tags []string) ([]string, []string) {
+	tagNames := make([]string, len(tags))
+	tagTypes := make([]string, len(tags))
+	for i, tagWithType := range tags {
+		tagAndType := strings.Split(tagWithType, " ")
+		if len(tagAndType) != 2 {
+			panic("tag header has invalid format")
+		}
+		tagNames[i] = tagAndType[0]
+		tagTypes[i] = tagAndType[1]
+	}
+
+	return tagNames, tagTypes
+}
@@ -0,0 +1,48 @@
+package clickhouse
+
+import (
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/timescaledb"
+)
+
+func NewTarget() targets.ImplementedTarget {
+	return &clickhouseTarget{}
+}
+
+type clickhouseTarget struct {
+}
+
+func (t *clickhouseTarget) TargetName() string {
+	return constants.FormatClickhouse
+}
+
+// Serializer returns the TimescaleDB serializer, ClickHouse uses the same
+// pseudo-CSV format.
+func (t *clickhouseTarget) Serializer() serialize.PointSerializer {
+	return &timescaledb.Serializer{}
+}
+
+func (t *clickhouseTarget) Benchmark(
+	targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper,
+) (targets.Benchmark, error) {
+	var config ClickhouseConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+	config.DbName = targetDB
+	return NewBenchmark(&config, dataSourceConfig)
+}
+
+func (t *clickhouseTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"host", "localhost", "Hostname of ClickHouse instance")
+	flagSet.String(flagPrefix+"port", "9000", "Port of the ClickHouse native protocol")
+	flagSet.String(flagPrefix+"user", "default", "User to connect to ClickHouse as")
+	flagSet.String(flagPrefix+"password", "", "Password to connect to ClickHouse")
+	flagSet.Bool(flagPrefix+"use-tags", true, "Whether tags should be stored in a separate tags table (instead of in every row)")
+	flagSet.Int(flagPrefix+"debug", 0, "Debug printing (choices: 0, 1, 2). (default 0)")
+}
@@ -0,0 +1,312 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kshvakov/clickhouse"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const insertSQL = "INSERT INTO %s(%s) VALUES (%s)"
+
+// tagIndex tracks the tag sets stored in the tags table. The id of a tag set
+// is the hash of its tags, so all loads into the database, e.g. the agents of
+// a coordinator or a load resumed from a checkpoint, store it under the same
+// id. It is shared by all workers so that every tag set is inserted once by
+// this load, even when the data is not hashed to the workers.
+type tagIndex struct {
+	mutex    sync.Mutex
+	loadOnce sync.Once
+	// stored is true for the ids committed to the tags table and false for
+	// the ones being inserted
+	stored map[uint64]bool
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{stored: make(map[uint64]bool)}
+}
+
+// tagSetID returns the id of the tag set with the given key
+func tagSetID(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// load adds the tag sets already in the tags table, once for all workers
+func (t *tagIndex) load(db *sql.DB) {
+	t.loadOnce.Do(func() {
+		rows, err := db.Query(fmt.Sprintf("SELECT DISTINCT id FROM %s", tagsTable))
+		if err != nil {
+			fatal("could not read existing tag sets: %v", err)
+			return
+		}
+		defer rows.Close()
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+		for rows.Next() {
+			var id uint64
+			if err := rows.Scan(&id); err != nil {
+				fatal("could not read existing tag sets: %v", err)
+				return
+			}
+			t.stored[id] = true
+		}
+	})
+}
+
+// getOrAdd returns the id of the given tag set and whether the caller has to
+// insert it, in which case it is reserved for the caller until it reports
+// the outcome with inserted.
+func (t *tagIndex) getOrAdd(key string) (uint64, bool) {
+	id := tagSetID(key)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if _, ok := t.stored[id]; ok {
+		return id, false
+	}
+	t.stored[id] = false
+	return id, true
+}
+
+// inserted marks the tag sets reserved by getOrAdd as stored once their
+// insert committed, or releases them if it failed, so a later batch inserts
+// them again
+func (t *tagIndex) inserted(ids []uint64, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for _, id := range ids {
+		if err != nil {
+			delete(t.stored, id)
+		} else {
+			t.stored[id] = true
+		}
+	}
+}
+
+type processor struct {
+	conf    *ClickhouseConfig
+	ds      targets.DataSource
+	tags    *tagIndex
+	headers *common.GeneratedDataHeaders
+	db      *sql.DB
+}
+
+func (p *processor) Init(_ int, doLoad, _ bool) {
+	p.headers = p.ds.Headers()
+	if !doLoad {
+		return
+	}
+	p.db = mustConnect(p.conf.getConnectString(p.conf.DbName))
+	if p.conf.UseTags {
+		p.tags.load(p.db)
+	}
+}
+
+func (p *processor) Close(doLoad bool) {
+	if doLoad {
+		p.db.Close()
+	}
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	res := p.ProcessBatchWithResult(b, doLoad)
+	if res.Err != nil {
+		fatal("%v", res.Err)
+	}
+	return res.Metrics, res.Rows
+}
+
+// ProcessBatchWithResult inserts the rows of each table in its own
+// transaction, so when an insert fails only the rows of that table are failed
+func (p *processor) ProcessBatchWithResult(b targets.Batch, doLoad bool) targets.BatchResult {
+	batch := b.(*tableArr)
+	var res targets.BatchResult
+	for tableName, rows := range batch.m {
+		metricCnt, err := p.processTable(tableName, rows, doLoad)
+		if err != nil {
+			res.Fail(metricCnt, uint64(len(rows)), err, classifyError(err))
+			continue
+		}
+		res.Metrics += metricCnt
+		res.Rows += uint64(len(rows))
+	}
+	return res
+}
+
+// classifyError tells exceptions raised by the server, which reject the
+// insert, from errors of the connection
+func classifyError(err error) targets.ErrorClass {
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return targets.ErrorClassRejected
+	}
+	return targets.ClassifyError(err)
+}
+
+// processTable converts the rows of a single table and, if doLoad is set,
+// inserts them together with any tag sets not seen before. It returns the
+// number of metrics in the rows and the error of the insert, if any.
+func (p *processor) processTable(tableName string, rows []*insertData, doLoad bool) (uint64, error) {
+	tagNames, tagTypes := p.headers.TagKeys, p.headers.TagTypes
+	fieldKeys := p.headers.FieldKeys[tableName]
+
+	var newTags [][]interface{}
+	var newIDs []uint64
+	dataRows := make([][]interface{}, 0, len(rows))
+	numMetrics := uint64(0)
+	for _, row := range rows {
+		tagKey, tagValues, additionalTags := splitTags(row.tags, tagNames, tagTypes)
+		ts, fieldValues, cnt := parseFields(row.fields, len(fieldKeys))
+		numMetrics += cnt
+
+		values := make([]interface{}, 0, len(tagNames)+len(fieldKeys)+3)
+		values = append(values, ts)
+		if p.conf.UseTags {
+			id, isNew := p.tags.getOrAdd(tagKey)
+			if isNew {
+				newTags = append(newTags, append([]interface{}{id}, tagValues...))
+				newIDs = append(newIDs, id)
+			}
+			values = append(values, id)
+		} else {
+			values = append(values, tagValues...)
+		}
+		values = append(values, fieldValues...)
+		values = append(values, additionalTags)
+		dataRows = append(dataRows, values)
+	}
+
+	if doLoad {
+		if len(newTags) > 0 {
+			err := insertRows(p.db, tagsTable, append([]string{"id"}, tagNames...), newTags)
+			p.tags.inserted(newIDs, err)
+			if err != nil {
+				return numMetrics, err
+			}
+		}
+		if err := insertRows(p.db, tableName, dataColumns(fieldKeys, tagNames, p.conf.UseTags), dataRows); err != nil {
+			return numMetrics, err
+		}
+	}
+	return numMetrics, nil
+}
+
+// dataColumns returns the columns of a measurement table in the order the
+// processor fills them in.
+func dataColumns(fieldKeys, tagNames []string, useTags bool) []string {
+	cols := []string{"time"}
+	if useTags {
+		cols = append(cols, "tags_id")
+	} else {
+		cols = append(cols, tagNames...)
+	}
+	cols = append(cols, fieldKeys...)
+	return append(cols, additionalTagsCol)
+}
+
+// insertRows inserts all rows in a single block, which is rolled back when
+// any of them can't be inserted.
+func insertRows(db *sql.DB, tableName string, cols []string, rows [][]interface{}) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(cols)), ",")
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin insert into %s: %w", tableName, err)
+	}
+	stmt, err := tx.Prepare(fmt.Sprintf(insertSQL, tableName, strings.Join(cols, ","), placeholders))
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("could not prepare insert into %s: %w", tableName, err)
+	}
+	defer stmt.Close()
+	for _, row := range rows {
+		if _, err := stmt.Exec(row...); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("could not insert into %s: %w", tableName, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit insert into %s: %w", tableName, err)
+	}
+	return nil
+}
+
+// splitTags splits a tags line of the form <tag>=<value>,... into the key
+// identifying the tag set, the values of the common tags converted to their
+// column types and the remaining, non-common tags.
+func splitTags(tags string, tagNames, tagTypes []string) (string, []interface{}, string) {
+	parts := strings.SplitN(tags, ",", len(tagNames)+1)
+	values := make([]interface{}, len(tagNames))
+	for i := range tagNames {
+		var value string
+		if i < len(parts) {
+			value = parts[i][strings.Index(parts[i], "=")+1:]
+		}
+		values[i] = convertTagValue(value, tagTypes[i])
+	}
+	if len(parts) > len(tagNames) {
+		return tags[:len(tags)-len(parts[len(tagNames)])-1], values, parts[len(tagNames)]
+	}
+	return tags, values, ""
+}
+
+func convertTagValue(value, serializedType string) interface{} {
+	if serializedType == serializedTypeText {
+		return value
+	}
+	if value == "" {
+		return nil
+	}
+	var v interface{}
+	var err error
+	switch serializedType {
+	case "float32", "float64":
+		v, err = strconv.ParseFloat(value, 64)
+	case "int32", "int64":
+		v, err = strconv.ParseInt(value, 10, 64)
+	default:
+		panic(fmt.Sprintf("unrecognized type %s", serializedType))
+	}
+	if err != nil {
+		fatal("cannot parse tag value %s as %s: %v", value, serializedType, err)
+		return nil
+	}
+	if serializedType == "int32" {
+		return int32(v.(int64))
+	}
+	return v
+}
+
+// parseFields parses a fields line of the form <unix-nano>,<value>,... and
+// returns the timestamp, the field values and the number of non-empty values.
+func parseFields(fields string, numFields int) (time.Time, []interface{}, uint64) {
+	parts := strings.Split(fields, ",")
+	ns, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		fatal("cannot parse timestamp %s: %v", parts[0], err)
+		return time.Time{}, nil, 0
+	}
+	values := make([]interface{}, numFields)
+	numMetrics := uint64(0)
+	for i := 0; i < numFields && i+1 < len(parts); i++ {
+		if parts[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(parts[i+1], 64)
+		if err != nil {
+			fatal("cannot parse field value %s: %v", parts[i+1], err)
+			return time.Time{}, nil, 0
+		}
+		values[i] = v
+		numMetrics++
+	}
+	return time.Unix(0, ns).UTC(), values, numMetrics
+}
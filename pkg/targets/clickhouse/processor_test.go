@@ -0,0 +1,183 @@
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kshvakov/clickhouse"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestSplitTags(t *testing.T) {
+	tagNames := []string{"hostname", "rack", "load"}
+	tagTypes := []string{"string", "int32", "float64"}
+	cases := []struct {
+		desc           string
+		tags           string
+		wantKey        string
+		wantValues     []interface{}
+		wantAdditional string
+	}{
+		{
+			desc:       "common tags",
+			tags:       "hostname=host_0,rack=3,load=1.5",
+			wantKey:    "hostname=host_0,rack=3,load=1.5",
+			wantValues: []interface{}{"host_0", int32(3), 1.5},
+		},
+		{
+			desc:       "missing values",
+			tags:       "hostname=,rack=,load=",
+			wantKey:    "hostname=,rack=,load=",
+			wantValues: []interface{}{"", nil, nil},
+		},
+		{
+			desc:           "additional tags",
+			tags:           "hostname=host_1,rack=4,load=2,extra=foo,other=bar",
+			wantKey:        "hostname=host_1,rack=4,load=2",
+			wantValues:     []interface{}{"host_1", int32(4), 2.0},
+			wantAdditional: "extra=foo,other=bar",
+		},
+	}
+	for _, c := range cases {
+		key, values, additional := splitTags(c.tags, tagNames, tagTypes)
+		if key != c.wantKey {
+			t.Errorf("%s: incorrect key: got %s want %s", c.desc, key, c.wantKey)
+		}
+		if !reflect.DeepEqual(values, c.wantValues) {
+			t.Errorf("%s: incorrect values: got %v want %v", c.desc, values, c.wantValues)
+		}
+		if additional != c.wantAdditional {
+			t.Errorf("%s: incorrect additional tags: got %s want %s", c.desc, additional, c.wantAdditional)
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	ts, values, cnt := parseFields("1451606400000000000,1.5,,3", 3)
+	if !ts.Equal(time.Unix(1451606400, 0)) {
+		t.Errorf("incorrect timestamp: %v", ts)
+	}
+	if !reflect.DeepEqual(values, []interface{}{1.5, nil, 3.0}) {
+		t.Errorf("incorrect values: %v", values)
+	}
+	if cnt != 2 {
+		t.Errorf("incorrect metric count: got %d want 2", cnt)
+	}
+}
+
+func TestParseFieldsInvalidTimestamp(t *testing.T) {
+	oldFatal := fatal
+	defer func() { fatal = oldFatal }()
+	isCalled := false
+	fatal = func(format string, args ...interface{}) {
+		isCalled = true
+	}
+	parseFields("abc,1", 1)
+	if !isCalled {
+		t.Errorf("fatal not called for invalid timestamp")
+	}
+}
+
+func TestTagIndex(t *testing.T) {
+	idx := newTagIndex()
+	wg := sync.WaitGroup{}
+	newCnt := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cnt := 0
+			for _, key := range []string{"a", "b", "c"} {
+				if _, isNew := idx.getOrAdd(key); isNew {
+					cnt++
+				}
+			}
+			newCnt <- cnt
+		}()
+	}
+	wg.Wait()
+	close(newCnt)
+	total := 0
+	for c := range newCnt {
+		total += c
+	}
+	if total != 3 {
+		t.Errorf("tag sets added %d times, want 3", total)
+	}
+	if id, isNew := idx.getOrAdd("a"); isNew || id != tagSetID("a") {
+		t.Errorf("incorrect id for existing tag set: %d %v", id, isNew)
+	}
+}
+
+func TestTagIndexInserted(t *testing.T) {
+	idx := newTagIndex()
+	a, _ := idx.getOrAdd("a")
+	b, _ := idx.getOrAdd("b")
+	idx.inserted([]uint64{a}, nil)
+	idx.inserted([]uint64{b}, errors.New("insert failed"))
+	if _, isNew := idx.getOrAdd("a"); isNew {
+		t.Errorf("committed tag set added again")
+	}
+	// the tag set of the failed insert is inserted with a later batch
+	if _, isNew := idx.getOrAdd("b"); !isNew {
+		t.Errorf("tag set of a failed insert not added again")
+	}
+	if tagSetID("a") == tagSetID("b") || tagSetID("a") != a {
+		t.Errorf("tag set ids not derived from the tags")
+	}
+}
+
+type testDataSource struct {
+	headers *common.GeneratedDataHeaders
+}
+
+func (d *testDataSource) NextItem() data.LoadedPoint            { return data.LoadedPoint{} }
+func (d *testDataSource) Headers() *common.GeneratedDataHeaders { return d.headers }
+
+func TestProcessBatchNoLoad(t *testing.T) {
+	ds := &testDataSource{headers: &common.GeneratedDataHeaders{
+		TagKeys:   []string{"hostname"},
+		TagTypes:  []string{"string"},
+		FieldKeys: map[string][]string{"cpu": {"usage_user", "usage_system"}, "mem": {"used"}},
+	}}
+	for _, useTags := range []bool{true, false} {
+		p := &processor{conf: &ClickhouseConfig{UseTags: useTags}, ds: ds, tags: newTagIndex()}
+		p.Init(0, false, false)
+
+		b := (&factory{}).New()
+		b.Append(data.NewLoadedPoint(&point{table: "cpu", row: &insertData{tags: "hostname=host_0", fields: "0,1,2"}}))
+		b.Append(data.NewLoadedPoint(&point{table: "cpu", row: &insertData{tags: "hostname=host_1", fields: "0,1,"}}))
+		b.Append(data.NewLoadedPoint(&point{table: "mem", row: &insertData{tags: "hostname=host_0", fields: "0,5"}}))
+
+		metricCnt, rowCnt := p.ProcessBatch(b, false)
+		if metricCnt != 4 {
+			t.Errorf("use-tags %v: incorrect metric count: got %d want 4", useTags, metricCnt)
+		}
+		if rowCnt != 3 {
+			t.Errorf("use-tags %v: incorrect row count: got %d want 3", useTags, rowCnt)
+		}
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want targets.ErrorClass
+	}{
+		{err: fmt.Errorf("could not insert into cpu: %w", &clickhouse.Exception{Code: 53, Message: "type mismatch"}), want: targets.ErrorClassRejected},
+		{err: fmt.Errorf("could not begin insert into cpu: %w", io.EOF), want: targets.ErrorClassConnection},
+		{err: errors.New("unknown"), want: targets.ErrorClassOther},
+	}
+	for _, c := range cases {
+		if got := classifyError(c.err); got != c.want {
+			t.Errorf("incorrect class for %v: got %s want %s", c.err, got, c.want)
+		}
+	}
+}
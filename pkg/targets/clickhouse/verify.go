@@ -0,0 +1,57 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const verifySQLFmt = "SELECT count(), toInt64(toUnixTimestamp(min(time))), toInt64(toUnixTimestamp(max(time))), uniqExact(%s) FROM %s"
+
+// TrackPoint adds the row of the point to stats, with the tags of the tags
+// table, i.e. without the additional tags, as its tag set.
+func (d *dbCreator) TrackPoint(p data.LoadedPoint, stats *targets.DataStats) {
+	pt := p.Data.(*point)
+	headers := d.ds.Headers()
+	tagKey, _, _ := splitTags(pt.row.tags, headers.TagKeys, headers.TagTypes)
+	timeStr := strings.SplitN(pt.row.fields, ",", 2)[0]
+	ns, err := strconv.ParseInt(timeStr, 10, 64)
+	if err != nil {
+		fatal("cannot parse timestamp %s: %v", timeStr, err)
+		return
+	}
+	// DateTime columns have a precision of seconds
+	stats.Add(pt.table, time.Unix(0, ns).Truncate(time.Second), tagKey)
+}
+
+// Verify returns the number of rows, the time range and the number of tag
+// sets of each table
+func (d *dbCreator) Verify(dbName string, measurements []string) (map[string]targets.MeasurementStats, error) {
+	db, err := sql.Open(driver, d.conf.getConnectString(dbName))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	tagSet := "tags_id"
+	if !d.conf.UseTags {
+		tagSet = strings.Join(d.ds.Headers().TagKeys, ", ")
+	}
+	stats := make(map[string]targets.MeasurementStats, len(measurements))
+	for _, table := range measurements {
+		var s targets.MeasurementStats
+		var minTime, maxTime int64
+		err := db.QueryRow(fmt.Sprintf(verifySQLFmt, tagSet, table)).Scan(&s.Rows, &minTime, &maxTime, &s.TagSets)
+		if err != nil {
+			return nil, fmt.Errorf("could not verify table %s: %v", table, err)
+		}
+		s.MinTime, s.MaxTime = time.Unix(minTime, 0), time.Unix(maxTime, 0)
+		stats[table] = s
+	}
+	return stats, nil
+}
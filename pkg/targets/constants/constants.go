@@ -5,6 +5,10 @@ const (
 	FormatInflux          = "influx"
 	FormatTimescaleDB     = "timescaledb"
 	FormatDatalayers      = "datalayers"
+	FormatPrometheus      = "prometheus"
+	FormatClickhouse      = "clickhouse"
+	FormatQuestDB         = "questdb"
+	FormatVictoriaMetrics = "victoriametrics"
 )
 
 func SupportedFormats() []string {
@@ -12,5 +16,9 @@ func SupportedFormats() []string {
 		FormatInflux,
 		FormatTimescaleDB,
 		FormatDatalayers,
+		FormatPrometheus,
+		FormatClickhouse,
+		FormatQuestDB,
+		FormatVictoriaMetrics,
 	}
 }
@@ -34,3 +34,23 @@ type DBCreatorPost interface {
 	// PostCreateDB does further initialization after the database is created
 	PostCreateDB(dbName string) error
 }
+
+// DBCreatorPostLoad is a DBCreator that also does some work on the database
+// after all the data is loaded (e.g., compressing it). The work is not part of
+// the measured load time; its results are added to the summary and to the
+// totals of the results file.
+type DBCreatorPostLoad interface {
+	DBCreator
+
+	// PostLoad runs after all workers are done and returns its results by name
+	PostLoad(dbName string) (map[string]interface{}, error)
+}
+
+// StorageReporter is a DBCreator that can report how much space the loaded data
+// takes on disk, so the cost of storing it can be compared across targets.
+type StorageReporter interface {
+	DBCreator
+
+	// StorageBytes returns the number of bytes the database takes on disk
+	StorageBytes(dbName string) (int64, error)
+}
@@ -13,6 +13,8 @@ type DatalayersConfig struct {
 	SqlEndpoint string `yaml:"sql-endpoint" mapstructure:"sql-endpoint"`
 	BatchSize   uint   `yaml:"batch-size" mapstructure:"batch-size"`
 	NumWorkers  int64  `yaml:"num-workers" mapstructure:"num-workers"`
+	// StorageSizeSQL is the query of the size of the database on disk
+	StorageSizeSQL string `yaml:"storage-size-sql" mapstructure:"storage-size-sql"`
 }
 
 // Wraps the context used during a benchmark.
@@ -65,5 +67,5 @@ func (b *benchmark) GetProcessor() targets.Processor {
 
 // GetDBCreator returns the DBCreator to use for this Benchmark
 func (b *benchmark) GetDBCreator() targets.DBCreator {
-	return NewDBCreator(b.datalayersClient)
+	return withStorageSize(NewDBCreator(b.datalayersClient), b.datalayersConfig.StorageSizeSQL)
 }
@@ -3,6 +3,7 @@ package datalayers
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/apache/arrow/go/v16/arrow"
@@ -174,6 +175,51 @@ func (clt *Client) ExecuteQuery(query string, doPrintResponses bool) error {
 	return nil
 }
 
+// QueryInt64 executes a query returning a single integer, e.g. a count or a
+// sum, and returns the value of the first column of the first row.
+func (clt *Client) QueryInt64(query string) (int64, error) {
+	values, err := clt.QueryInt64s(query)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// QueryInt64s executes a query returning integers and returns the values of
+// all columns of the first row. NULL values are returned as 0.
+func (clt *Client) QueryInt64s(query string) ([]int64, error) {
+	flightInfo, err := clt.inner.Execute(clt.ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	flightReader, err := clt.inner.DoGet(clt.ctx, flightInfo.GetEndpoint()[0].GetTicket())
+	if err != nil {
+		return nil, err
+	}
+	defer flightReader.Release()
+
+	for flightReader.Next() {
+		record := flightReader.Record()
+		if record.NumRows() == 0 || record.NumCols() == 0 {
+			continue
+		}
+		values := make([]int64, record.NumCols())
+		for i, col := range record.Columns() {
+			if col.IsNull(0) {
+				continue
+			}
+			if values[i], err = strconv.ParseInt(col.ValueStr(0), 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	}
+	if err := flightReader.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("query returned no rows: %s", query)
+}
+
 func arrowDataTypeToDatalayersDataType(arrowDataType arrow.DataType) string {
 	switch arrowDataType {
 	case arrow.FixedWidthTypes.Boolean:
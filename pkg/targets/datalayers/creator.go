@@ -6,19 +6,33 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/timescale/tsbs/pkg/targets"
 	datalayers "github.com/timescale/tsbs/pkg/targets/datalayers/client"
 )
 
+// storageSizeDatabase is replaced by the name of the database in the
+// storage-size-sql query
+const storageSizeDatabase = "{database}"
+
+// sqlClient is the part of the Datalayers client used by the DBCreator
+type sqlClient interface {
+	CreateDatabase(dbName string) error
+	QueryInt64(query string) (int64, error)
+	QueryInt64s(query string) ([]int64, error)
+}
+
 // DBCreator is an interface for a benchmark to do the initial setup of a database
 // in preparation for running a benchmark against it.
 //
 // Datalayers' implementation of the DBCreator interface.
 type dBCreator struct {
-	client *datalayers.Client
+	client sqlClient
+	// tracker stitches the lines split across sub files when verifying
+	tracker lineTracker
 }
 
 func NewDBCreator(client *datalayers.Client) *dBCreator {
-	return &dBCreator{client}
+	return &dBCreator{client: client}
 }
 
 // Init should set up any connection or other setup for talking to the DB, but should NOT create any databases
@@ -71,3 +85,28 @@ func (dc *dBCreator) PostCreateDB(dbName string) error {
 	// Not implemented.
 	return nil
 }
+
+// storageDBCreator is a dBCreator that reports the size of the database on
+// disk with the storage-size-sql query
+type storageDBCreator struct {
+	*dBCreator
+	storageSizeSQL string
+}
+
+// withStorageSize returns dc, reporting the size of the database on disk if a
+// storage-size-sql query is set
+func withStorageSize(dc *dBCreator, storageSizeSQL string) targets.DBCreator {
+	if storageSizeSQL == "" {
+		return dc
+	}
+	return &storageDBCreator{dBCreator: dc, storageSizeSQL: storageSizeSQL}
+}
+
+// StorageBytes returns the first column of the row returned by the
+// storage-size-sql query, run with {database} replaced by dbName. No system
+// table of Datalayers is known to hold the size of the files of a database,
+// so the query is left to the user, e.g. one over the system tables of the
+// Datalayers version loaded into.
+func (dc *storageDBCreator) StorageBytes(dbName string) (int64, error) {
+	return dc.client.QueryInt64(strings.ReplaceAll(dc.storageSizeSQL, storageSizeDatabase, dbName))
+}
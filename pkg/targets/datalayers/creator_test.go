@@ -0,0 +1,58 @@
+package datalayers
+
+import (
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// fakeClient records the queries run, returning size to each of them
+type fakeClient struct {
+	queries []string
+	size    int64
+}
+
+func (c *fakeClient) CreateDatabase(dbName string) error {
+	c.queries = append(c.queries, "create database "+dbName)
+	return nil
+}
+
+func (c *fakeClient) QueryInt64(query string) (int64, error) {
+	c.queries = append(c.queries, query)
+	return c.size, nil
+}
+
+func (c *fakeClient) QueryInt64s(query string) ([]int64, error) {
+	c.queries = append(c.queries, query)
+	return []int64{c.size}, nil
+}
+
+func TestDBCreatorStorageBytes(t *testing.T) {
+	client := &fakeClient{size: 1024}
+	dc := withStorageSize(&dBCreator{client: client}, "SELECT SUM(size) FROM sizes WHERE db = '{database}'")
+	sr, ok := dc.(targets.StorageReporter)
+	if !ok {
+		t.Fatalf("DBCreator with a storage-size-sql query is not a StorageReporter")
+	}
+	if _, ok := dc.(targets.Verifier); !ok {
+		t.Errorf("DBCreator with a storage-size-sql query is not a Verifier")
+	}
+	got, err := sr.StorageBytes("benchmark")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 1024 {
+		t.Errorf("incorrect storage bytes: got %d want %d", got, 1024)
+	}
+	want := "SELECT SUM(size) FROM sizes WHERE db = 'benchmark'"
+	if len(client.queries) != 1 || client.queries[0] != want {
+		t.Errorf("incorrect queries: got %q want %q", client.queries, want)
+	}
+}
+
+func TestDBCreatorStorageBytesNoQuery(t *testing.T) {
+	dc := withStorageSize(&dBCreator{client: &fakeClient{}}, "")
+	if _, ok := dc.(targets.StorageReporter); ok {
+		t.Errorf("DBCreator without a storage-size-sql query is a StorageReporter")
+	}
+}
@@ -19,6 +19,7 @@ func (t *datalayersTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag
 	flagSet.String(flagPrefix+"sql-endpoint", "127.0.0.1:8360", "Datalayers' Arrow FlightSql endpoint")
 	flagSet.Uint(flagPrefix+"batch-size", 1250, "The number of rows being sent to the Datalayers server in a row")
 	flagSet.Uint(flagPrefix+"num-workers", 32, "The number of processors")
+	flagSet.String(flagPrefix+"storage-size-sql", "", "Query returning the bytes the database takes on disk after the load, with {database} for its name. The size is not reported if empty")
 }
 
 func (t *datalayersTarget) TargetName() string {
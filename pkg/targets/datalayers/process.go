@@ -18,6 +18,8 @@ import (
 	"github.com/apache/arrow/go/v16/arrow/array"
 	"github.com/apache/arrow/go/v16/arrow/flight/flightsql"
 	"github.com/apache/arrow/go/v16/arrow/memory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var cpuFieldNames []string = []string{
@@ -104,22 +106,38 @@ func (proc *processor) Init(workerNum int, doLoad, hashWorkers bool) {
 // If doLoad is true, the processor will load the data batch to the Datalayers server.
 // If doLoad is false, no data loading will be performed. Only data parsing and buffering would be performed.
 func (proc *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, rowCount uint64) {
+	res := proc.ProcessBatchWithResult(b, doLoad)
+	if res.Err != nil {
+		log.Error(res.Err)
+	}
+	// Segments that failed to be inserted are only logged.
+	return res.Metrics + res.FailedMetrics, res.Rows + res.FailedRows
+}
+
+// ProcessBatchWithResult handles a single batch of data like ProcessBatch,
+// but reports the rows of the segments that failed to be inserted as failed.
+func (proc *processor) ProcessBatchWithResult(b targets.Batch, doLoad bool) (res targets.BatchResult) {
 	batch := b.(*batch)
-	startOffset := batch.subFile[0]
-	endOffset := batch.subFile[1]
+	buffer := batch.lines
+	var offset int64
+	if buffer == nil {
+		offset = batch.subFile[2]
+		startOffset := batch.subFile[0]
+		endOffset := batch.subFile[1]
 
-	// fmt.Printf("Processor %v is reading sub file in range [%v, %v)\n", proc.id, startOffset, endOffset)
+		// fmt.Printf("Processor %v is reading sub file in range [%v, %v)\n", proc.id, startOffset, endOffset)
 
-	buffer := make([]byte, endOffset-startOffset)
-	bytesRead, err := DataSourceFile.ReadAt(buffer, startOffset)
-	if err != nil {
-		if err == io.EOF {
-			return metricCount, rowCount
+		buffer = make([]byte, endOffset-startOffset)
+		bytesRead, err := DataSourceFile.ReadAt(buffer, startOffset)
+		if err != nil {
+			if err == io.EOF {
+				return res
+			}
+			panic(fmt.Sprintf("failed to read sub file. error: %v", err))
+		}
+		if int64(bytesRead) != endOffset-startOffset {
+			panic(fmt.Sprintf("error on reading sub file. read bytes = %v, expected = %v", bytesRead, endOffset-startOffset))
 		}
-		panic(fmt.Sprintf("failed to read sub file. error: %v", err))
-	}
-	if int64(bytesRead) != endOffset-startOffset {
-		panic(fmt.Sprintf("error on reading sub file. read bytes = %v, expected = %v", bytesRead, endOffset-startOffset))
 	}
 
 	lines := strings.Split(string(buffer), "\n")
@@ -137,6 +155,9 @@ func (proc *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount,
 			if len(values) != len(cpuFieldNames) {
 				continue
 			}
+			if offset != 0 {
+				values[0] = shiftTimestamp(values[0], offset)
+			}
 			appendRow(proc.arrowRecordBuilder, values)
 		}
 
@@ -145,22 +166,49 @@ func (proc *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount,
 
 			// Datalayers does not differentiate between tags and fields, all columns are regarded as metrics.
 			// FIXME(niebayes): seems we need to modify the calculation of the number of metrics.
-			metricCount += uint64(record.NumCols() * record.NumRows())
-			rowCount += uint64(record.NumRows())
+			metricCount := uint64(record.NumCols() * record.NumRows())
+			rowCount := uint64(record.NumRows())
 
+			var err error
 			if doLoad {
 				proc.preparedStatement.SetParameters(record)
-				err := proc.client.ExecuteInsertPrepare(proc.preparedStatement)
-				if err != nil {
-					log.Error(err)
-					// panic(fmt.Sprintf("failed to execute a insert prepared statement. error: %v", err))
-				}
+				err = proc.client.ExecuteInsertPrepare(proc.preparedStatement)
+			}
+			if err != nil {
+				res.Fail(metricCount, rowCount, err, classifyError(err))
+			} else {
+				res.Metrics += metricCount
+				res.Rows += rowCount
 			}
 			record.Release()
 		}
 	}
 
-	return metricCount, rowCount
+	return res
+}
+
+// shiftTimestamp shifts the timestamp value of a row by offset nanoseconds,
+// for a pass of --loop after the first one.
+func shiftTimestamp(value string, offset int64) string {
+	ts, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatInt(ts+offset, 10)
+}
+
+// classifyError classifies the gRPC status of a failed insert.
+func classifyError(err error) targets.ErrorClass {
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return targets.ErrorClassConnection
+	case codes.DeadlineExceeded:
+		return targets.ErrorClassTimeout
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.AlreadyExists, codes.NotFound,
+		codes.OutOfRange, codes.ResourceExhausted, codes.PermissionDenied, codes.Unauthenticated:
+		return targets.ErrorClassRejected
+	}
+	return targets.ClassifyError(err)
 }
 
 func appendRow(arrowRecordBuilder *array.RecordBuilder, values []string) {
@@ -1,14 +1,19 @@
 package datalayers
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 
 	// "log"
 
 	"os"
+	"strconv"
 
 	// "time"
 
+	"github.com/timescale/tsbs/internal/datafile"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
@@ -26,15 +31,58 @@ var HostTags map[string][]string
 // The scanner also maintains a batch for each channel to buffer scanned data points.
 // To determine which channel should a data point go to, we use the point indexer to
 // set the index of channels for each data point and send the data point to the corresponding channel.
+//
+// A single uncompressed data file is split into byte ranges, the sub files,
+// which the processors read from the file themselves. Compressed files, or a
+// directory or glob of files, can't be read at an offset, so they are read by
+// the data source and sent to the processors in chunks of complete lines.
+
+// chunkSize is the size of the chunks of lines read from compressed files
+const chunkSize = 4 << 20
 
 type dataSource struct {
-	subFiles [][]int64
-	cursor   int
+	subFiles      [][]int64
+	cursor        int
+	fileSize      int64
+	numProcessors int64
+
+	// files are the data files read in chunks of lines, if they are not a
+	// single uncompressed file
+	files  *datafile.Files
+	reader *bufio.Reader
+	// an agent loads every shards-th chunk, starting at chunk shard
+	chunks uint64
+	shard  uint64
+	shards uint64
+
+	// shift shifts the timestamps of the passes over the data after the
+	// first one, for --loop
+	shift   data.TimeShift
+	scanned bool
 }
 
 // Creates a new file data source.
 func NewDataSource(fileName string, numProcessors int64) targets.DataSource {
-	file, err := os.Open(fileName)
+	paths, err := datafile.Paths(fileName)
+	if err != nil {
+		panic(fmt.Sprintf("failed to find data files %v. error: %v", fileName, err))
+	}
+	format := ""
+	if len(paths) == 1 {
+		if format, err = datafile.Format(paths[0]); err != nil {
+			panic(fmt.Sprintf("failed to open file %v. error: %v", paths[0], err))
+		}
+	}
+	if len(paths) > 1 || format != "" {
+		files, err := datafile.OpenFiles(fileName, 0)
+		if err != nil {
+			panic(fmt.Sprintf("failed to open data files %v. error: %v", fileName, err))
+		}
+		fmt.Printf("Read %v data files in chunks of lines\n", len(paths))
+		return &dataSource{files: files, reader: bufio.NewReaderSize(files, chunkSize)}
+	}
+
+	file, err := os.Open(paths[0])
 	if err != nil {
 		panic(fmt.Sprintf("failed to open file %v. error: %v", fileName, err))
 	}
@@ -46,32 +94,62 @@ func NewDataSource(fileName string, numProcessors int64) targets.DataSource {
 	fileSize := fileInfo.Size()
 	// fmt.Printf("The file size is %v\n", fileSize)
 
-	chunkSize := (fileSize + numProcessors - 1) / numProcessors
+	DataSourceFile = file
+	if DataSourceFile == nil {
+		panic("The DataSourceFile cannot be nil")
+	}
+
+	ds := &dataSource{cursor: 0, fileSize: fileSize, numProcessors: numProcessors}
+	ds.split(0, fileSize)
+	return ds
+}
+
+// Splits the range [start, end) of the file into a sub file for each processor.
+func (ds *dataSource) split(start, end int64) {
+	chunkSize := (end - start + ds.numProcessors - 1) / ds.numProcessors
 	// fmt.Printf("The chunk size is %v\n", chunkSize)
 
-	subFiles := make([][]int64, 0, numProcessors)
-	for i := int64(0); i < numProcessors; i++ {
-		startOffset := i * chunkSize
-		endOffset := min(startOffset+chunkSize, fileSize)
+	subFiles := make([][]int64, 0, ds.numProcessors)
+	for i := int64(0); i < ds.numProcessors; i++ {
+		startOffset := min(start+i*chunkSize, end)
+		endOffset := min(startOffset+chunkSize, end)
 
 		// fmt.Printf("The range of chunk %v is [%v,%v)\n", i, startOffset, endOffset)
 
 		subFiles = append(subFiles, []int64{startOffset, endOffset})
 	}
 
-	fmt.Printf("Create %v sub files each of at most length %v for %v processors\n", len(subFiles), chunkSize, numProcessors)
+	fmt.Printf("Create %v sub files each of at most length %v for %v processors\n", len(subFiles), chunkSize, ds.numProcessors)
+	ds.subFiles = subFiles
+}
 
-	DataSourceFile = file
-	if DataSourceFile == nil {
-		panic("The DataSourceFile cannot be nil")
+// Shard reads only the shard-th of shards equal byte ranges of the file,
+// split into a sub file for each processor. Data files read in chunks of
+// lines are not split, the agent loads every shards-th chunk of them.
+func (ds *dataSource) Shard(shard, shards int) bool {
+	if ds.files != nil {
+		ds.shard, ds.shards = uint64(shard), uint64(shards)
+		return true
 	}
-
-	return &dataSource{cursor: 0, subFiles: subFiles}
+	start := ds.fileSize * int64(shard) / int64(shards)
+	end := ds.fileSize * int64(shard+1) / int64(shards)
+	ds.split(start, end)
+	return true
 }
 
 // Retrieves the next item from the data source.
 // An item only contains a single data point for Datalayers.
 func (ds *dataSource) NextItem() data.LoadedPoint {
+	if ds.files != nil {
+		for {
+			item := ds.nextChunk()
+			i := ds.chunks
+			ds.chunks++
+			if item.Data == nil || ds.shards == 0 || i%ds.shards == ds.shard {
+				return item
+			}
+		}
+	}
 	if ds.cursor >= len(ds.subFiles) {
 		return data.LoadedPoint{}
 	}
@@ -81,7 +159,112 @@ func (ds *dataSource) NextItem() data.LoadedPoint {
 
 	ds.cursor += 1
 
-	return data.LoadedPoint{Data: subFile}
+	// the processor shifts the timestamps of the sub file by the offset
+	return data.LoadedPoint{Data: []int64{subFile[0], subFile[1], int64(ds.shift.Offset())}}
+}
+
+// Rewind starts the next pass over the data, with the timestamps shifted past
+// the ones read before. The processors parse the lines of a file split into
+// byte ranges, so its timestamps are read from all of it at the first rewind.
+func (ds *dataSource) Rewind() bool {
+	if ds.files == nil {
+		if !ds.scanned {
+			ds.scanTimestamps()
+			ds.scanned = true
+		}
+		if !ds.shift.Next() {
+			return false
+		}
+		ds.cursor = 0
+		return true
+	}
+	if !ds.shift.Next() {
+		return false
+	}
+	if err := ds.files.Rewind(); err != nil {
+		panic(fmt.Sprintf("failed to read data files again. error: %v", err))
+	}
+	ds.reader.Reset(ds.files)
+	return true
+}
+
+// scanTimestamps reads the timestamps of the whole file, also outside the
+// shard of an agent, so every agent shifts the next pass past all of them.
+func (ds *dataSource) scanTimestamps() {
+	fmt.Printf("Read the timestamps of the data file to loop over it\n")
+	scanner := bufio.NewScanner(io.NewSectionReader(DataSourceFile, 0, ds.fileSize))
+	scanner.Buffer(make([]byte, 64*1024), chunkSize)
+	for scanner.Scan() {
+		if ns, ok := lineTimestamp(scanner.Bytes()); ok {
+			ds.shift.Shift(ns)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		panic(fmt.Sprintf("failed to read data file. error: %v", err))
+	}
+}
+
+// lineTimestamp returns the timestamp of a line, its first value
+func lineTimestamp(line []byte) (int64, bool) {
+	end := bytes.IndexByte(line, ' ')
+	if end <= 0 {
+		return 0, false
+	}
+	ns, err := strconv.ParseInt(string(line[:end]), 10, 64)
+	return ns, err == nil
+}
+
+// shiftLine returns the line with its timestamp shifted for the current pass
+func (ds *dataSource) shiftLine(line []byte) []byte {
+	ns, ok := lineTimestamp(line)
+	if !ok {
+		return line
+	}
+	shifted := ds.shift.Shift(ns)
+	if shifted == ns {
+		return line
+	}
+	return append(strconv.AppendInt(nil, shifted, 10), line[bytes.IndexByte(line, ' '):]...)
+}
+
+// nextChunk reads the next chunk of complete lines of the data files, of at
+// least chunkSize bytes unless the files end before.
+func (ds *dataSource) nextChunk() data.LoadedPoint {
+	chunk := make([]byte, 0, chunkSize+chunkSize/8)
+	lineStart := true
+	for len(chunk) < chunkSize || !lineStart {
+		line, err := ds.reader.ReadSlice('\n')
+		if lineStart {
+			line = ds.shiftLine(line)
+		}
+		chunk = append(chunk, line...)
+		lineStart = err != bufio.ErrBufferFull
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			if len(chunk) > 0 && chunk[len(chunk)-1] != '\n' {
+				// the last line of a file has no trailing newline
+				chunk = append(chunk, '\n')
+			}
+			next, err := ds.files.Next()
+			if err != nil {
+				panic(fmt.Sprintf("failed to open the next data file. error: %v", err))
+			}
+			if !next {
+				break
+			}
+			ds.reader.Reset(ds.files)
+			continue
+		}
+		if err != nil {
+			panic(fmt.Sprintf("failed to read data file. error: %v", err))
+		}
+	}
+	if len(chunk) == 0 {
+		return data.LoadedPoint{}
+	}
+	return data.LoadedPoint{Data: chunk}
 }
 
 // Gets the headers of the data source. Not used by Datalayers.
@@ -114,6 +297,8 @@ func (indexer *pointIndexer) GetIndex(_ data.LoadedPoint) uint {
 // it does not get too large and it needs a way to append a point
 type batch struct {
 	subFile []int64
+	// lines is the chunk of lines of a data file read by the data source
+	lines []byte
 }
 
 // Gets the current length of the batch.
@@ -124,8 +309,12 @@ func (b *batch) Len() uint {
 
 // Appends a data point to the batch.
 func (b *batch) Append(loadedPoint data.LoadedPoint) {
-	subFile := loadedPoint.Data.([]int64)
-	b.subFile = subFile
+	switch item := loadedPoint.Data.(type) {
+	case []int64:
+		b.subFile = item
+	case []byte:
+		b.lines = item
+	}
 }
 
 // BatchFactory returns a new empty batch for storing points.
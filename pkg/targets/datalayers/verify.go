@@ -0,0 +1,101 @@
+package datalayers
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	// cpuTable is the only table loaded into Datalayers
+	cpuTable     = "cpu"
+	verifySQLFmt = "SELECT count(*), CAST(min(ts) AS BIGINT), CAST(max(ts) AS BIGINT), count(DISTINCT hostname) FROM %s.%s"
+)
+
+// lineTracker splits the sub files of the data source into lines. A line
+// split across two sub files is put back together, so the rows of the data
+// file are tracked, not the rows the processors manage to parse.
+type lineTracker struct {
+	partial  []byte
+	fileSize int64
+}
+
+// lines returns the complete lines of the sub file in [start, end) of the data
+// file, including a line started in the previous sub file
+func (t *lineTracker) lines(start, end int64) []string {
+	if t.fileSize == 0 {
+		info, err := DataSourceFile.Stat()
+		if err != nil {
+			panic(fmt.Sprintf("failed to get file info. error: %v", err))
+		}
+		t.fileSize = info.Size()
+	}
+
+	buffer := make([]byte, end-start)
+	if _, err := DataSourceFile.ReadAt(buffer, start); err != nil {
+		panic(fmt.Sprintf("failed to read sub file. error: %v", err))
+	}
+	buffer = append(t.partial, buffer...)
+
+	last := bytes.LastIndexByte(buffer, '\n')
+	t.partial = append([]byte(nil), buffer[last+1:]...)
+	lines := strings.Split(string(buffer[:last+1]), "\n")
+	if end >= t.fileSize && len(t.partial) > 0 {
+		// the last line of the file has no trailing newline
+		lines = append(lines, string(t.partial))
+		t.partial = nil
+	}
+	return lines
+}
+
+// TrackPoint adds the rows of the sub file or chunk of lines of the point to
+// stats, with the hostname as tag set.
+func (dc *dBCreator) TrackPoint(p data.LoadedPoint, stats *targets.DataStats) {
+	var lines []string
+	var offset int64
+	switch item := p.Data.(type) {
+	case []int64:
+		lines = dc.tracker.lines(item[0], item[1])
+		offset = item[2]
+	case []byte:
+		lines = strings.Split(string(item), "\n")
+	}
+	for _, line := range lines {
+		values := strings.Split(line, " ")
+		if len(values) != len(cpuFieldNames) {
+			continue
+		}
+		ns, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		stats.Add(cpuTable, time.Unix(0, ns+offset), values[1])
+	}
+}
+
+// Verify returns the number of rows, the time range and the number of hosts
+// of the cpu table
+func (dc *dBCreator) Verify(dbName string, measurements []string) (map[string]targets.MeasurementStats, error) {
+	stats := make(map[string]targets.MeasurementStats, len(measurements))
+	for _, m := range measurements {
+		values, err := dc.client.QueryInt64s(fmt.Sprintf(verifySQLFmt, dbName, m))
+		if err != nil {
+			return nil, fmt.Errorf("could not verify table %s: %v", m, err)
+		}
+		if len(values) != 4 {
+			return nil, fmt.Errorf("could not verify table %s: expected 4 columns, got %d", m, len(values))
+		}
+		stats[m] = targets.MeasurementStats{
+			Rows:    uint64(values[0]),
+			MinTime: time.Unix(0, values[1]),
+			MaxTime: time.Unix(0, values[2]),
+			TagSets: uint64(values[3]),
+		}
+	}
+	return stats, nil
+}
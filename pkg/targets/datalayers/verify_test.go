@@ -0,0 +1,157 @@
+package datalayers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestDBCreatorTrackPoint(t *testing.T) {
+	row := func(ts, host string) string {
+		values := make([]string, len(cpuFieldNames))
+		for i := range values {
+			values[i] = "1"
+		}
+		values[0], values[1] = ts, host
+		return strings.Join(values, " ")
+	}
+	// no trailing newline, so the last line ends with the file
+	content := strings.Join([]string{
+		row("1451606400000000000", "host_0"),
+		row("1451606410000000000", "host_1"),
+		row("1451606420000000000", "host_0"),
+	}, "\n")
+	fileName := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, numSubFiles := range []int64{1, 2, 7} {
+		ds := NewDataSource(fileName, numSubFiles)
+		dc := &dBCreator{}
+		stats := targets.NewDataStats()
+		for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+			dc.TrackPoint(item, stats)
+		}
+		DataSourceFile.Close()
+
+		want := targets.MeasurementStats{
+			Rows:    3,
+			MinTime: time.Unix(1451606400, 0),
+			MaxTime: time.Unix(1451606420, 0),
+			TagSets: 2,
+		}
+		if got := stats.Get(cpuTable); got != want {
+			t.Errorf("%d sub files: incorrect stats: got %+v want %+v", numSubFiles, got, want)
+		}
+	}
+}
+
+func TestDBCreatorTrackPointCompressedFiles(t *testing.T) {
+	row := func(ts, host string) string {
+		values := make([]string, len(cpuFieldNames))
+		for i := range values {
+			values[i] = "1"
+		}
+		values[0], values[1] = ts, host
+		return strings.Join(values, " ")
+	}
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	w.Write([]byte(row("1451606400000000000", "host_0") + "\n" + row("1451606410000000000", "host_1")))
+	w.Close()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.gz"), compressed.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b"), []byte(row("1451606420000000000", "host_2")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the files are read in chunks of lines
+	ds := NewDataSource(dir, 2)
+	dc := &dBCreator{}
+	stats := targets.NewDataStats()
+	chunks := 0
+	for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+		if _, ok := item.Data.([]byte); !ok {
+			t.Fatalf("expected a chunk of lines, got %T", item.Data)
+		}
+		dc.TrackPoint(item, stats)
+		chunks++
+	}
+	if chunks != 1 {
+		t.Errorf("incorrect number of chunks: got %d want %d", chunks, 1)
+	}
+	want := targets.MeasurementStats{
+		Rows:    3,
+		MinTime: time.Unix(1451606400, 0),
+		MaxTime: time.Unix(1451606420, 0),
+		TagSets: 3,
+	}
+	if got := stats.Get(cpuTable); got != want {
+		t.Errorf("incorrect stats: got %+v want %+v", got, want)
+	}
+}
+
+func TestDataSourceRewind(t *testing.T) {
+	row := func(ts, host string) string {
+		values := make([]string, len(cpuFieldNames))
+		for i := range values {
+			values[i] = "1"
+		}
+		values[0], values[1] = ts, host
+		return strings.Join(values, " ")
+	}
+	content := strings.Join([]string{
+		row("1451606400000000000", "host_0"),
+		row("1451606410000000000", "host_1"),
+		row("1451606420000000000", "host_0"),
+	}, "\n") + "\n"
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	w.Write([]byte(content))
+	w.Close()
+	dir := t.TempDir()
+	plain, gz := filepath.Join(dir, "data"), filepath.Join(dir, "data.gz")
+	if err := os.WriteFile(plain, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(gz, compressed.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fileName := range []string{plain, gz} {
+		ds := NewDataSource(fileName, 2).(*dataSource)
+		dc := &dBCreator{}
+		stats := targets.NewDataStats()
+		for pass := 0; pass < 2; pass++ {
+			if pass > 0 && !ds.Rewind() {
+				t.Fatalf("%s: could not rewind", fileName)
+			}
+			for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+				dc.TrackPoint(item, stats)
+			}
+		}
+		if ds.files == nil {
+			DataSourceFile.Close()
+		}
+
+		// the second pass is shifted by the 30s span of the first one
+		want := targets.MeasurementStats{
+			Rows:    6,
+			MinTime: time.Unix(1451606400, 0),
+			MaxTime: time.Unix(1451606450, 0),
+			TagSets: 2,
+		}
+		if got := stats.Get(cpuTable); got != want {
+			t.Errorf("%s: incorrect stats: got %+v want %+v", fileName, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package influx
+
+import (
+	"bufio"
+
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// NewFileDataSource returns a DataSource reading the line protocol of the
+// files fileName, one item per line
+func NewFileDataSource(fileName string, readAhead int) targets.DataSource {
+	br := load.GetRewindableReader(fileName, readAhead)
+	return &fileDataSource{reader: br, scanner: bufio.NewScanner(br)}
+}
+
+// fileDataSource reads the line protocol written by the InfluxDB serializer,
+// one line per point
+type fileDataSource struct {
+	reader  *load.RewindableReader
+	scanner *bufio.Scanner
+	shift   data.TimeShift
+}
+
+func (d *fileDataSource) NextItem() data.LoadedPoint {
+	ok := d.scanner.Scan()
+	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+		if d.reader.NextFile() {
+			d.scanner = bufio.NewScanner(d.reader)
+			return d.NextItem()
+		}
+		return data.LoadedPoint{}
+	} else if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+	line, err := ShiftTimestamp(d.scanner.Bytes(), &d.shift)
+	if err != nil {
+		fatal("%v", err)
+		return data.LoadedPoint{}
+	}
+	return data.NewLoadedPoint(line)
+}
+
+// Shard reads only the lines of the shard-th of shards byte ranges of the
+// data file, if it is a single uncompressed file
+func (d *fileDataSource) Shard(shard, shards int) bool {
+	if !d.reader.ShardLines(shard, shards, load.LineShards{}) {
+		return false
+	}
+	d.scanner = bufio.NewScanner(d.reader)
+	return true
+}
+
+// Rewind reads the file again, with the timestamps shifted past the ones read
+// before
+func (d *fileDataSource) Rewind() bool {
+	if !d.shift.Next() || !d.reader.Rewind() {
+		return false
+	}
+	d.scanner = bufio.NewScanner(d.reader)
+	return true
+}
+
+// Headers are not written for the line protocol
+func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
@@ -23,6 +23,11 @@ func (t *influxTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.Fla
 	flagSet.String(flagPrefix+"consistency", "all", "Write consistency. Must be one of: any, one, quorum, all.")
 	flagSet.Duration(flagPrefix+"backoff", time.Second, "Time to sleep between requests when server indicates backpressure is needed.")
 	flagSet.Bool(flagPrefix+"gzip", true, "Whether to gzip encode requests (default true).")
+	flagSet.Int(flagPrefix+"api-version", 1, "InfluxDB HTTP API version. 1 writes to /write?db=, 2 writes to /api/v2/write with org, bucket (db-name) and token.")
+	flagSet.String(flagPrefix+"org", "", "Organization that owns the bucket (API v2 only).")
+	flagSet.String(flagPrefix+"token", "", "Authentication token sent in the Authorization header.")
+	flagSet.String(flagPrefix+"precision", "ns", "Timestamp precision of the written points (API v2 only). Must be one of: ns, us, ms, s.")
+	flagSet.Duration(flagPrefix+"retention-period", 0, "Retention period of the created bucket, 0 = infinite (API v2 only).")
 }
 
 func (t *influxTarget) TargetName() string {
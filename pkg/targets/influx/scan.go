@@ -0,0 +1,81 @@
+package influx
+
+import (
+	"bytes"
+	"hash/fnv"
+	"log"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
+
+var fatal = log.Fatalf
+
+var (
+	newLine = []byte("\n")
+	space   = []byte(" ")
+	comma   = []byte(",")
+)
+
+// SeriesIndexer is used to consistently send the same series (measurement
+// and tags) of the line protocol to the same worker
+type SeriesIndexer struct {
+	Partitions uint
+}
+
+func (i *SeriesIndexer) GetIndex(item data.LoadedPoint) uint {
+	line := item.Data.([]byte)
+	if idx := bytes.Index(line, space); idx >= 0 {
+		line = line[:idx]
+	}
+	h := fnv.New32a()
+	h.Write(line)
+	return uint(h.Sum32()) % i.Partitions
+}
+
+// Batch holds lines of the line protocol ready to be sent to the database,
+// one line per point
+type Batch struct {
+	buf     *bytes.Buffer
+	rows    uint
+	metrics uint64
+}
+
+func (b *Batch) Len() uint {
+	return b.rows
+}
+
+// Metrics returns the number of fields of the lines in the batch
+func (b *Batch) Metrics() uint64 {
+	return b.metrics
+}
+
+// Bytes returns the lines of the batch, each ending with a newline
+func (b *Batch) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *Batch) Append(item data.LoadedPoint) {
+	that := item.Data.([]byte)
+	b.rows++
+	// Each line is in the format "csv-tags csv-fields timestamp", so we split by space
+	// and then on the middle element, we count the commas to get the number of fields
+	args := bytes.Split(that, space)
+	if len(args) != 3 {
+		fatal(errNotThreeTuplesFmt, len(args))
+		return
+	}
+	b.metrics += uint64(bytes.Count(args[1], comma) + 1)
+
+	b.buf.Write(that)
+	b.buf.Write(newLine)
+}
+
+// BatchFactory makes the Batches of the line protocol
+type BatchFactory struct{}
+
+func (f *BatchFactory) New() targets.Batch {
+	return &Batch{buf: bytes.NewBuffer(make([]byte, 0, 4*1024*1024))}
+}
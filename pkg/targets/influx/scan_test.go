@@ -0,0 +1,53 @@
+package influx
+
+import (
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestBatchAppend(t *testing.T) {
+	b := newTestBatch(
+		"cpu,hostname=host_0 usage_user=1,usage_system=2,usage_idle=3 0",
+		"cpu,hostname=host_1 usage_user=1 0",
+	)
+	if b.Len() != 2 {
+		t.Errorf("incorrect length: got %d want 2", b.Len())
+	}
+	if b.metrics != 4 {
+		t.Errorf("incorrect metric count: got %d want 4", b.metrics)
+	}
+}
+
+func TestBatchAppendInvalid(t *testing.T) {
+	oldFatal := fatal
+	defer func() { fatal = oldFatal }()
+	isCalled := false
+	fatal = func(format string, args ...interface{}) {
+		isCalled = true
+	}
+	newTestBatch("cpu usage_user=1")
+	if !isCalled {
+		t.Errorf("fatal not called for line without timestamp")
+	}
+}
+
+func newTestBatch(lines ...string) *Batch {
+	b := (&BatchFactory{}).New().(*Batch)
+	for _, l := range lines {
+		b.Append(data.NewLoadedPoint([]byte(l)))
+	}
+	return b
+}
+
+func TestSeriesIndexer(t *testing.T) {
+	i := &SeriesIndexer{Partitions: 8}
+	a := i.GetIndex(data.NewLoadedPoint([]byte("cpu,hostname=host_0 usage_user=1 0")))
+	b := i.GetIndex(data.NewLoadedPoint([]byte("cpu,hostname=host_0 usage_user=2 10")))
+	if a != b {
+		t.Errorf("same series indexed to different workers: %d and %d", a, b)
+	}
+	if a >= 8 {
+		t.Errorf("index out of range: %d", a)
+	}
+}
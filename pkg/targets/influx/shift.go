@@ -0,0 +1,27 @@
+package influx
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// ShiftTimestamp shifts the nanosecond timestamp that ends a line written by
+// the Serializer. The line is returned as is if its timestamp is unchanged,
+// and copied otherwise.
+func ShiftTimestamp(line []byte, shift *data.TimeShift) ([]byte, error) {
+	i := bytes.LastIndexByte(line, ' ')
+	ns, err := strconv.ParseInt(string(line[i+1:]), 10, 64)
+	if err != nil {
+		return line, fmt.Errorf("invalid timestamp %q: %v", line[i+1:], err)
+	}
+	shifted := shift.Shift(ns)
+	if shifted == ns {
+		return line, nil
+	}
+	buf := make([]byte, 0, len(line)+4)
+	buf = append(buf, line[:i+1]...)
+	return strconv.AppendInt(buf, shifted, 10), nil
+}
@@ -0,0 +1,37 @@
+package influx
+
+import (
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestShiftTimestamp(t *testing.T) {
+	var shift data.TimeShift
+	lines := []string{"cpu,hostname=host_0 usage_user=1 1000", "cpu,hostname=host_0 usage_user=2 2000"}
+	for _, line := range lines {
+		got, err := ShiftTimestamp([]byte(line), &shift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != line {
+			t.Errorf("first pass shifted %q to %q", line, got)
+		}
+	}
+
+	shift.Next()
+	want := []string{"cpu,hostname=host_0 usage_user=1 3000", "cpu,hostname=host_0 usage_user=2 4000"}
+	for i, line := range lines {
+		got, err := ShiftTimestamp([]byte(line), &shift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want[i] {
+			t.Errorf("incorrect shifted line: got %q want %q", got, want[i])
+		}
+	}
+
+	if _, err := ShiftTimestamp([]byte("cpu usage_user=1"), &shift); err == nil {
+		t.Errorf("expected an error for a line without timestamp")
+	}
+}
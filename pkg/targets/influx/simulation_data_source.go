@@ -0,0 +1,50 @@
+package influx
+
+import (
+	"bytes"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// NewSimulationDataSource returns a DataSource serializing the points of sim
+// into lines of the line protocol
+func NewSimulationDataSource(sim common.Simulator) targets.DataSource {
+	return &simulationDataSource{simulator: sim}
+}
+
+// simulationDataSource serializes each simulated point into a line of the
+// line protocol, the same as read from a file.
+type simulationDataSource struct {
+	simulator  common.Simulator
+	serializer Serializer
+	buf        bytes.Buffer
+}
+
+func (d *simulationDataSource) NextItem() data.LoadedPoint {
+	p := data.NewPoint()
+	for !d.simulator.Finished() {
+		if !d.simulator.Next(p) {
+			p.Reset()
+			continue
+		}
+		d.buf.Reset()
+		if err := d.serializer.Serialize(p, &d.buf); err != nil {
+			fatal("could not serialize point: %v", err)
+			return data.LoadedPoint{}
+		}
+		return data.NewLoadedPoint(bytes.TrimSuffix(d.buf.Bytes(), newLine))
+	}
+	return data.LoadedPoint{}
+}
+
+// Headers are not written for the line protocol
+func (d *simulationDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
+
+// Rewind keeps the simulation going for another pass over its time range
+func (d *simulationDataSource) Rewind() bool {
+	return common.RewindSimulator(d.simulator)
+}
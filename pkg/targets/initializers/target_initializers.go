@@ -3,10 +3,14 @@ package initializers
 import (
 	"fmt"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/clickhouse"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/datalayers"
 	"github.com/timescale/tsbs/pkg/targets/influx"
+	"github.com/timescale/tsbs/pkg/targets/prometheus"
+	"github.com/timescale/tsbs/pkg/targets/questdb"
 	"github.com/timescale/tsbs/pkg/targets/timescaledb"
+	"github.com/timescale/tsbs/pkg/targets/victoriametrics"
 	"strings"
 )
 
@@ -18,6 +22,14 @@ func GetTarget(format string) targets.ImplementedTarget {
 		return influx.NewTarget()
 	case constants.FormatDatalayers:
 		return datalayers.NewTarget()
+	case constants.FormatPrometheus:
+		return prometheus.NewTarget()
+	case constants.FormatClickhouse:
+		return clickhouse.NewTarget()
+	case constants.FormatQuestDB:
+		return questdb.NewTarget()
+	case constants.FormatVictoriaMetrics:
+		return victoriametrics.NewTarget()
 	}
 
 	supportedFormatsStr := strings.Join(constants.SupportedFormats(), ",")
@@ -1,5 +1,15 @@
 package targets
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
 // Processor is a type that processes the work for a loading worker
 type Processor interface {
 	// Init does per-worker setup needed before receiving data
@@ -14,3 +24,83 @@ type ProcessorCloser interface {
 	// Close cleans up after a Processor
 	Close(doLoad bool)
 }
+
+// ErrorClass classifies why a batch, or part of it, could not be written
+type ErrorClass string
+
+const (
+	// ErrorClassConnection is a database that can't be reached, or a
+	// connection that broke during the write
+	ErrorClassConnection ErrorClass = "connection"
+	// ErrorClassTimeout is a write that took longer than allowed
+	ErrorClassTimeout ErrorClass = "timeout"
+	// ErrorClassRejected is data refused by the database, e.g. due to its
+	// schema, limits or a failed statement
+	ErrorClassRejected ErrorClass = "rejected"
+	// ErrorClassOther is any other error
+	ErrorClassOther ErrorClass = "other"
+)
+
+// BatchResult is the outcome of processing a batch: what was written and,
+// if the write failed in whole or in part, what was not and why
+type BatchResult struct {
+	Metrics       uint64
+	Rows          uint64
+	FailedMetrics uint64
+	FailedRows    uint64
+	Err           error
+	ErrorClass    ErrorClass
+}
+
+// Fail records that the given metrics and rows could not be written due to
+// err, keeping the first error of the batch
+func (r *BatchResult) Fail(metrics, rows uint64, err error, class ErrorClass) {
+	r.FailedMetrics += metrics
+	r.FailedRows += rows
+	if r.Err == nil {
+		r.Err = err
+		r.ErrorClass = class
+	}
+}
+
+// ResultProcessor is a Processor that reports failed writes in a BatchResult
+// instead of exiting or ignoring them, so the loader can count them and
+// decide whether the run goes on
+type ResultProcessor interface {
+	Processor
+	// ProcessBatchWithResult handles a single batch of data
+	ProcessBatchWithResult(b Batch, doLoad bool) BatchResult
+}
+
+// HTTPStatusError is the error of a write request the server answered with
+// a non-2xx HTTP status
+type HTTPStatusError struct {
+	Status     string
+	StatusCode int
+	// Body is the start of the response body, which usually tells what was
+	// wrong with the request
+	Body string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("server returned HTTP status %s: %s", e.Status, e.Body)
+}
+
+// ClassifyError returns the ErrorClass of an error of the network or of an
+// HTTP write request, which is ErrorClassOther if it can't be told
+func ClassifyError(err error) ErrorClass {
+	var netErr net.Error
+	var statusErr *HTTPStatusError
+	switch {
+	case errors.As(err, &statusErr) && statusErr.StatusCode/100 == 4:
+		return ErrorClassRejected
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE):
+		return ErrorClassConnection
+	}
+	return ErrorClassOther
+}
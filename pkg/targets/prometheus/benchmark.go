@@ -0,0 +1,69 @@
+package prometheus
+
+import (
+	"errors"
+	"time"
+
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// SpecificConfig holds the prometheus specific loader configuration
+type SpecificConfig struct {
+	RemoteWriteURL   string        `yaml:"remote-write-url" mapstructure:"remote-write-url"`
+	Concurrency      int           `yaml:"concurrency" mapstructure:"concurrency"`
+	MaxSeriesPerSend int           `yaml:"max-series-per-send" mapstructure:"max-series-per-send"`
+	Timeout          time.Duration `yaml:"timeout" mapstructure:"timeout"`
+}
+
+func NewBenchmark(config *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if config.RemoteWriteURL == "" {
+		return nil, errors.New("remote-write-url must be set")
+	}
+	if config.Concurrency < 1 {
+		return nil, errors.New("concurrency must be at least 1")
+	}
+
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = newFileDataSource(dataSourceConfig.File.Location, dataSourceConfig.File.ReadAhead)
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = newSimulationDataSource(simulator)
+	}
+
+	return &benchmark{config: config, ds: ds}, nil
+}
+
+type benchmark struct {
+	config *SpecificConfig
+	ds     targets.DataSource
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	if maxPartitions > 1 {
+		return &seriesIndexer{partitions: maxPartitions}
+	}
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return newProcessor(b.config)
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{}
+}
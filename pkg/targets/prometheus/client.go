@@ -0,0 +1,65 @@
+package prometheus
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	remoteWriteVersion = "0.1.0"
+	// maxErrMsgLen limits how much of an error response body is reported
+	maxErrMsgLen = 256
+)
+
+// client sends snappy-compressed protobuf WriteRequests to a remote-write endpoint
+type client struct {
+	url        string
+	httpClient *http.Client
+	buf        []byte
+}
+
+func newClient(url string, timeout time.Duration) *client {
+	return &client{
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// write sends the series in a single remote-write request. A client is not safe
+// for concurrent use since the compression buffer is reused between requests.
+func (c *client) write(series []prompb.TimeSeries) error {
+	req := &prompb.WriteRequest{Timeseries: series}
+	raw, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	c.buf = snappy.Encode(c.buf[:cap(c.buf)], raw)
+
+	httpReq, err := http.NewRequest("POST", c.url, bytes.NewReader(c.buf))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Add("Content-Encoding", "snappy")
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	httpReq.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrMsgLen))
+		return &targets.HTTPStatusError{Status: resp.Status, StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(body))}
+	}
+	// drain the body so the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
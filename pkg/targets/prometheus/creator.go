@@ -0,0 +1,24 @@
+package prometheus
+
+import "log"
+
+var fatal = log.Fatalf
+
+// dbCreator is a no-op since Prometheus has no databases; every series is
+// written to the single TSDB behind the remote-write endpoint.
+type dbCreator struct{}
+
+func (d *dbCreator) Init() {}
+
+// DBExists always reports false so do-abort-on-exist never stops a load.
+func (d *dbCreator) DBExists(dbName string) bool {
+	return false
+}
+
+func (d *dbCreator) CreateDB(dbName string) error {
+	return nil
+}
+
+func (d *dbCreator) RemoveOldDB(dbName string) error {
+	return nil
+}
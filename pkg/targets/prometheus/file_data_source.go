@@ -0,0 +1,79 @@
+package prometheus
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func newFileDataSource(fileName string, readAhead int) targets.DataSource {
+	return &fileDataSource{reader: load.GetRewindableReader(fileName, readAhead)}
+}
+
+// fileDataSource reads the length-prefixed series written by the Serializer
+type fileDataSource struct {
+	reader *load.RewindableReader
+	buf    []byte
+	shift  data.TimeShift
+}
+
+func (d *fileDataSource) NextItem() data.LoadedPoint {
+	ts, err := readTimeSeries(d.reader.Reader, &d.buf)
+	if err == io.EOF {
+		if d.reader.NextFile() {
+			return d.NextItem()
+		}
+		return data.LoadedPoint{}
+	} else if err != nil {
+		fatal("could not read series: %v", err)
+		return data.LoadedPoint{}
+	}
+	for i := range ts.Samples {
+		// sample timestamps are in milliseconds
+		s := &ts.Samples[i]
+		s.Timestamp = d.shift.Shift(s.Timestamp*int64(time.Millisecond)) / int64(time.Millisecond)
+	}
+	return data.NewLoadedPoint(ts)
+}
+
+// Rewind reads the file again, with the timestamps shifted past the ones read
+// before
+func (d *fileDataSource) Rewind() bool {
+	return d.shift.Next() && d.reader.Rewind()
+}
+
+// Headers are not written for the prometheus format
+func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
+
+// readTimeSeries decodes the next varint length-prefixed prompb.TimeSeries. buf
+// is reused between calls to avoid allocating a new slice for every series.
+func readTimeSeries(r *bufio.Reader, buf *[]byte) (*prompb.TimeSeries, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(cap(*buf)) < size {
+		*buf = make([]byte, size)
+	}
+	b := (*buf)[:size]
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	ts := &prompb.TimeSeries{}
+	if err := ts.Unmarshal(b); err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
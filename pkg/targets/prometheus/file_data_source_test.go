@@ -0,0 +1,53 @@
+package prometheus
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+)
+
+func TestFileDataSourceRewind(t *testing.T) {
+	f, err := ioutil.TempFile("", "prometheus_data_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	for _, ms := range []int64{1000, 2000} {
+		ts := prompb.TimeSeries{
+			Labels:  []prompb.Label{{Name: metricNameLabel, Value: "cpu_usage_user"}},
+			Samples: []prompb.Sample{{Value: 1, Timestamp: ms}},
+		}
+		b, err := ts.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(binary.AppendUvarint(nil, uint64(len(b))))
+		f.Write(b)
+	}
+	f.Close()
+
+	ds := newFileDataSource(f.Name(), 0).(*fileDataSource)
+	var got []int64
+	for pass := 0; pass < 2; pass++ {
+		if pass > 0 && !ds.Rewind() {
+			t.Fatalf("could not rewind for pass %d", pass)
+		}
+		for p := ds.NextItem(); p.Data != nil; p = ds.NextItem() {
+			got = append(got, p.Data.(*prompb.TimeSeries).Samples[0].Timestamp)
+		}
+	}
+
+	want := []int64{1000, 2000, 3000, 4000}
+	if len(got) != len(want) {
+		t.Fatalf("incorrect timestamps: got %v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("incorrect timestamps: got %v want %v", got, want)
+			break
+		}
+	}
+}
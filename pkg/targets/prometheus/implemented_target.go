@@ -0,0 +1,44 @@
+package prometheus
+
+import (
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() targets.ImplementedTarget {
+	return &prometheusTarget{}
+}
+
+type prometheusTarget struct {
+}
+
+func (t *prometheusTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"remote-write-url", "http://localhost:9090/api/v1/write", "Prometheus remote-write endpoint")
+	flagSet.Int(flagPrefix+"concurrency", 1, "Number of parallel remote-write requests per worker")
+	flagSet.Int(flagPrefix+"max-series-per-send", 2000, "Maximum number of series in a single remote-write request, 0 = whole batch")
+	flagSet.Duration(flagPrefix+"timeout", 30*time.Second, "Timeout of a single remote-write request")
+}
+
+func (t *prometheusTarget) TargetName() string {
+	return constants.FormatPrometheus
+}
+
+func (t *prometheusTarget) Serializer() serialize.PointSerializer {
+	return &Serializer{}
+}
+
+func (t *prometheusTarget) Benchmark(
+	_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper,
+) (targets.Benchmark, error) {
+	var config SpecificConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+	return NewBenchmark(&config, dataSourceConfig)
+}
@@ -0,0 +1,113 @@
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+type processor struct {
+	config  *SpecificConfig
+	clients []*client
+}
+
+func newProcessor(config *SpecificConfig) targets.Processor {
+	return &processor{config: config}
+}
+
+// Init creates one client per concurrent request the worker may have in flight
+func (p *processor) Init(_ int, doLoad, _ bool) {
+	if !doLoad {
+		return
+	}
+	p.clients = make([]*client, p.config.Concurrency)
+	for i := range p.clients {
+		p.clients[i] = newClient(p.config.RemoteWriteURL, p.config.Timeout)
+	}
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	res := p.ProcessBatchWithResult(b, doLoad)
+	if res.Err != nil {
+		fatal("remote write error: %v", res.Err)
+	}
+	return res.Metrics, res.Rows
+}
+
+// ProcessBatchWithResult sends the series of the batch using up to
+// Concurrency parallel requests. Each series always goes through the same
+// client, in requests of at most MaxSeriesPerSend series sent one after
+// another, so its samples reach the server in order. Prometheus has no notion
+// of rows, so only the number of samples is reported, and the samples of each
+// failed request are counted as failed.
+func (p *processor) ProcessBatchWithResult(b targets.Batch, doLoad bool) targets.BatchResult {
+	batch := b.(*batch)
+	if !doLoad {
+		return targets.BatchResult{Metrics: batch.samples}
+	}
+
+	partitions := partitionSeries(batch.series, len(p.clients))
+	res := targets.BatchResult{Metrics: batch.samples}
+	lock := &sync.Mutex{}
+	wg := &sync.WaitGroup{}
+	for i, partition := range partitions {
+		if len(partition) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(c *client, partition []prompb.TimeSeries) {
+			defer wg.Done()
+			for _, series := range splitSeries(partition, p.config.MaxSeriesPerSend) {
+				if err := c.write(series); err != nil {
+					samples := countSamples(series)
+					lock.Lock()
+					res.Metrics -= samples
+					res.Fail(samples, 0, err, targets.ClassifyError(err))
+					lock.Unlock()
+				}
+			}
+		}(p.clients[i], partition)
+	}
+	wg.Wait()
+	return res
+}
+
+// partitionSeries divides series into n partitions by the hash of their
+// labels, keeping the order of the samples of each series
+func partitionSeries(series []prompb.TimeSeries, n int) [][]prompb.TimeSeries {
+	if n <= 1 {
+		return [][]prompb.TimeSeries{series}
+	}
+	partitions := make([][]prompb.TimeSeries, n)
+	for i := range series {
+		p := seriesHash(&series[i]) % uint32(n)
+		partitions[p] = append(partitions[p], series[i])
+	}
+	return partitions
+}
+
+// countSamples returns the number of samples in series
+func countSamples(series []prompb.TimeSeries) uint64 {
+	var n uint64
+	for _, s := range series {
+		n += uint64(len(s.Samples))
+	}
+	return n
+}
+
+// splitSeries divides series into consecutive chunks of at most size elements
+func splitSeries(series []prompb.TimeSeries, size int) [][]prompb.TimeSeries {
+	if size <= 0 || len(series) <= size {
+		return [][]prompb.TimeSeries{series}
+	}
+	chunks := make([][]prompb.TimeSeries, 0, (len(series)+size-1)/size)
+	for start := 0; start < len(series); start += size {
+		end := start + size
+		if end > len(series) {
+			end = len(series)
+		}
+		chunks = append(chunks, series[start:end])
+	}
+	return chunks
+}
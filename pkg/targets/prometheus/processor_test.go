@@ -0,0 +1,204 @@
+package prometheus
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// stubReceiver is a local remote-write endpoint that decodes and keeps every
+// request it receives.
+type stubReceiver struct {
+	mu       sync.Mutex
+	requests []*prompb.WriteRequest
+	status   int
+}
+
+func (s *stubReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Encoding") != "snappy" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+		http.Error(w, "unexpected encoding", http.StatusUnsupportedMediaType)
+		return
+	}
+	compressed, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	raw, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &prompb.WriteRequest{}
+	if err := req.Unmarshal(raw); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.status != 0 {
+		http.Error(w, "stub failure", s.status)
+		return
+	}
+	s.requests = append(s.requests, req)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *stubReceiver) samples() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cnt := 0
+	for _, r := range s.requests {
+		for _, ts := range r.Timeseries {
+			cnt += len(ts.Samples)
+		}
+	}
+	return cnt
+}
+
+func newTestBatch(n int) *batch {
+	b := &batch{}
+	for i := 0; i < n; i++ {
+		b.series = append(b.series, prompb.TimeSeries{
+			Labels:  []prompb.Label{{Name: metricNameLabel, Value: "cpu_usage_user"}, {Name: "hostname", Value: fmt.Sprintf("host_%d", i)}},
+			Samples: []prompb.Sample{{Value: float64(i), Timestamp: int64(i)}},
+		})
+		b.samples++
+	}
+	return b
+}
+
+func TestProcessBatch(t *testing.T) {
+	cases := []struct {
+		desc         string
+		series       int
+		maxPerSend   int
+		concurrency  int
+		wantRequests int
+	}{
+		{desc: "single request", series: 10, maxPerSend: 100, concurrency: 1, wantRequests: 1},
+		{desc: "unlimited request size", series: 10, maxPerSend: 0, concurrency: 1, wantRequests: 1},
+		{desc: "one request per client", series: 10, maxPerSend: 0, concurrency: 4, wantRequests: 4},
+		{desc: "split sequentially", series: 10, maxPerSend: 3, concurrency: 1, wantRequests: 4},
+	}
+	for _, c := range cases {
+		stub := &stubReceiver{}
+		server := httptest.NewServer(stub)
+
+		p := newProcessor(&SpecificConfig{
+			RemoteWriteURL:   server.URL,
+			Concurrency:      c.concurrency,
+			MaxSeriesPerSend: c.maxPerSend,
+			Timeout:          time.Second,
+		})
+		p.Init(0, true, false)
+		metrics, rows := p.ProcessBatch(newTestBatch(c.series), true)
+		server.Close()
+
+		if metrics != uint64(c.series) || rows != 0 {
+			t.Errorf("%s: incorrect counts: got %d metrics %d rows, want %d metrics 0 rows", c.desc, metrics, rows, c.series)
+		}
+		if got := len(stub.requests); got != c.wantRequests {
+			t.Errorf("%s: incorrect number of requests: got %d want %d", c.desc, got, c.wantRequests)
+		}
+		if got := stub.samples(); got != c.series {
+			t.Errorf("%s: incorrect number of received samples: got %d want %d", c.desc, got, c.series)
+		}
+	}
+}
+
+func TestProcessBatchSeriesInOrder(t *testing.T) {
+	stub := &stubReceiver{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	p := newProcessor(&SpecificConfig{
+		RemoteWriteURL:   server.URL,
+		Concurrency:      4,
+		MaxSeriesPerSend: 1,
+		Timeout:          time.Second,
+	})
+	p.Init(0, true, false)
+	// the samples of the hosts are interleaved in the batch
+	b := &batch{}
+	for ts := 0; ts < 5; ts++ {
+		for host := 0; host < 3; host++ {
+			b.series = append(b.series, prompb.TimeSeries{
+				Labels:  []prompb.Label{{Name: metricNameLabel, Value: "cpu_usage_user"}, {Name: "hostname", Value: fmt.Sprintf("host_%d", host)}},
+				Samples: []prompb.Sample{{Value: float64(ts), Timestamp: int64(ts)}},
+			})
+			b.samples++
+		}
+	}
+	metrics, _ := p.ProcessBatch(b, true)
+	if metrics != 15 {
+		t.Errorf("incorrect metrics: got %d want %d", metrics, 15)
+	}
+
+	last := map[string]int64{}
+	for _, r := range stub.requests {
+		for _, series := range r.Timeseries {
+			host := series.Labels[1].Value
+			for _, s := range series.Samples {
+				if prev, ok := last[host]; ok && s.Timestamp <= prev {
+					t.Errorf("sample of %s out of order: got %d after %d", host, s.Timestamp, prev)
+				}
+				last[host] = s.Timestamp
+			}
+		}
+	}
+	if len(last) != 3 {
+		t.Errorf("incorrect number of series received: got %d want %d", len(last), 3)
+	}
+}
+
+func TestProcessBatchNoLoad(t *testing.T) {
+	p := newProcessor(&SpecificConfig{Concurrency: 1})
+	p.Init(0, false, false)
+	metrics, rows := p.ProcessBatch(newTestBatch(5), false)
+	if metrics != 5 || rows != 0 {
+		t.Errorf("incorrect counts: got %d metrics %d rows", metrics, rows)
+	}
+}
+
+func TestClientWriteError(t *testing.T) {
+	stub := &stubReceiver{status: http.StatusBadRequest}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	c := newClient(server.URL, time.Second)
+	err := c.write(newTestBatch(1).series)
+	if err == nil {
+		t.Fatalf("expected error on non-2xx response")
+	}
+}
+
+func TestProcessBatchWithResultFailed(t *testing.T) {
+	stub := &stubReceiver{status: http.StatusBadRequest}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	p := newProcessor(&SpecificConfig{
+		RemoteWriteURL:   server.URL,
+		Concurrency:      2,
+		MaxSeriesPerSend: 3,
+		Timeout:          time.Second,
+	}).(*processor)
+	p.Init(0, true, false)
+	res := p.ProcessBatchWithResult(newTestBatch(10), true)
+	if res.Metrics != 0 || res.FailedMetrics != 10 {
+		t.Errorf("incorrect counts: got %d metrics %d failed, want 0 metrics 10 failed", res.Metrics, res.FailedMetrics)
+	}
+	if res.Err == nil || res.ErrorClass != targets.ErrorClassRejected {
+		t.Errorf("incorrect error: got %v (%s) want a rejected error", res.Err, res.ErrorClass)
+	}
+}
@@ -0,0 +1,51 @@
+package prometheus
+
+import (
+	"hash/fnv"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// seriesIndexer is used to consistently send the samples of a series to the same
+// worker, so samples of one series are never written out of order.
+type seriesIndexer struct {
+	partitions uint
+}
+
+func (i *seriesIndexer) GetIndex(item data.LoadedPoint) uint {
+	return uint(seriesHash(item.Data.(*prompb.TimeSeries))) % i.partitions
+}
+
+// seriesHash returns the hash of the labels of the series
+func seriesHash(ts *prompb.TimeSeries) uint32 {
+	h := fnv.New32a()
+	for _, l := range ts.Labels {
+		h.Write([]byte(l.Name))
+		h.Write([]byte(l.Value))
+	}
+	return h.Sum32()
+}
+
+// batch holds the series to be sent in one or more remote-write requests
+type batch struct {
+	series  []prompb.TimeSeries
+	samples uint64
+}
+
+func (b *batch) Len() uint {
+	return uint(len(b.series))
+}
+
+func (b *batch) Append(item data.LoadedPoint) {
+	ts := item.Data.(*prompb.TimeSeries)
+	b.series = append(b.series, *ts)
+	b.samples += uint64(len(ts.Samples))
+}
+
+type factory struct{}
+
+func (f *factory) New() targets.Batch {
+	return &batch{}
+}
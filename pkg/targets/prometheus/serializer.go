@@ -0,0 +1,116 @@
+package prometheus
+
+import (
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+)
+
+const metricNameLabel = "__name__"
+
+// Serializer writes a Point in a serialized form for the Prometheus remote-write protocol
+type Serializer struct{}
+
+// Serialize writes Point data to the given writer as a sequence of
+// prompb.TimeSeries, one for each non-nil field of the Point.
+//
+// Each series is named <measurement>_<field> and carries the tags of the Point
+// as labels. Every series is written as a protobuf message prefixed with its
+// length encoded as an unsigned varint, e.g. for a point with two fields:
+// <len><TimeSeries cpu_usage_user><len><TimeSeries cpu_usage_system>
+func (s *Serializer) Serialize(p *data.Point, w io.Writer) error {
+	series := convertToTimeSeries(p)
+	if len(series) == 0 {
+		return nil
+	}
+
+	buf := make([]byte, 0, 1024)
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+	for i := range series {
+		size := series[i].Size()
+		n := binary.PutUvarint(lenBuf, uint64(size))
+		buf = append(buf, lenBuf[:n]...)
+		start := len(buf)
+		buf = append(buf, make([]byte, size)...)
+		if _, err := series[i].MarshalTo(buf[start:]); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// convertToTimeSeries creates a single-sample series for each field of the Point.
+// Tags with a nil value are skipped, as are nil or non-numeric fields.
+func convertToTimeSeries(p *data.Point) []prompb.TimeSeries {
+	tagKeys := p.TagKeys()
+	tagValues := p.TagValues()
+	labels := make([]prompb.Label, 0, len(tagKeys)+1)
+	for i, v := range tagValues {
+		if v == nil {
+			continue
+		}
+		var value string
+		switch tv := v.(type) {
+		case string:
+			value = tv
+		default:
+			value = string(serialize.FastFormatAppend(tv, nil))
+		}
+		labels = append(labels, prompb.Label{Name: string(tagKeys[i]), Value: value})
+	}
+	// remote-write receivers expect the labels of a series to be sorted by name
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+
+	measurement := string(p.MeasurementName())
+	timestamp := p.TimestampInUnixMs()
+	fieldKeys := p.FieldKeys()
+	fieldValues := p.FieldValues()
+	series := make([]prompb.TimeSeries, 0, len(fieldKeys))
+	for i, v := range fieldValues {
+		value, ok := toFloat64(v)
+		if !ok {
+			continue
+		}
+		seriesLabels := make([]prompb.Label, 0, len(labels)+1)
+		seriesLabels = append(seriesLabels, prompb.Label{Name: metricNameLabel, Value: measurement + "_" + string(fieldKeys[i])})
+		seriesLabels = append(seriesLabels, labels...)
+		series = append(series, prompb.TimeSeries{
+			Labels:  seriesLabels,
+			Samples: []prompb.Sample{{Value: value, Timestamp: timestamp}},
+		})
+	}
+	return series
+}
+
+func toFloat64(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
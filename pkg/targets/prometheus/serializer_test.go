@@ -0,0 +1,107 @@
+package prometheus
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+)
+
+func TestPrometheusSerializerSerialize(t *testing.T) {
+	ts := serialize.TestNow.UnixNano() / 1e6
+	labels := func(name string) []prompb.Label {
+		return []prompb.Label{
+			{Name: metricNameLabel, Value: name},
+			{Name: "datacenter", Value: "eu-west-1b"},
+			{Name: "hostname", Value: "host_0"},
+			{Name: "region", Value: "eu-west-1"},
+		}
+	}
+	cases := []struct {
+		desc  string
+		point *data.Point
+		want  []prompb.TimeSeries
+	}{
+		{
+			desc:  "a regular Point",
+			point: serialize.TestPointDefault(),
+			want: []prompb.TimeSeries{
+				{Labels: labels("cpu_usage_guest_nice"), Samples: []prompb.Sample{{Value: serialize.TestFloat, Timestamp: ts}}},
+			},
+		},
+		{
+			desc:  "a regular Point with multiple fields",
+			point: serialize.TestPointMultiField(),
+			want: []prompb.TimeSeries{
+				{Labels: labels("cpu_big_usage_guest"), Samples: []prompb.Sample{{Value: float64(serialize.TestInt64), Timestamp: ts}}},
+				{Labels: labels("cpu_usage_guest"), Samples: []prompb.Sample{{Value: serialize.TestInt, Timestamp: ts}}},
+				{Labels: labels("cpu_usage_guest_nice"), Samples: []prompb.Sample{{Value: serialize.TestFloat, Timestamp: ts}}},
+			},
+		},
+		{
+			desc:  "a Point with no tags",
+			point: serialize.TestPointNoTags(),
+			want: []prompb.TimeSeries{
+				{Labels: []prompb.Label{{Name: metricNameLabel, Value: "cpu_usage_guest_nice"}}, Samples: []prompb.Sample{{Value: serialize.TestFloat, Timestamp: ts}}},
+			},
+		},
+		{
+			desc:  "a Point with a nil tag",
+			point: serialize.TestPointWithNilTag(),
+			want: []prompb.TimeSeries{
+				{Labels: []prompb.Label{{Name: metricNameLabel, Value: "cpu_usage_guest_nice"}}, Samples: []prompb.Sample{{Value: serialize.TestFloat, Timestamp: ts}}},
+			},
+		},
+		{
+			desc:  "a Point with a nil field",
+			point: serialize.TestPointWithNilField(),
+			want: []prompb.TimeSeries{
+				{Labels: []prompb.Label{{Name: metricNameLabel, Value: "cpu_usage_guest_nice"}}, Samples: []prompb.Sample{{Value: serialize.TestFloat, Timestamp: ts}}},
+			},
+		},
+		{
+			desc:  "a Point with only nil fields",
+			point: serialize.TestPointWithNilTagAndNilField(),
+			want:  nil,
+		},
+	}
+
+	s := &Serializer{}
+	for _, c := range cases {
+		b := new(bytes.Buffer)
+		if err := s.Serialize(c.point, b); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.desc, err)
+		}
+		var got []prompb.TimeSeries
+		r := bufio.NewReader(b)
+		var buf []byte
+		for {
+			series, err := readTimeSeries(r, &buf)
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: could not read series: %v", c.desc, err)
+			}
+			got = append(got, *series)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s:\ngot\n%v\nwant\n%v", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestPrometheusSerializerSerializeErr(t *testing.T) {
+	p := serialize.TestPointDefault()
+	s := &Serializer{}
+	err := s.Serialize(p, &serialize.ErrWriter{})
+	if err == nil {
+		t.Errorf("no error returned when expected")
+	} else if err.Error() != serialize.ErrWriterAlwaysErr {
+		t.Errorf("unexpected writer error: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package prometheus
+
+import (
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func newSimulationDataSource(sim common.Simulator) targets.DataSource {
+	return &simulationDataSource{simulator: sim}
+}
+
+// simulationDataSource converts each simulated point into one series per field
+// and hands them out one at a time.
+type simulationDataSource struct {
+	simulator common.Simulator
+	pending   []prompb.TimeSeries
+}
+
+func (d *simulationDataSource) NextItem() data.LoadedPoint {
+	for len(d.pending) == 0 {
+		if d.simulator.Finished() {
+			return data.LoadedPoint{}
+		}
+		p := data.NewPoint()
+		if !d.simulator.Next(p) {
+			continue
+		}
+		d.pending = convertToTimeSeries(p)
+	}
+	ts := &d.pending[0]
+	d.pending = d.pending[1:]
+	return data.NewLoadedPoint(ts)
+}
+
+// Headers are not used by the prometheus target
+func (d *simulationDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
+
+// Rewind keeps the simulation going for another pass over its time range
+func (d *simulationDataSource) Rewind() bool {
+	return common.RewindSimulator(d.simulator)
+}
@@ -0,0 +1,70 @@
+package questdb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+// SpecificConfig holds the QuestDB specific loader configuration
+type SpecificConfig struct {
+	ILPBindTo         string        `yaml:"ilp-bind-to" mapstructure:"ilp-bind-to"`
+	ReconnectAttempts int           `yaml:"reconnect-attempts" mapstructure:"reconnect-attempts"`
+	ReconnectBackoff  time.Duration `yaml:"reconnect-backoff" mapstructure:"reconnect-backoff"`
+	WriteTimeout      time.Duration `yaml:"write-timeout" mapstructure:"write-timeout"`
+}
+
+func NewBenchmark(config *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if config.ILPBindTo == "" {
+		return nil, errors.New("ilp-bind-to must be set")
+	}
+	if config.ReconnectAttempts < 0 {
+		return nil, errors.New("reconnect-attempts must not be negative")
+	}
+
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = influx.NewFileDataSource(dataSourceConfig.File.Location, dataSourceConfig.File.ReadAhead)
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = influx.NewSimulationDataSource(simulator)
+	}
+
+	return &benchmark{config: config, ds: ds}, nil
+}
+
+type benchmark struct {
+	config *SpecificConfig
+	ds     targets.DataSource
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &influx.BatchFactory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	if maxPartitions > 1 {
+		return &influx.SeriesIndexer{Partitions: maxPartitions}
+	}
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{config: b.config}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{}
+}
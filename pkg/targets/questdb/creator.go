@@ -0,0 +1,24 @@
+package questdb
+
+import "log"
+
+var fatal = log.Fatalf
+
+// dbCreator is a no-op since QuestDB has a single database; tables are
+// created by the line protocol receiver on their first write.
+type dbCreator struct{}
+
+func (d *dbCreator) Init() {}
+
+// DBExists always reports false so do-abort-on-exist never stops a load.
+func (d *dbCreator) DBExists(dbName string) bool {
+	return false
+}
+
+func (d *dbCreator) CreateDB(dbName string) error {
+	return nil
+}
+
+func (d *dbCreator) RemoveOldDB(dbName string) error {
+	return nil
+}
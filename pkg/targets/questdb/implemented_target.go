@@ -0,0 +1,47 @@
+package questdb
+
+import (
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+func NewTarget() targets.ImplementedTarget {
+	return &questdbTarget{}
+}
+
+type questdbTarget struct {
+}
+
+func (t *questdbTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"ilp-bind-to", "localhost:9009", "QuestDB host:port accepting InfluxDB line protocol over TCP")
+	flagSet.Int(flagPrefix+"reconnect-attempts", 3, "Number of times a worker reconnects and resends a batch after a failed write")
+	flagSet.Duration(flagPrefix+"reconnect-backoff", time.Second, "Time to wait before reconnecting after a failed write")
+	flagSet.Duration(flagPrefix+"write-timeout", 30*time.Second, "Timeout of writing a single batch to the socket")
+}
+
+func (t *questdbTarget) TargetName() string {
+	return constants.FormatQuestDB
+}
+
+// Serializer returns the InfluxDB serializer, QuestDB ingests the InfluxDB
+// line protocol.
+func (t *questdbTarget) Serializer() serialize.PointSerializer {
+	return &influx.Serializer{}
+}
+
+func (t *questdbTarget) Benchmark(
+	_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper,
+) (targets.Benchmark, error) {
+	var config SpecificConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+	return NewBenchmark(&config, dataSourceConfig)
+}
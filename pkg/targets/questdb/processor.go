@@ -0,0 +1,107 @@
+package questdb
+
+import (
+	"log"
+	"net"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+const dialTimeout = 10 * time.Second
+
+// processor writes the batches of a worker over a single, persistent TCP
+// connection to the line protocol receiver of QuestDB.
+type processor struct {
+	config    *SpecificConfig
+	workerNum int
+	conn      net.Conn
+}
+
+func (p *processor) Init(workerNum int, doLoad, _ bool) {
+	p.workerNum = workerNum
+	if !doLoad {
+		return
+	}
+	if err := p.connect(); err != nil {
+		fatal("worker %d could not connect to %s: %v", workerNum, p.config.ILPBindTo, err)
+	}
+}
+
+func (p *processor) Close(doLoad bool) {
+	if doLoad && p.conn != nil {
+		p.conn.Close()
+	}
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	res := p.ProcessBatchWithResult(b, doLoad)
+	if res.Err != nil {
+		fatal("worker %d could not write to %s: %v", p.workerNum, p.config.ILPBindTo, res.Err)
+	}
+	return res.Metrics, res.Rows
+}
+
+// ProcessBatchWithResult fails the batch as a whole when it could not be
+// written after reconnecting
+func (p *processor) ProcessBatchWithResult(b targets.Batch, doLoad bool) targets.BatchResult {
+	batch := b.(*influx.Batch)
+	var res targets.BatchResult
+	if doLoad {
+		if err := p.write(batch.Bytes()); err != nil {
+			res.Fail(batch.Metrics(), uint64(batch.Len()), err, targets.ClassifyError(err))
+			return res
+		}
+	}
+	res.Metrics, res.Rows = batch.Metrics(), uint64(batch.Len())
+	return res
+}
+
+func (p *processor) connect() error {
+	conn, err := net.DialTimeout("tcp", p.config.ILPBindTo, dialTimeout)
+	if err != nil {
+		return err
+	}
+	p.conn = conn
+	return nil
+}
+
+// write sends buf over the connection. When the write fails the connection
+// is re-established and the whole buffer is sent again, up to
+// ReconnectAttempts times, after which the last error is returned. The line
+// protocol has no acknowledgements, so lines written before the failure may
+// be stored twice and a failure detected by the kernel only after the write
+// returned goes unnoticed.
+func (p *processor) write(buf []byte) error {
+	for attempt := 0; ; attempt++ {
+		err := p.tryWrite(buf)
+		if err == nil {
+			return nil
+		}
+		if p.conn != nil {
+			p.conn.Close()
+			p.conn = nil
+		}
+		if attempt >= p.config.ReconnectAttempts {
+			return err
+		}
+		log.Printf("worker %d: write to %s failed, reconnecting: %v", p.workerNum, p.config.ILPBindTo, err)
+		time.Sleep(p.config.ReconnectBackoff)
+	}
+}
+
+func (p *processor) tryWrite(buf []byte) error {
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	if p.config.WriteTimeout > 0 {
+		if err := p.conn.SetWriteDeadline(time.Now().Add(p.config.WriteTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := p.conn.Write(buf)
+	return err
+}
@@ -0,0 +1,165 @@
+package questdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+// stubReceiver accepts connections and collects everything written to them.
+type stubReceiver struct {
+	listener net.Listener
+	mutex    sync.Mutex
+	received bytes.Buffer
+	conns    int
+	wg       sync.WaitGroup
+	done     chan struct{}
+}
+
+func newStubReceiver(t *testing.T) *stubReceiver {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("could not listen: %v", err)
+	}
+	r := &stubReceiver{listener: l, done: make(chan struct{})}
+	go r.serve()
+	return r
+}
+
+func (r *stubReceiver) serve() {
+	defer close(r.done)
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		r.mutex.Lock()
+		r.conns++
+		r.mutex.Unlock()
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			b, _ := ioutil.ReadAll(conn)
+			r.mutex.Lock()
+			r.received.Write(b)
+			r.mutex.Unlock()
+		}()
+	}
+}
+
+// close stops accepting connections and waits for the open ones to be
+// closed by the client.
+func (r *stubReceiver) close() {
+	r.listener.Close()
+	<-r.done
+	r.wg.Wait()
+}
+
+func newTestBatch(lines ...string) *influx.Batch {
+	b := (&influx.BatchFactory{}).New().(*influx.Batch)
+	for _, l := range lines {
+		b.Append(data.NewLoadedPoint([]byte(l)))
+	}
+	return b
+}
+
+func TestProcessBatch(t *testing.T) {
+	r := newStubReceiver(t)
+	p := &processor{config: &SpecificConfig{ILPBindTo: r.listener.Addr().String()}}
+	p.Init(0, true, false)
+
+	b := newTestBatch(
+		"cpu,hostname=host_0 usage_user=1,usage_system=2 1451606400000000000",
+		"mem,hostname=host_0 used=3 1451606400000000000",
+	)
+	metrics, rows := p.ProcessBatch(b, true)
+	if metrics != 3 || rows != 2 {
+		t.Errorf("incorrect counts: got %d metrics %d rows, want 3 metrics 2 rows", metrics, rows)
+	}
+	p.ProcessBatch(newTestBatch("cpu,hostname=host_1 usage_user=4 1451606410000000000"), true)
+	p.Close(true)
+	r.close()
+
+	want := "cpu,hostname=host_0 usage_user=1,usage_system=2 1451606400000000000\n" +
+		"mem,hostname=host_0 used=3 1451606400000000000\n" +
+		"cpu,hostname=host_1 usage_user=4 1451606410000000000\n"
+	if got := r.received.String(); got != want {
+		t.Errorf("incorrect data received:\ngot\n%s\nwant\n%s", got, want)
+	}
+	if r.conns != 1 {
+		t.Errorf("expected a single persistent connection, got %d", r.conns)
+	}
+}
+
+func TestProcessBatchNoLoad(t *testing.T) {
+	p := &processor{config: &SpecificConfig{ILPBindTo: "127.0.0.1:1"}}
+	p.Init(0, false, false)
+	metrics, rows := p.ProcessBatch(newTestBatch("cpu,hostname=host_0 usage_user=1 0"), false)
+	if metrics != 1 || rows != 1 {
+		t.Errorf("incorrect counts: got %d metrics %d rows", metrics, rows)
+	}
+	if p.conn != nil {
+		t.Errorf("expected no connection without load")
+	}
+}
+
+func TestProcessBatchReconnect(t *testing.T) {
+	r := newStubReceiver(t)
+	p := &processor{config: &SpecificConfig{ILPBindTo: r.listener.Addr().String(), ReconnectAttempts: 1}}
+	p.Init(0, true, false)
+
+	// a closed connection fails the next write
+	p.conn.Close()
+	p.ProcessBatch(newTestBatch("cpu,hostname=host_0 usage_user=1 0"), true)
+	p.Close(true)
+	r.close()
+
+	if got, want := r.received.String(), "cpu,hostname=host_0 usage_user=1 0\n"; got != want {
+		t.Errorf("incorrect data received after reconnect: got %q want %q", got, want)
+	}
+	if r.conns != 2 {
+		t.Errorf("expected 2 connections, got %d", r.conns)
+	}
+}
+
+func TestProcessBatchReconnectFails(t *testing.T) {
+	oldFatal := fatal
+	defer func() { fatal = oldFatal }()
+	isCalled := false
+	fatal = func(format string, args ...interface{}) {
+		isCalled = true
+	}
+
+	r := newStubReceiver(t)
+	p := &processor{config: &SpecificConfig{ILPBindTo: r.listener.Addr().String(), ReconnectAttempts: 2}}
+	p.Init(0, true, false)
+	p.conn.Close()
+	r.close()
+
+	p.ProcessBatch(newTestBatch("cpu,hostname=host_0 usage_user=1 0"), true)
+	if !isCalled {
+		t.Errorf("fatal not called when reconnecting fails")
+	}
+}
+
+func TestProcessBatchWithResultReconnectFails(t *testing.T) {
+	r := newStubReceiver(t)
+	p := &processor{config: &SpecificConfig{ILPBindTo: r.listener.Addr().String()}}
+	p.Init(0, true, false)
+	p.conn.Close()
+	r.close()
+
+	res := p.ProcessBatchWithResult(newTestBatch("cpu,hostname=host_0 usage_user=1 0"), true)
+	if res.Metrics != 0 || res.FailedMetrics != 1 || res.FailedRows != 1 {
+		t.Errorf("incorrect counts: got %d metrics %d failed metrics %d failed rows", res.Metrics, res.FailedMetrics, res.FailedRows)
+	}
+	if res.ErrorClass != targets.ErrorClassConnection {
+		t.Errorf("incorrect error class: got %s (%v) want %s", res.ErrorClass, res.Err, targets.ErrorClassConnection)
+	}
+}
@@ -75,3 +75,23 @@ type DataSource interface {
 	NextItem() data.LoadedPoint
 	Headers() *common.GeneratedDataHeaders
 }
+
+// RewindableDataSource is a DataSource that can read its data again, for a
+// load with --loop
+type RewindableDataSource interface {
+	DataSource
+	// Rewind starts the next pass over the data once NextItem ran out of it,
+	// with the timestamps shifted past the ones read before. It returns false
+	// if the data can't be read again.
+	Rewind() bool
+}
+
+// ShardedDataSource is a DataSource that can read only a part of its data,
+// for an agent of a coordinator that splits the load among its agents
+type ShardedDataSource interface {
+	DataSource
+	// Shard limits the data read to the shard-th of shards about equal parts
+	// of it; it is called before NextItem. It returns false if the data can't
+	// be split, e.g. when it is compressed.
+	Shard(shard, shards int) bool
+}
@@ -1,6 +1,8 @@
 package timescaledb
 
 import (
+	"errors"
+
 	"github.com/timescale/tsbs/internal/inputs"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
@@ -10,9 +12,29 @@ const pgxDriver = "pgx"
 const pqDriver = "postgres"
 
 func NewBenchmark(dbName string, opts *LoadingOptions, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if opts.UseNativePartitioning {
+		if opts.UseHypertable {
+			return nil, errors.New("use-native-partitioning can not be combined with use-hypertable, set use-hypertable=false")
+		}
+		if opts.ChunkTime <= 0 {
+			return nil, errors.New("chunk-time must be positive when using native partitioning")
+		}
+	}
+	if opts.UseCompression && !opts.UseHypertable {
+		return nil, errors.New("use-compression requires use-hypertable")
+	}
+	if opts.ContinuousAggregates != "" {
+		if !opts.UseHypertable {
+			return nil, errors.New("continuous-aggregates requires use-hypertable")
+		}
+		if _, err := opts.parseContinuousAggregates(); err != nil {
+			return nil, err
+		}
+	}
+
 	var ds targets.DataSource
 	if dataSourceConfig.Type == source.FileDataSourceType {
-		ds = newFileDataSource(dataSourceConfig.File.Location)
+		ds = newFileDataSource(dataSourceConfig.File.Location, dataSourceConfig.File.ReadAhead)
 	} else {
 		dataGenerator := &inputs.DataGenerator{}
 		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
@@ -0,0 +1,134 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	compressionJobsSQL   = "SELECT job_id FROM timescaledb_information.jobs WHERE proc_name = 'policy_compression' AND hypertable_name = $1"
+	compressedChunksSQL  = "SELECT count(*) FROM timescaledb_information.chunks WHERE hypertable_name = $1 AND is_compressed"
+	hypertableSizeSQLFmt = "SELECT COALESCE(table_bytes, 0), COALESCE(index_bytes, 0), COALESCE(toast_bytes, 0), COALESCE(total_bytes, 0) FROM hypertable_detailed_size('%s')"
+)
+
+// relationSize holds the on-disk size of one or more hypertables in bytes
+type relationSize struct {
+	table, index, toast, total int64
+}
+
+func (s *relationSize) add(o relationSize) {
+	s.table += o.table
+	s.index += o.index
+	s.toast += o.toast
+	s.total += o.total
+}
+
+// addTo adds the sizes to results, with suffix appended to their names
+func (s *relationSize) addTo(results map[string]interface{}, suffix string) {
+	results["tableBytes"+suffix] = s.table
+	results["indexBytes"+suffix] = s.index
+	results["toastBytes"+suffix] = s.toast
+	results["totalBytes"+suffix] = s.total
+}
+
+// getCompressionSQL returns the statements enabling compression on the
+// hypertable, segmented by the partition column and ordered by time, and
+// adding a compression policy if one is configured.
+func (d *dbCreator) getCompressionSQL(hypertable, partitionColumn string) []string {
+	stmts := []string{
+		fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress, timescaledb.compress_segmentby = '%s', timescaledb.compress_orderby = 'time DESC')",
+			hypertable, partitionColumn),
+	}
+	if d.opts.CompressionPolicyAfter > 0 {
+		stmts = append(stmts, fmt.Sprintf("SELECT add_compression_policy('%s', INTERVAL '%d seconds')",
+			hypertable, int64(d.opts.CompressionPolicyAfter/time.Second)))
+	}
+	return stmts
+}
+
+// compressHypertables compresses all hypertables and adds the time it took,
+// the number of compressed chunks and the size of the hypertables before and
+// after compression to results.
+func (d *dbCreator) compressHypertables(db *sql.DB, results map[string]interface{}) {
+	hypertables := d.hypertables()
+	var before, after relationSize
+	for _, h := range hypertables {
+		before.add(hypertableSize(db, h))
+	}
+
+	start := time.Now()
+	for _, h := range hypertables {
+		d.compress(db, h)
+	}
+	took := time.Since(start)
+
+	var chunks int64
+	for _, h := range hypertables {
+		after.add(hypertableSize(db, h))
+		chunks += mustQueryInt(db, compressedChunksSQL, h)
+	}
+
+	results["compressionMillis"] = took.Milliseconds()
+	results["compressedChunks"] = chunks
+	before.addTo(results, "BeforeCompression")
+	after.addTo(results, "AfterCompression")
+}
+
+// compress compresses all chunks of the hypertable that are not compressed
+// yet, either directly or by running its compression policy.
+func (d *dbCreator) compress(db *sql.DB, hypertable string) {
+	if d.opts.CompressionPolicyAfter <= 0 {
+		MustExec(db, fmt.Sprintf("SELECT compress_chunk(c, if_not_compressed => true) FROM show_chunks('%s') c", hypertable))
+		return
+	}
+
+	var jobs []int64
+	r := MustQuery(db, compressionJobsSQL, hypertable)
+	for r.Next() {
+		var job int64
+		if err := r.Scan(&job); err != nil {
+			panic(err)
+		}
+		jobs = append(jobs, job)
+	}
+	r.Close()
+	if len(jobs) == 0 {
+		fatal("no compression policy found for hypertable %s", hypertable)
+	}
+	for _, job := range jobs {
+		MustExec(db, fmt.Sprintf("CALL run_job(%d)", job))
+	}
+}
+
+// hypertables returns the names of the hypertables in the data set, sorted
+func (d *dbCreator) hypertables() []string {
+	var hypertables []string
+	for tableName := range d.ds.Headers().FieldKeys {
+		hypertables = append(hypertables, tableName)
+	}
+	sort.Strings(hypertables)
+	return hypertables
+}
+
+func hypertableSize(db *sql.DB, hypertable string) relationSize {
+	var s relationSize
+	r := MustQuery(db, fmt.Sprintf(hypertableSizeSQLFmt, hypertable))
+	defer r.Close()
+	if r.Next() {
+		if err := r.Scan(&s.table, &s.index, &s.toast, &s.total); err != nil {
+			panic(err)
+		}
+	}
+	return s
+}
+
+// mustQueryInt runs a query returning a single integer or exits on error
+func mustQueryInt(db *sql.DB, query string, args ...interface{}) int64 {
+	var v int64
+	if err := db.QueryRow(query, args...).Scan(&v); err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -176,18 +176,13 @@ func (d *dbCreator) createTableAndIndexes(dbBench *sql.DB, tableName string, fie
 	}
 
 	MustExec(dbBench, fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))
-	MustExec(dbBench, fmt.Sprintf("CREATE TABLE %s (time timestamptz, tags_id integer, %s, additional_tags JSONB DEFAULT NULL)", tableName, strings.Join(fieldDefs, ",")))
+	MustExec(dbBench, d.getCreateTableSQL(tableName, fieldDefs))
 	if d.opts.PartitionIndex {
 		MustExec(dbBench, fmt.Sprintf("CREATE INDEX ON %s(%s, \"time\" DESC)", tableName, partitionColumn))
 	}
 
-	// Only allow one or the other, it's probably never right to have both.
-	// Experimentation suggests (so far) that for 100k devices it is better to
-	// use --time-partition-index for reduced index lock contention.
-	if d.opts.TimePartitionIndex {
-		MustExec(dbBench, fmt.Sprintf("CREATE INDEX ON %s(\"time\" DESC, %s)", tableName, partitionColumn))
-	} else if d.opts.TimeIndex {
-		MustExec(dbBench, fmt.Sprintf("CREATE INDEX ON %s(\"time\" DESC)", tableName))
+	if timeIndexDef := d.getTimeIndexSQL(tableName, partitionColumn); timeIndexDef != "" {
+		MustExec(dbBench, timeIndexDef)
 	}
 
 	for _, indexDef := range indexDefs {
@@ -225,7 +220,70 @@ func (d *dbCreator) createTableAndIndexes(dbBench *sql.DB, tableName string, fie
 		MustExec(dbBench,
 			fmt.Sprintf("SELECT %s('%s'::regclass, 'time'::name, %s, chunk_time_interval => %d, create_default_indexes=>FALSE)",
 				creationCommand, tableName, partitionsOption, d.opts.ChunkTime.Nanoseconds()/1000))
+
+		for _, width := range d.opts.caggWidths(tableName) {
+			MustExec(dbBench, d.getContinuousAggregateSQL(tableName, width))
+		}
+
+		if d.opts.UseCompression {
+			for _, stmt := range d.getCompressionSQL(tableName, partitionColumn) {
+				MustExec(dbBench, stmt)
+			}
+		}
+	}
+}
+
+// getCreateTableSQL returns the statement creating the metrics table. With
+// native partitioning the table is range partitioned by time; the partitions
+// themselves are created by the processors as data arrives.
+func (d *dbCreator) getCreateTableSQL(tableName string, fieldDefs []string) string {
+	partitionBy := ""
+	if d.opts.UseNativePartitioning {
+		partitionBy = " PARTITION BY RANGE (time)"
+	}
+	return fmt.Sprintf("CREATE TABLE %s (time timestamptz, tags_id integer, %s, additional_tags JSONB DEFAULT NULL)%s", tableName, strings.Join(fieldDefs, ","), partitionBy)
+}
+
+// getTimeIndexSQL returns the statement creating the index on the time
+// dimension, or an empty string when no such index is wanted.
+func (d *dbCreator) getTimeIndexSQL(tableName, partitionColumn string) string {
+	// Only allow one or the other, it's probably never right to have both.
+	// Experimentation suggests (so far) that for 100k devices it is better to
+	// use --time-partition-index for reduced index lock contention.
+	// BRIN indexes have no sort order, so DESC is dropped for them.
+	if d.opts.TimePartitionIndex {
+		if d.opts.BRINTimeIndex {
+			return fmt.Sprintf("CREATE INDEX ON %s USING BRIN (\"time\", %s)", tableName, partitionColumn)
+		}
+		return fmt.Sprintf("CREATE INDEX ON %s(\"time\" DESC, %s)", tableName, partitionColumn)
+	} else if d.opts.TimeIndex {
+		if d.opts.BRINTimeIndex {
+			return fmt.Sprintf("CREATE INDEX ON %s USING BRIN (\"time\")", tableName)
+		}
+		return fmt.Sprintf("CREATE INDEX ON %s(\"time\" DESC)", tableName)
+	}
+	return ""
+}
+
+// PostLoad refreshes the continuous aggregates and compresses the loaded data,
+// if enabled, and reports how long that took along with the size of the
+// hypertables before and after compression.
+func (d *dbCreator) PostLoad(dbName string) (map[string]interface{}, error) {
+	if !d.opts.UseCompression && d.opts.ContinuousAggregates == "" {
+		return nil, nil
+	}
+	db := MustConnect(d.driver, d.opts.GetConnectString(dbName))
+	defer db.Close()
+
+	results := make(map[string]interface{})
+	// refresh first, so the aggregates are computed from uncompressed data
+	if d.opts.ContinuousAggregates != "" {
+		d.refreshContinuousAggregates(db, results)
+	}
+	if d.opts.UseCompression {
+		d.compressHypertables(db, results)
 	}
+	return results, nil
 }
 
 func (d *dbCreator) getCreateIndexOnFieldCmds(hypertable, field, idxType string) []string {
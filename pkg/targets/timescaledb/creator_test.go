@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
 )
 
 func TestDBCreatorInit(t *testing.T) {
@@ -204,6 +208,72 @@ func TestDBCreatorGetFieldAndIndexDefinitions(t *testing.T) {
 	}
 }
 
+func TestDBCreatorGetCreateTableSQL(t *testing.T) {
+	fieldDefs := []string{"usage_user DOUBLE PRECISION", "usage_system DOUBLE PRECISION"}
+	cases := []struct {
+		desc string
+		opts *LoadingOptions
+		want string
+	}{
+		{
+			desc: "regular table",
+			opts: &LoadingOptions{},
+			want: "CREATE TABLE cpu (time timestamptz, tags_id integer, usage_user DOUBLE PRECISION,usage_system DOUBLE PRECISION, additional_tags JSONB DEFAULT NULL)",
+		},
+		{
+			desc: "native partitioning",
+			opts: &LoadingOptions{UseNativePartitioning: true},
+			want: "CREATE TABLE cpu (time timestamptz, tags_id integer, usage_user DOUBLE PRECISION,usage_system DOUBLE PRECISION, additional_tags JSONB DEFAULT NULL) PARTITION BY RANGE (time)",
+		},
+	}
+	for _, c := range cases {
+		dbc := &dbCreator{opts: c.opts}
+		if got := dbc.getCreateTableSQL("cpu", fieldDefs); got != c.want {
+			t.Errorf("%s: incorrect sql:\ngot\n%s\nwant\n%s", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestDBCreatorGetTimeIndexSQL(t *testing.T) {
+	cases := []struct {
+		desc string
+		opts *LoadingOptions
+		want string
+	}{
+		{
+			desc: "no time index",
+			opts: &LoadingOptions{},
+			want: "",
+		},
+		{
+			desc: "time index",
+			opts: &LoadingOptions{TimeIndex: true},
+			want: `CREATE INDEX ON cpu("time" DESC)`,
+		},
+		{
+			desc: "time partition index takes precedence",
+			opts: &LoadingOptions{TimeIndex: true, TimePartitionIndex: true},
+			want: `CREATE INDEX ON cpu("time" DESC, tags_id)`,
+		},
+		{
+			desc: "BRIN time index",
+			opts: &LoadingOptions{TimeIndex: true, BRINTimeIndex: true},
+			want: `CREATE INDEX ON cpu USING BRIN ("time")`,
+		},
+		{
+			desc: "BRIN time partition index",
+			opts: &LoadingOptions{TimePartitionIndex: true, BRINTimeIndex: true},
+			want: `CREATE INDEX ON cpu USING BRIN ("time", tags_id)`,
+		},
+	}
+	for _, c := range cases {
+		dbc := &dbCreator{opts: c.opts}
+		if got := dbc.getTimeIndexSQL("cpu", "tags_id"); got != c.want {
+			t.Errorf("%s: incorrect sql: got %s want %s", c.desc, got, c.want)
+		}
+	}
+}
+
 func TestExtractTagNamesAndTypes(t *testing.T) {
 	names, types := extractTagNamesAndTypes([]string{"tag1 type1", "tag2 type2"})
 	if names[0] != "tag1" || names[1] != "tag2" {
@@ -254,3 +324,143 @@ func TestGenerateTagsTableQueryPanicOnWrongType(t *testing.T) {
 
 	t.Fatalf("test should have stopped at this point")
 }
+
+func TestDBCreatorGetCompressionSQL(t *testing.T) {
+	cases := []struct {
+		desc string
+		opts *LoadingOptions
+		want []string
+	}{
+		{
+			desc: "compress after load",
+			opts: &LoadingOptions{UseCompression: true},
+			want: []string{
+				"ALTER TABLE cpu SET (timescaledb.compress, timescaledb.compress_segmentby = 'tags_id', timescaledb.compress_orderby = 'time DESC')",
+			},
+		},
+		{
+			desc: "compression policy",
+			opts: &LoadingOptions{UseCompression: true, CompressionPolicyAfter: time.Hour},
+			want: []string{
+				"ALTER TABLE cpu SET (timescaledb.compress, timescaledb.compress_segmentby = 'tags_id', timescaledb.compress_orderby = 'time DESC')",
+				"SELECT add_compression_policy('cpu', INTERVAL '3600 seconds')",
+			},
+		},
+	}
+	for _, c := range cases {
+		dbc := &dbCreator{opts: c.opts}
+		got := dbc.getCompressionSQL("cpu", "tags_id")
+		if len(got) != len(c.want) {
+			t.Errorf("%s: incorrect number of statements: got %d want %d", c.desc, len(got), len(c.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%s: incorrect statement %d:\ngot\n%s\nwant\n%s", c.desc, i, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+func TestDBCreatorPostLoadWithoutCompression(t *testing.T) {
+	dbc := &dbCreator{opts: &LoadingOptions{}}
+	results, err := dbc.PostLoad("benchmark")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if results != nil {
+		t.Errorf("expected no results without compression, got %v", results)
+	}
+}
+
+func TestCaggName(t *testing.T) {
+	cases := []struct {
+		width time.Duration
+		want  string
+	}{
+		{width: time.Minute, want: "cpu_1m"},
+		{width: time.Hour, want: "cpu_1h"},
+		{width: 90 * time.Minute, want: "cpu_90m"},
+		{width: 30 * time.Second, want: "cpu_30s"},
+	}
+	for _, c := range cases {
+		if got := caggName("cpu", c.width); got != c.want {
+			t.Errorf("incorrect name for %v: got %s want %s", c.width, got, c.want)
+		}
+	}
+}
+
+func TestLoadingOptionsCaggWidths(t *testing.T) {
+	opts := &LoadingOptions{ContinuousAggregates: "1m, 1h", ContinuousAggregateTables: "cpu,mem"}
+	if got := opts.caggWidths("cpu"); len(got) != 2 || got[0] != time.Minute || got[1] != time.Hour {
+		t.Errorf("incorrect widths for cpu: %v", got)
+	}
+	if got := opts.caggWidths("disk"); got != nil {
+		t.Errorf("expected no widths for disk, got %v", got)
+	}
+
+	for _, invalid := range []string{"1x", "500ms"} {
+		opts.ContinuousAggregates = invalid
+		if _, err := opts.parseContinuousAggregates(); err == nil {
+			t.Errorf("expected error for width %s", invalid)
+		}
+	}
+}
+
+func TestDBCreatorGetContinuousAggregateSQL(t *testing.T) {
+	tableCols[tagsKey] = []string{"hostname"}
+	tableCols["cpu"] = []string{"usage_user", "usage_system"}
+	cases := []struct {
+		desc       string
+		inTableTag bool
+		want       string
+	}{
+		{
+			desc: "tags table only",
+			want: "CREATE MATERIALIZED VIEW cpu_1m WITH (timescaledb.continuous) AS " +
+				"SELECT time_bucket('60 seconds', time) AS bucket, tags_id, " +
+				"max(usage_user) AS max_usage_user, avg(usage_user) AS avg_usage_user, " +
+				"max(usage_system) AS max_usage_system, avg(usage_system) AS avg_usage_system " +
+				"FROM cpu GROUP BY bucket, tags_id WITH NO DATA",
+		},
+		{
+			desc:       "in table tag",
+			inTableTag: true,
+			want: "CREATE MATERIALIZED VIEW cpu_1m WITH (timescaledb.continuous) AS " +
+				"SELECT time_bucket('60 seconds', time) AS bucket, tags_id, hostname, " +
+				"max(usage_user) AS max_usage_user, avg(usage_user) AS avg_usage_user, " +
+				"max(usage_system) AS max_usage_system, avg(usage_system) AS avg_usage_system " +
+				"FROM cpu GROUP BY bucket, tags_id, hostname WITH NO DATA",
+		},
+	}
+	for _, c := range cases {
+		dbc := &dbCreator{opts: &LoadingOptions{InTableTag: c.inTableTag}}
+		if got := dbc.getContinuousAggregateSQL("cpu", time.Minute); got != c.want {
+			t.Errorf("%s: incorrect SQL:\ngot\n%s\nwant\n%s", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestDBCreatorTrackPoint(t *testing.T) {
+	tableCols[tagsKey] = []string{"hostname", "region"}
+	dbc := &dbCreator{opts: &LoadingOptions{}}
+	stats := targets.NewDataStats()
+	points := []*point{
+		{hypertable: "cpu", row: &insertData{tags: "hostname=host_0,region=eu", fields: "1451606400000000001,1,2"}},
+		{hypertable: "cpu", row: &insertData{tags: "hostname=host_1,region=eu,extra=x", fields: "1451606410000000000,1,2"}},
+		{hypertable: "cpu", row: &insertData{tags: "hostname=host_0,region=eu,extra=y", fields: "1451606420000000000,1,2"}},
+	}
+	for _, p := range points {
+		dbc.TrackPoint(data.NewLoadedPoint(p), stats)
+	}
+
+	want := targets.MeasurementStats{
+		Rows:    3,
+		MinTime: time.Unix(1451606400, 0),
+		MaxTime: time.Unix(1451606420, 0),
+		TagSets: 2,
+	}
+	if got := stats.Get("cpu"); got != want {
+		t.Errorf("incorrect stats: got %+v want %+v", got, want)
+	}
+}
@@ -2,6 +2,8 @@ package timescaledb
 
 import (
 	"bufio"
+	"bytes"
+	"strconv"
 	"strings"
 
 	"github.com/timescale/tsbs/load"
@@ -10,14 +12,19 @@ import (
 	"github.com/timescale/tsbs/pkg/targets"
 )
 
-func newFileDataSource(fileName string) targets.DataSource {
-	br := load.GetBufferedReader(fileName)
-	return &fileDataSource{scanner: bufio.NewScanner(br)}
+func newFileDataSource(fileName string, readAhead int) targets.DataSource {
+	br := load.GetRewindableReader(fileName, readAhead)
+	return &fileDataSource{reader: br, scanner: bufio.NewScanner(br)}
 }
 
+// tagsPrefix starts the line of tags of every row
+var tagsPrefix = []byte(tagsKey + ",")
+
 type fileDataSource struct {
+	reader  *load.RewindableReader
 	scanner *bufio.Scanner
 	headers *common.GeneratedDataHeaders
+	shift   data.TimeShift
 }
 
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
@@ -83,6 +90,10 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	newPoint := &insertData{}
 	ok := d.scanner.Scan()
 	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+		if d.reader.NextFile() {
+			d.readHeadersAgain()
+			return d.NextItem()
+		}
 		return data.LoadedPoint{}
 	} else if !ok {
 		fatal("scan error: %v", d.scanner.Err())
@@ -106,10 +117,58 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	}
 	parts = strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
 	prefix = parts[0]
-	newPoint.fields = parts[1]
+	newPoint.fields = d.shiftFields(parts[1])
 
 	return data.NewLoadedPoint(&point{
 		hypertable: prefix,
 		row:        newPoint,
 	})
 }
+
+// Shard reads only the rows of the shard-th of shards byte ranges of the data
+// file after its headers, if it is a single uncompressed file. Each row starts
+// with its line of tags.
+func (d *fileDataSource) Shard(shard, shards int) bool {
+	isStart := func(line []byte) bool { return bytes.HasPrefix(line, tagsPrefix) }
+	if !d.reader.ShardLines(shard, shards, load.LineShards{Header: true, IsStart: isStart}) {
+		return false
+	}
+	d.readHeadersAgain()
+	return true
+}
+
+// Rewind reads the file again, after its headers, with the timestamps shifted
+// past the ones read before
+func (d *fileDataSource) Rewind() bool {
+	if !d.shift.Next() || !d.reader.Rewind() {
+		return false
+	}
+	d.readHeadersAgain()
+	return true
+}
+
+// readHeadersAgain reads past the headers each data file starts with, once the
+// reader starts a file again or moves on to the next one
+func (d *fileDataSource) readHeadersAgain() {
+	d.scanner = bufio.NewScanner(d.reader)
+	d.headers = nil
+	d.Headers()
+}
+
+// shiftFields shifts the timestamp the fields of a row start with
+func (d *fileDataSource) shiftFields(fields string) string {
+	i := strings.IndexByte(fields, ',')
+	if i < 0 {
+		i = len(fields)
+	}
+	ns, err := strconv.ParseInt(fields[:i], 10, 64)
+	if err != nil {
+		fatal("invalid timestamp %q: %v", fields[:i], err)
+		return fields
+	}
+	shifted := d.shift.Shift(ns)
+	if shifted == ns {
+		return fields
+	}
+	return strconv.FormatInt(shifted, 10) + fields[i:]
+}
@@ -49,15 +49,21 @@ func (t *timescaleTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.
 	flagSet.Bool(flagPrefix+"log-batches", false, "Whether to time individual batches.")
 
 	flagSet.Bool(flagPrefix+"use-hypertable", true, "Whether to make the table a hypertable. Set this flag to false to check input write speed against regular PostgreSQL.")
+	flagSet.Bool(flagPrefix+"use-native-partitioning", false, "Whether to partition the table by time with PostgreSQL declarative partitioning, one partition per chunk-time. Requires use-hypertable=false.")
 	flagSet.Bool(flagPrefix+"use-jsonb-tags", false, "Whether tags should be stored as JSONB (instead of a separate table with schema)")
 	flagSet.Bool(flagPrefix+"in-table-partition-tag", false, "Whether the partition key (e.g. hostname) should also be in the metrics hypertable")
 
 	flagSet.Int(flagPrefix+"replication-factor", 0, "Setting replication factor >= 1 will create a distributed hypertable")
 	flagSet.Int(flagPrefix+"partitions", 0, "Number of partitions")
 	flagSet.Duration(flagPrefix+"chunk-time", 12*time.Hour, "Duration that each chunk should represent, e.g., 12h")
+	flagSet.Bool(flagPrefix+"use-compression", false, "Whether to enable native compression on the hypertables (segmented by the partition column, ordered by time) and compress all chunks after the load")
+	flagSet.String(flagPrefix+"continuous-aggregates", "", "Comma-separated bucket widths of continuous aggregates to create and refresh after the load, e.g. 1m,1h")
+	flagSet.String(flagPrefix+"continuous-aggregate-tables", "cpu", "Comma-separated hypertables to create the continuous aggregates on")
+	flagSet.Duration(flagPrefix+"compression-policy-after", 0, "If > 0, compress with a compression policy for chunks older than this instead, the policy is run once after the load")
 
 	flagSet.Bool(flagPrefix+"time-index", true, "Whether to build an index on the time dimension")
 	flagSet.Bool(flagPrefix+"time-partition-index", false, "Whether to build an index on the time dimension, compounded with partition")
+	flagSet.Bool(flagPrefix+"brin-time-index", false, "Whether to build the index on the time dimension (and partition) as a BRIN index instead of a B-tree")
 	flagSet.Bool(flagPrefix+"partition-index", true, "Whether to build an index on the partition key")
 	flagSet.String(flagPrefix+"field-index", ValueTimeIdx, "index types for tags (comma delimited)")
 	flagSet.Int(flagPrefix+"field-index-count", 0, "Number of indexed fields (-1 for all)")
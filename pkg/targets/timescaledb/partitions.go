@@ -0,0 +1,96 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const partitionSuffixFmt = "20060102_150405"
+
+// partitionCache keeps track of the time partitions already created for each
+// natively partitioned table. It is shared by all workers, since any worker may
+// receive the first row of a partition.
+type partitionCache struct {
+	// m maps a partitionKey to the *partition created for it
+	m sync.Map
+}
+
+// partitionKey is a partition of a table, by the unix nanoseconds of its start
+type partitionKey struct {
+	table string
+	start int64
+}
+
+// partition is created once, by the first worker to need it, while the other
+// workers needing it wait for it
+type partition struct {
+	once sync.Once
+	err  error
+}
+
+func newPartitionCache() *partitionCache {
+	return &partitionCache{}
+}
+
+var globalPartitionCache = newPartitionCache()
+
+// execer runs a statement, e.g. a *sql.DB
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// ensure creates the partitions of table covering the time (first column) of
+// every row that do not exist yet. Partitions are width wide and aligned to the
+// unix epoch, like the chunks of a hypertable. A partition that could not be
+// created is tried again by the next batch needing it.
+func (c *partitionCache) ensure(db execer, table string, rows [][]interface{}, width time.Duration) error {
+	seen := make(map[int64]bool)
+	var starts []time.Time
+	for _, r := range rows {
+		start := partitionStart(r[0].(time.Time), width)
+		if !seen[start.UnixNano()] {
+			seen[start.UnixNano()] = true
+			starts = append(starts, start)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	for _, start := range starts {
+		key := partitionKey{table: table, start: start.UnixNano()}
+		v, ok := c.m.Load(key)
+		if !ok {
+			v, _ = c.m.LoadOrStore(key, &partition{})
+		}
+		part := v.(*partition)
+		part.once.Do(func() {
+			_, part.err = db.Exec(createPartitionSQL(table, start, width))
+		})
+		if part.err != nil {
+			c.m.CompareAndDelete(key, part)
+			return fmt.Errorf("could not create partition of %s at %s: %w", table, start.Format(time.RFC3339), part.err)
+		}
+	}
+	return nil
+}
+
+// partitionStart returns the start of the partition of the given width ts
+// belongs to.
+func partitionStart(ts time.Time, width time.Duration) time.Time {
+	n, w := ts.UnixNano(), width.Nanoseconds()
+	start := n - n%w
+	if n%w < 0 {
+		start -= w
+	}
+	return time.Unix(0, start).UTC()
+}
+
+// createPartitionSQL returns the statement creating the partition of table
+// starting at start. IF NOT EXISTS allows several loaders to share a table.
+func createPartitionSQL(table string, start time.Time, width time.Duration) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s_p%s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+		table, start.Format(partitionSuffixFmt), table,
+		start.Format(time.RFC3339Nano), start.Add(width).Format(time.RFC3339Nano))
+}
@@ -0,0 +1,120 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPartitionStart(t *testing.T) {
+	cases := []struct {
+		desc  string
+		ts    time.Time
+		width time.Duration
+		want  time.Time
+	}{
+		{
+			desc:  "start of partition",
+			ts:    time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC),
+			width: 12 * time.Hour,
+			want:  time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:  "inside partition",
+			ts:    time.Date(2016, 1, 1, 11, 59, 59, 999, time.UTC),
+			width: 12 * time.Hour,
+			want:  time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:  "other time zone",
+			ts:    time.Date(2016, 1, 2, 1, 30, 0, 0, time.FixedZone("CET", 3600)),
+			width: time.Hour,
+			want:  time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:  "before epoch",
+			ts:    time.Date(1969, 12, 31, 23, 0, 0, 0, time.UTC),
+			width: 24 * time.Hour,
+			want:  time.Date(1969, 12, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, c := range cases {
+		if got := partitionStart(c.ts, c.width); !got.Equal(c.want) {
+			t.Errorf("%s: incorrect start: got %v want %v", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestCreatePartitionSQL(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	want := "CREATE TABLE IF NOT EXISTS cpu_p20160101_120000 PARTITION OF cpu FOR VALUES FROM ('2016-01-01T12:00:00Z') TO ('2016-01-02T00:00:00Z')"
+	if got := createPartitionSQL("cpu", start, 12*time.Hour); got != want {
+		t.Errorf("incorrect sql:\ngot\n%s\nwant\n%s", got, want)
+	}
+}
+
+// testExecer records the statements run, failing them while err is set
+type testExecer struct {
+	mutex sync.Mutex
+	stmts []string
+	err   error
+}
+
+func (e *testExecer) Exec(query string, args ...interface{}) (sql.Result, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.stmts = append(e.stmts, query)
+	return nil, e.err
+}
+
+func TestPartitionCacheEnsure(t *testing.T) {
+	c := newPartitionCache()
+	db := &testExecer{}
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := [][]interface{}{
+		{start.Add(2 * time.Hour)},
+		{start.Add(time.Hour)},
+		{start.Add(2*time.Hour + time.Minute)},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.ensure(db, "cpu", rows, time.Hour); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	// each partition is created once, by one of the workers
+	want := []string{
+		createPartitionSQL("cpu", start.Add(time.Hour), time.Hour),
+		createPartitionSQL("cpu", start.Add(2*time.Hour), time.Hour),
+	}
+	if len(db.stmts) != len(want) || db.stmts[0] != want[0] || db.stmts[1] != want[1] {
+		t.Errorf("incorrect statements:\ngot\n%q\nwant\n%q", db.stmts, want)
+	}
+}
+
+func TestPartitionCacheEnsureError(t *testing.T) {
+	c := newPartitionCache()
+	errCreate := errors.New("could not create")
+	db := &testExecer{err: errCreate}
+	rows := [][]interface{}{{time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)}}
+
+	if err := c.ensure(db, "cpu", rows, time.Hour); !errors.Is(err, errCreate) {
+		t.Errorf("incorrect error: got %v want %v", err, errCreate)
+	}
+	// the partition that failed is created by the next batch
+	db.err = nil
+	if err := c.ensure(db, "cpu", rows, time.Hour); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(db.stmts) != 2 {
+		t.Errorf("partition not created again after failing: got %d statements want 2", len(db.stmts))
+	}
+}
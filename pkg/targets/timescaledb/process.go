@@ -5,9 +5,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -183,7 +183,10 @@ func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]s
 	return tagRows, dataRows, numMetrics
 }
 
-func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
+// processCSI inserts the rows of a hypertable, with any tags not inserted
+// yet, and returns the number of metrics in the rows and the error of the
+// insert, if any
+func (p *processor) processCSI(hypertable string, rows []*insertData) (uint64, error) {
 	colLen := len(tableCols[hypertable]) + numExtraCols
 	if p.opts.InTableTag {
 		colLen++
@@ -222,11 +225,27 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 	}
 	cols = append(cols, tableCols[hypertable]...)
 
+	if p.opts.UseNativePartitioning {
+		if err := globalPartitionCache.ensure(p._db, hypertable, dataRows, p.opts.ChunkTime); err != nil {
+			return numMetrics, err
+		}
+	}
+
+	return numMetrics, p.insertData(hypertable, cols, dataRows)
+}
+
+// insertData writes the data rows of a hypertable in a single transaction,
+// which is rolled back if any of them can't be written
+func (p *processor) insertData(hypertable string, cols []string, dataRows [][]interface{}) error {
 	if p.opts.ForceTextFormat {
-		tx := MustBegin(p._db)
+		tx, err := p._db.Begin()
+		if err != nil {
+			return err
+		}
 		stmt, err := tx.Prepare(pq.CopyIn(hypertable, cols...))
 		if err != nil {
-			panic(err)
+			tx.Rollback()
+			return err
 		}
 
 		for _, r := range dataRows {
@@ -234,57 +253,81 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 		}
 		_, err = stmt.Exec()
 		if err != nil {
-			panic(err)
+			stmt.Close()
+			tx.Rollback()
+			return err
 		}
 
 		err = stmt.Close()
 		if err != nil {
-			panic(err)
+			tx.Rollback()
+			return err
 		}
 
-		err = tx.Commit()
+		return tx.Commit()
+	}
+
+	if !p.opts.UseInsert {
+		rows := pgx.CopyFromRows(dataRows)
+		inserted, err := p._pgxConn.CopyFrom(context.Background(), pgx.Identifier{hypertable}, cols, rows)
 		if err != nil {
-			panic(err)
+			return err
 		}
-	} else {
-		if !p.opts.UseInsert {
-			rows := pgx.CopyFromRows(dataRows)
-			inserted, err := p._pgxConn.CopyFrom(context.Background(), pgx.Identifier{hypertable}, cols, rows)
 
-			if err != nil {
-				panic(err)
-			}
+		if inserted != int64(len(dataRows)) {
+			return fmt.Errorf("failed to insert all the data: expected %d rows, got %d", len(dataRows), inserted)
+		}
+		return nil
+	}
 
-			if inserted != int64(len(dataRows)) {
-				fmt.Fprintf(os.Stderr, "Failed to insert all the data! Expected: %d, Got: %d", len(dataRows), inserted)
-				os.Exit(1)
-			}
-		} else {
-			tx := MustBegin(p._db)
-			var stmt *sql.Stmt
-			var err error
+	tx, err := p._db.Begin()
+	if err != nil {
+		return err
+	}
+	stmtString := genBatchInsertStmt(hypertable, cols, len(dataRows))
+	stmt, err := tx.Prepare(stmtString)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-			stmtString := genBatchInsertStmt(hypertable, cols, len(dataRows))
-			stmt, err = tx.Prepare(stmtString)
+	_, err = stmt.Exec(flatten(dataRows)...)
+	if err != nil {
+		stmt.Close()
+		tx.Rollback()
+		return err
+	}
 
-			_, err = stmt.Exec(flatten(dataRows)...)
-			if err != nil {
-				panic(err)
-			}
+	err = stmt.Close()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-			err = stmt.Close()
-			if err != nil {
-				panic(err)
-			}
+	return tx.Commit()
+}
 
-			err = tx.Commit()
-			if err != nil {
-				panic(err)
-			}
-		}
+// classifyError tells the errors the server reports by their SQLSTATE:
+// connection exceptions and cancelled statements from rejected data. Other
+// errors are classified by targets.ClassifyError.
+func classifyError(err error) targets.ErrorClass {
+	var pqErr *pq.Error
+	var pgErr interface{ SQLState() string }
+	code := ""
+	if errors.As(err, &pqErr) {
+		code = string(pqErr.Code)
+	} else if errors.As(err, &pgErr) {
+		code = pgErr.SQLState()
 	}
-
-	return numMetrics
+	switch {
+	case code == "":
+		return targets.ClassifyError(err)
+	case strings.HasPrefix(code, "08"):
+		return targets.ErrorClassConnection
+	case code == "57014":
+		return targets.ErrorClassTimeout
+	}
+	return targets.ErrorClassRejected
 }
 
 func newProcessor(opts *LoadingOptions, driver, dbName string) *processor {
@@ -382,14 +425,31 @@ func (p *processor) Close(doLoad bool) {
 }
 
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	res := p.ProcessBatchWithResult(b, doLoad)
+	if res.Err != nil {
+		panic(res.Err)
+	}
+	return res.Metrics, res.Rows
+}
+
+// ProcessBatchWithResult inserts the rows of each hypertable in its own
+// transaction, so when an insert fails only the rows of that hypertable are
+// failed
+func (p *processor) ProcessBatchWithResult(b targets.Batch, doLoad bool) targets.BatchResult {
 	batches := b.(*hypertableArr)
-	rowCnt := 0
-	metricCnt := uint64(0)
+	var res targets.BatchResult
 	for hypertable, rows := range batches.m {
-		rowCnt += len(rows)
-		if doLoad {
+		if !doLoad {
+			res.Rows += uint64(len(rows))
+		} else {
 			start := time.Now()
-			metricCnt += p.processCSI(hypertable, rows)
+			metricCnt, err := p.processCSI(hypertable, rows)
+			if err != nil {
+				res.Fail(metricCnt, uint64(len(rows)), err, classifyError(err))
+				continue
+			}
+			res.Metrics += metricCnt
+			res.Rows += uint64(len(rows))
 
 			if p.opts.LogBatches {
 				now := time.Now()
@@ -401,7 +461,7 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 	}
 	batches.m = map[string][]*insertData{}
 	batches.cnt = 0
-	return metricCnt, uint64(rowCnt)
+	return res
 }
 func convertValsToSQLBasedOnType(values []string, types []string) []string {
 	return convertValsToBasedOnType(values, types, "'", "NULL")
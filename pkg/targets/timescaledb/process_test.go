@@ -1,10 +1,16 @@
 package timescaledb
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
 	"strconv"
 	"testing"
 	"time"
+
+	"github.com/lib/pq"
+	"github.com/timescale/tsbs/pkg/targets"
 )
 
 func TestSubsystemTagsToJSON(t *testing.T) {
@@ -299,3 +305,22 @@ func TestConvertValsToSQLBasedOnType(t *testing.T) {
 		t.Errorf("error converting to sql values\nexpected: %v\ngot: %v", expected, converted)
 	}
 }
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		desc string
+		err  error
+		want targets.ErrorClass
+	}{
+		{desc: "connection failure", err: &pq.Error{Code: "08006"}, want: targets.ErrorClassConnection},
+		{desc: "statement timeout", err: &pq.Error{Code: "57014"}, want: targets.ErrorClassTimeout},
+		{desc: "not null violation", err: fmt.Errorf("insert: %w", &pq.Error{Code: "23502"}), want: targets.ErrorClassRejected},
+		{desc: "broken connection", err: io.ErrUnexpectedEOF, want: targets.ErrorClassConnection},
+		{desc: "unknown", err: errors.New("unknown"), want: targets.ErrorClassOther},
+	}
+	for _, c := range cases {
+		if got := classifyError(c.err); got != c.want {
+			t.Errorf("%s: incorrect class: got %s want %s", c.desc, got, c.want)
+		}
+	}
+}
@@ -16,18 +16,26 @@ type LoadingOptions struct {
 	Port            string
 	ConnDB          string `yaml:"admin-db-name" mapstructure:"admin-db-name"`
 
-	UseHypertable bool `yaml:"use-hypertable" mapstructure:"use-hypertable"`
-	LogBatches    bool `yaml:"log-batches" mapstructure:"log-batches"`
-	UseJSON       bool `yaml:"use-jsonb-tags" mapstructure:"use-jsonb-tags"`
-	InTableTag    bool `yaml:"in-table-partition-tag" mapstructure:"in-table-partition-tag"`
+	UseHypertable         bool `yaml:"use-hypertable" mapstructure:"use-hypertable"`
+	UseNativePartitioning bool `yaml:"use-native-partitioning" mapstructure:"use-native-partitioning"`
+	LogBatches            bool `yaml:"log-batches" mapstructure:"log-batches"`
+	UseJSON               bool `yaml:"use-jsonb-tags" mapstructure:"use-jsonb-tags"`
+	InTableTag            bool `yaml:"in-table-partition-tag" mapstructure:"in-table-partition-tag"`
 
 	NumberPartitions  int           `yaml:"partitions" mapstructure:"partitions"`
 	PartitionColumn   string        `yaml:"partition-column" mapstructure:"partition-column"`
 	ReplicationFactor int           `yaml:"replication-factor" mapstructure:"replication-factor"`
 	ChunkTime         time.Duration `yaml:"chunk-time" mapstructure:"chunk-time"`
 
+	UseCompression         bool          `yaml:"use-compression" mapstructure:"use-compression"`
+	CompressionPolicyAfter time.Duration `yaml:"compression-policy-after" mapstructure:"compression-policy-after"`
+
+	ContinuousAggregates      string `yaml:"continuous-aggregates" mapstructure:"continuous-aggregates"`
+	ContinuousAggregateTables string `yaml:"continuous-aggregate-tables" mapstructure:"continuous-aggregate-tables"`
+
 	TimeIndex          bool   `yaml:"time-index" mapstructure:"time-index"`
 	TimePartitionIndex bool   `yaml:"time-partition-index" mapstructure:"time-partition-index"`
+	BRINTimeIndex      bool   `yaml:"brin-time-index" mapstructure:"brin-time-index"`
 	PartitionIndex     bool   `yaml:"partition-index" mapstructure:"partition-index"`
 	FieldIndex         string `yaml:"field-index" mapstructure:"field-index"`
 	FieldIndexCount    int    `yaml:"field-index-count" mapstructure:"field-index-count"`
@@ -41,6 +49,40 @@ type LoadingOptions struct {
 	UseInsert          bool     `yaml:"use-insert" mapstructure:"use-insert"`
 }
 
+// parseContinuousAggregates returns the bucket widths of the continuous
+// aggregates to create, parsed from the comma-separated ContinuousAggregates
+func (o *LoadingOptions) parseContinuousAggregates() ([]time.Duration, error) {
+	var widths []time.Duration
+	for _, w := range strings.Split(o.ContinuousAggregates, ",") {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		width, err := time.ParseDuration(w)
+		if err != nil {
+			return nil, fmt.Errorf("invalid continuous aggregate width '%s': %v", w, err)
+		}
+		if width < time.Second || width%time.Second != 0 {
+			return nil, fmt.Errorf("continuous aggregate width '%s' must be a whole number of seconds", w)
+		}
+		widths = append(widths, width)
+	}
+	return widths, nil
+}
+
+// caggWidths returns the bucket widths of the continuous aggregates to create
+// on the given table, none if the table is not one of ContinuousAggregateTables
+func (o *LoadingOptions) caggWidths(tableName string) []time.Duration {
+	for _, t := range strings.Split(o.ContinuousAggregateTables, ",") {
+		if strings.TrimSpace(t) == tableName {
+			// validated when creating the benchmark
+			widths, _ := o.parseContinuousAggregates()
+			return widths
+		}
+	}
+	return nil
+}
+
 func (o *LoadingOptions) GetConnectString(dbName string) string {
 	// User might be passing in host=hostname the connect string out of habit which may override the
 	// multi host configuration. Same for dbname= and user=. This sanitizes that.
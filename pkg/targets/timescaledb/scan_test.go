@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -264,3 +267,59 @@ func TestFileDataSourceHeaders(t *testing.T) {
 		}
 	}
 }
+
+func TestFileDataSourceRewind(t *testing.T) {
+	f, err := ioutil.TempFile("", "timescaledb_data_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("tags,hostname string\ncpu,usage_user\n\n" +
+		"tags,hostname=host_0\ncpu,1000,1\n" +
+		"tags,hostname=host_0\ncpu,2000,2\n")
+	f.Close()
+
+	ds := newFileDataSource(f.Name(), 0).(*fileDataSource)
+	ds.Headers()
+	var fields []string
+	for pass := 0; pass < 2; pass++ {
+		if pass > 0 && !ds.Rewind() {
+			t.Fatalf("could not rewind for pass %d", pass)
+		}
+		for p := ds.NextItem(); p.Data != nil; p = ds.NextItem() {
+			fields = append(fields, p.Data.(*point).row.fields)
+		}
+	}
+
+	want := []string{"1000,1", "2000,2", "3000,1", "4000,2"}
+	if got := strings.Join(fields, " "); got != strings.Join(want, " ") {
+		t.Errorf("incorrect fields: got %s want %s", got, strings.Join(want, " "))
+	}
+}
+
+func TestFileDataSourceFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "timescaledb_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// each file starts with the headers
+	for i, ts := range []string{"1000", "2000"} {
+		data := "tags,hostname string\ncpu,usage_user\n\n" +
+			"tags,hostname=host_0\ncpu," + ts + ",1\n"
+		name := filepath.Join(dir, fmt.Sprintf("data_%d", i))
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ds := newFileDataSource(filepath.Join(dir, "data_*"), 1).(*fileDataSource)
+	ds.Headers()
+	var fields []string
+	for p := ds.NextItem(); p.Data != nil; p = ds.NextItem() {
+		fields = append(fields, p.Data.(*point).row.fields)
+	}
+	if got, want := strings.Join(fields, " "), "1000,1 2000,1"; got != want {
+		t.Errorf("incorrect fields: got %s want %s", got, want)
+	}
+}
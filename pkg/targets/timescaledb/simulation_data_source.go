@@ -76,3 +76,8 @@ func (d *simulationDataSource) NextItem() data.LoadedPoint {
 		row:        newLoadPoint,
 	})
 }
+
+// Rewind keeps the simulation going for another pass over its time range
+func (d *simulationDataSource) Rewind() bool {
+	return common.RewindSimulator(d.simulator)
+}
@@ -0,0 +1,44 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+const (
+	hypertableTotalSizeSQL = "SELECT COALESCE(hypertable_size($1::regclass), 0)"
+	// tableTotalSizeSQL sums a table and its partitions, if it has any
+	tableTotalSizeSQL = "SELECT COALESCE(sum(pg_total_relation_size(rel)), 0) FROM " +
+		"(SELECT $1::regclass AS rel UNION ALL SELECT inhrelid::regclass FROM pg_inherits WHERE inhparent = $1::regclass) AS rels"
+	tagsTableSizeSQL = "SELECT COALESCE(pg_total_relation_size(to_regclass('tags')), 0)"
+)
+
+// StorageBytes returns the size of the data tables, with all their chunks or
+// partitions, indexes and TOAST, and of the tags table
+func (d *dbCreator) StorageBytes(dbName string) (int64, error) {
+	db, err := sql.Open(d.driver, d.opts.GetConnectString(dbName))
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	sizeSQL := tableTotalSizeSQL
+	if d.opts.UseHypertable {
+		sizeSQL = hypertableTotalSizeSQL
+	}
+
+	var total int64
+	for _, table := range d.hypertables() {
+		var size int64
+		if err := db.QueryRow(sizeSQL, table).Scan(&size); err != nil {
+			return 0, fmt.Errorf("could not get size of table %s: %v", table, err)
+		}
+		total += size
+	}
+
+	var tagsSize int64
+	if err := db.QueryRow(tagsTableSizeSQL).Scan(&tagsSize); err != nil {
+		return 0, fmt.Errorf("could not get size of the tags table: %v", err)
+	}
+	return total + tagsSize, nil
+}
@@ -0,0 +1,56 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const verifySQLFmt = "SELECT count(*), min(time), max(time), count(DISTINCT tags_id) FROM %s"
+
+// TrackPoint adds the row of the point to stats. Every distinct set of common
+// tags is stored once in the tags table, so it is counted as one tag set.
+func (d *dbCreator) TrackPoint(p data.LoadedPoint, stats *targets.DataStats) {
+	pt := p.Data.(*point)
+	timeStr := strings.SplitN(pt.row.fields, ",", 2)[0]
+	ns, err := strconv.ParseInt(timeStr, 10, 64)
+	if err != nil {
+		fatal("cannot parse timestamp %s: %v", timeStr, err)
+		return
+	}
+	commonTagsLen := len(tableCols[tagsKey])
+	tags := strings.SplitN(pt.row.tags, ",", commonTagsLen+1)
+	if len(tags) > commonTagsLen {
+		tags = tags[:commonTagsLen]
+	}
+	// PostgreSQL stores timestamps with microsecond precision
+	stats.Add(pt.hypertable, time.Unix(0, ns).Truncate(time.Microsecond), strings.Join(tags, ","))
+}
+
+// Verify returns the number of rows, the time range and the number of tag
+// sets of each table
+func (d *dbCreator) Verify(dbName string, measurements []string) (map[string]targets.MeasurementStats, error) {
+	db, err := sql.Open(d.driver, d.opts.GetConnectString(dbName))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	stats := make(map[string]targets.MeasurementStats, len(measurements))
+	for _, table := range measurements {
+		var s targets.MeasurementStats
+		var minTime, maxTime sql.NullTime
+		err := db.QueryRow(fmt.Sprintf(verifySQLFmt, table)).Scan(&s.Rows, &minTime, &maxTime, &s.TagSets)
+		if err != nil {
+			return nil, fmt.Errorf("could not verify table %s: %v", table, err)
+		}
+		s.MinTime, s.MaxTime = minTime.Time, maxTime.Time
+		stats[table] = s
+	}
+	return stats, nil
+}
@@ -0,0 +1,84 @@
+package targets
+
+import (
+	"sort"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// MeasurementStats summarizes the rows of a single measurement (table)
+type MeasurementStats struct {
+	Rows    uint64
+	MinTime time.Time
+	MaxTime time.Time
+	// TagSets is the number of distinct tag sets, i.e. series
+	TagSets uint64
+}
+
+// DataStats tracks the MeasurementStats of the rows read from a DataSource.
+// It is not safe for concurrent use; the scanner is its only writer.
+type DataStats struct {
+	measurements map[string]*MeasurementStats
+	tagSets      map[string]map[string]struct{}
+}
+
+// NewDataStats returns DataStats without any rows
+func NewDataStats() *DataStats {
+	return &DataStats{
+		measurements: make(map[string]*MeasurementStats),
+		tagSets:      make(map[string]map[string]struct{}),
+	}
+}
+
+// Add adds a row of the measurement with the given timestamp and tag set
+func (s *DataStats) Add(measurement string, timestamp time.Time, tagSet string) {
+	m, ok := s.measurements[measurement]
+	if !ok {
+		m = &MeasurementStats{MinTime: timestamp, MaxTime: timestamp}
+		s.measurements[measurement] = m
+		s.tagSets[measurement] = make(map[string]struct{})
+	}
+	m.Rows++
+	if timestamp.Before(m.MinTime) {
+		m.MinTime = timestamp
+	}
+	if timestamp.After(m.MaxTime) {
+		m.MaxTime = timestamp
+	}
+	if _, ok := s.tagSets[measurement][tagSet]; !ok {
+		s.tagSets[measurement][tagSet] = struct{}{}
+		m.TagSets++
+	}
+}
+
+// Measurements returns the names of all measurements with rows, sorted
+func (s *DataStats) Measurements() []string {
+	names := make([]string, 0, len(s.measurements))
+	for name := range s.measurements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the stats of the measurement, which are empty if it has no rows
+func (s *DataStats) Get(measurement string) MeasurementStats {
+	if m, ok := s.measurements[measurement]; ok {
+		return *m
+	}
+	return MeasurementStats{}
+}
+
+// Verifier is a DBCreator that can check that the database holds the data read
+// from the DataSource, to surface rows dropped silently during the load.
+type Verifier interface {
+	DBCreator
+
+	// TrackPoint adds the rows of a point read from the DataSource to stats,
+	// with their timestamps at the precision the database stores them
+	TrackPoint(p data.LoadedPoint, stats *DataStats)
+
+	// Verify returns the stats of the given measurements stored in the database
+	Verify(dbName string, measurements []string) (map[string]MeasurementStats, error)
+}
@@ -0,0 +1,94 @@
+package victoriametrics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+const (
+	// apiWrite sends batches in the InfluxDB line protocol to /write
+	apiWrite = "write"
+	// apiImport converts batches to JSON lines and sends them to /api/v1/import
+	apiImport = "import"
+)
+
+// SpecificConfig holds the VictoriaMetrics specific loader configuration
+type SpecificConfig struct {
+	URLs    string        `yaml:"urls" mapstructure:"urls"`
+	API     string        `yaml:"api" mapstructure:"api"`
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// endpoints returns the ingestion endpoint of every configured URL
+func (c *SpecificConfig) endpoints() []string {
+	path := "/write"
+	if c.API == apiImport {
+		path = "/api/v1/import"
+	}
+	var endpoints []string
+	for _, u := range strings.Split(c.URLs, ",") {
+		u = strings.TrimSuffix(strings.TrimSpace(u), "/")
+		if u != "" {
+			endpoints = append(endpoints, u+path)
+		}
+	}
+	return endpoints
+}
+
+func NewBenchmark(config *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if len(config.endpoints()) == 0 {
+		return nil, errors.New("urls must be set")
+	}
+	if config.API != apiWrite && config.API != apiImport {
+		return nil, fmt.Errorf("unknown api '%s', expected '%s' or '%s'", config.API, apiWrite, apiImport)
+	}
+
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		ds = influx.NewFileDataSource(dataSourceConfig.File.Location, dataSourceConfig.File.ReadAhead)
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = influx.NewSimulationDataSource(simulator)
+	}
+
+	return &benchmark{config: config, ds: ds}, nil
+}
+
+type benchmark struct {
+	config *SpecificConfig
+	ds     targets.DataSource
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &influx.BatchFactory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	if maxPartitions > 1 {
+		return &influx.SeriesIndexer{Partitions: maxPartitions}
+	}
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{config: b.config}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{}
+}
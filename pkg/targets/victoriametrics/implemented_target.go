@@ -0,0 +1,46 @@
+package victoriametrics
+
+import (
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+func NewTarget() targets.ImplementedTarget {
+	return &victoriaMetricsTarget{}
+}
+
+type victoriaMetricsTarget struct {
+}
+
+func (t *victoriaMetricsTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"urls", "http://localhost:8428", "VictoriaMetrics URLs, comma-separated. Will be used in a round-robin fashion by the workers.")
+	flagSet.String(flagPrefix+"api", apiWrite, "Ingestion API to use: 'write' sends the line protocol to /write, 'import' sends JSON lines to /api/v1/import")
+	flagSet.Duration(flagPrefix+"timeout", 30*time.Second, "Timeout of a single write request")
+}
+
+func (t *victoriaMetricsTarget) TargetName() string {
+	return constants.FormatVictoriaMetrics
+}
+
+// Serializer returns the InfluxDB serializer, so the same generated dataset
+// can be loaded into InfluxDB, Datalayers and VictoriaMetrics.
+func (t *victoriaMetricsTarget) Serializer() serialize.PointSerializer {
+	return &influx.Serializer{}
+}
+
+func (t *victoriaMetricsTarget) Benchmark(
+	_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper,
+) (targets.Benchmark, error) {
+	var config SpecificConfig
+	if err := v.Unmarshal(&config); err != nil {
+		return nil, err
+	}
+	return NewBenchmark(&config, dataSourceConfig)
+}
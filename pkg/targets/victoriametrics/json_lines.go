@@ -0,0 +1,149 @@
+package victoriametrics
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+const (
+	nanosPerMs           = 1000000
+	hexDigits            = "0123456789abcdef"
+	errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
+	errFieldFmt          = "parse error: field '%s' is not in the form key=value"
+)
+
+var (
+	space      = []byte(" ")
+	comma      = []byte(",")
+	equals     = []byte("=")
+	trueValue  = []byte("true")
+	falseValue = []byte("false")
+)
+
+// linesToJSONLines converts lines of the InfluxDB line protocol into the JSON
+// lines format of /api/v1/import and appends them to dst. Every field becomes
+// its own series named <measurement>_<field>, the same naming VictoriaMetrics
+// uses for the /write endpoint, with the tags as labels, e.g.
+//
+//	cpu,hostname=host_0 usage_user=58.1,usage_system=2i 1451606400000000000
+//
+// becomes
+//
+//	{"metric":{"__name__":"cpu_usage_user","hostname":"host_0"},"values":[58.1],"timestamps":[1451606400000]}
+//	{"metric":{"__name__":"cpu_usage_system","hostname":"host_0"},"values":[2],"timestamps":[1451606400000]}
+//
+// String fields can not be stored and are skipped, booleans are stored as 1
+// and 0.
+func linesToJSONLines(dst, lines []byte) ([]byte, error) {
+	for len(lines) > 0 {
+		var line []byte
+		if idx := bytes.IndexByte(lines, '\n'); idx >= 0 {
+			line, lines = lines[:idx], lines[idx+1:]
+		} else {
+			line, lines = lines, nil
+		}
+		if len(line) == 0 {
+			continue
+		}
+		var err error
+		if dst, err = appendJSONLines(dst, line); err != nil {
+			return nil, err
+		}
+	}
+	return dst, nil
+}
+
+func appendJSONLines(dst, line []byte) ([]byte, error) {
+	args := bytes.Split(line, space)
+	if len(args) != 3 {
+		return nil, fmt.Errorf(errNotThreeTuplesFmt, len(args))
+	}
+	ts, err := strconv.ParseInt(string(args[2]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse error: invalid timestamp '%s': %v", args[2], err)
+	}
+	ts /= nanosPerMs
+
+	tags := bytes.Split(args[0], comma)
+	measurement := tags[0]
+	tags = tags[1:]
+
+	for _, field := range bytes.Split(args[1], comma) {
+		kv := bytes.SplitN(field, equals, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf(errFieldFmt, field)
+		}
+		value, ok, err := fieldValue(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("parse error: field '%s': %v", kv[0], err)
+		}
+		if !ok {
+			continue
+		}
+
+		dst = append(dst, `{"metric":{"__name__":`...)
+		dst = appendJSONString(dst, measurement, []byte("_"), kv[0])
+		for _, tag := range tags {
+			tkv := bytes.SplitN(tag, equals, 2)
+			if len(tkv) != 2 {
+				return nil, fmt.Errorf("parse error: tag '%s' is not in the form key=value", tag)
+			}
+			dst = append(dst, ',')
+			dst = appendJSONString(dst, tkv[0])
+			dst = append(dst, ':')
+			dst = appendJSONString(dst, tkv[1])
+		}
+		dst = append(dst, `},"values":[`...)
+		dst = append(dst, value...)
+		dst = append(dst, `],"timestamps":[`...)
+		dst = strconv.AppendInt(dst, ts, 10)
+		dst = append(dst, "]}\n"...)
+	}
+	return dst, nil
+}
+
+// fieldValue returns the field value of the line protocol as a JSON number.
+// ok is false for string fields.
+func fieldValue(v []byte) (value []byte, ok bool, err error) {
+	switch {
+	case len(v) == 0:
+		return nil, false, fmt.Errorf("empty value")
+	case v[0] == '"':
+		return nil, false, nil
+	case bytes.Equal(v, trueValue):
+		return []byte("1"), true, nil
+	case bytes.Equal(v, falseValue):
+		return []byte("0"), true, nil
+	case v[len(v)-1] == 'i':
+		v = v[:len(v)-1]
+		if _, err := strconv.ParseInt(string(v), 10, 64); err != nil {
+			return nil, false, err
+		}
+		return v, true, nil
+	default:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return nil, false, err
+		}
+		return strconv.AppendFloat(nil, f, 'g', -1, 64), true, nil
+	}
+}
+
+// appendJSONString appends the concatenation of parts as a quoted JSON string
+func appendJSONString(dst []byte, parts ...[]byte) []byte {
+	dst = append(dst, '"')
+	for _, part := range parts {
+		for _, c := range part {
+			switch {
+			case c == '"' || c == '\\':
+				dst = append(dst, '\\', c)
+			case c < 0x20:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			default:
+				dst = append(dst, c)
+			}
+		}
+	}
+	return append(dst, '"')
+}
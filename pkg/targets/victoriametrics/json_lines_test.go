@@ -0,0 +1,63 @@
+package victoriametrics
+
+import "testing"
+
+func TestLinesToJSONLines(t *testing.T) {
+	cases := []struct {
+		desc    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			desc:  "float and int fields",
+			input: "cpu,hostname=host_0,region=eu usage_user=58.5,usage_system=2i 1451606400000000000\n",
+			want: `{"metric":{"__name__":"cpu_usage_user","hostname":"host_0","region":"eu"},"values":[58.5],"timestamps":[1451606400000]}` + "\n" +
+				`{"metric":{"__name__":"cpu_usage_system","hostname":"host_0","region":"eu"},"values":[2],"timestamps":[1451606400000]}` + "\n",
+		},
+		{
+			desc:  "no tags, several lines",
+			input: "mem free=1 1000000\nmem free=2 2000000",
+			want: `{"metric":{"__name__":"mem_free"},"values":[1],"timestamps":[1]}` + "\n" +
+				`{"metric":{"__name__":"mem_free"},"values":[2],"timestamps":[2]}` + "\n",
+		},
+		{
+			desc:  "string fields are skipped, booleans are numbers",
+			input: `readings,name="truck_0" status="ok",moving=true,stopped=false 0`,
+			want: `{"metric":{"__name__":"readings_moving","name":"\"truck_0\""},"values":[1],"timestamps":[0]}` + "\n" +
+				`{"metric":{"__name__":"readings_stopped","name":"\"truck_0\""},"values":[0],"timestamps":[0]}` + "\n",
+		},
+		{
+			desc:    "missing timestamp",
+			input:   "cpu,hostname=host_0 usage_user=1",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid value",
+			input:   "cpu,hostname=host_0 usage_user=abc 0",
+			wantErr: true,
+		},
+		{
+			desc:    "invalid tag",
+			input:   "cpu,hostname usage_user=1 0",
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		got, err := linesToJSONLines(nil, []byte(c.input))
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+			continue
+		}
+		if string(got) != c.want {
+			t.Errorf("%s: incorrect output\ngot:\n%s\nwant:\n%s", c.desc, got, c.want)
+		}
+	}
+}
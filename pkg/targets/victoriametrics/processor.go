@@ -0,0 +1,86 @@
+package victoriametrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+// maxErrMsgLen limits how much of an error response body is reported
+const maxErrMsgLen = 256
+
+// processor sends the batches of a worker to one of the configured URLs,
+// either as line protocol or converted to JSON lines depending on the api.
+type processor struct {
+	config     *SpecificConfig
+	url        string
+	httpClient *http.Client
+	body       []byte
+}
+
+// Init picks the URL of the worker in a round-robin fashion
+func (p *processor) Init(workerNum int, doLoad, _ bool) {
+	if !doLoad {
+		return
+	}
+	endpoints := p.config.endpoints()
+	p.url = endpoints[workerNum%len(endpoints)]
+	p.httpClient = &http.Client{Timeout: p.config.Timeout}
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	res := p.ProcessBatchWithResult(b, doLoad)
+	if res.Err != nil {
+		fatal("%v", res.Err)
+	}
+	return res.Metrics, res.Rows
+}
+
+// ProcessBatchWithResult sends the batch in a single request, so the batch
+// is either written or failed as a whole
+func (p *processor) ProcessBatchWithResult(b targets.Batch, doLoad bool) targets.BatchResult {
+	batch := b.(*influx.Batch)
+	if !doLoad {
+		return targets.BatchResult{Metrics: batch.Metrics(), Rows: uint64(batch.Len())}
+	}
+
+	var res targets.BatchResult
+	body := batch.Bytes()
+	if p.config.API == apiImport {
+		var err error
+		p.body, err = linesToJSONLines(p.body[:0], body)
+		if err != nil {
+			err = fmt.Errorf("could not convert batch to JSON lines: %v", err)
+			res.Fail(batch.Metrics(), uint64(batch.Len()), err, targets.ErrorClassRejected)
+			return res
+		}
+		body = p.body
+	}
+	if err := p.post(body); err != nil {
+		err = fmt.Errorf("write to %s failed: %w", p.url, err)
+		res.Fail(batch.Metrics(), uint64(batch.Len()), err, targets.ClassifyError(err))
+		return res
+	}
+	res.Metrics, res.Rows = batch.Metrics(), uint64(batch.Len())
+	return res
+}
+
+func (p *processor) post(body []byte) error {
+	resp, err := p.httpClient.Post(p.url, "text/plain", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrMsgLen))
+		return &targets.HTTPStatusError{Status: resp.Status, StatusCode: resp.StatusCode, Body: string(bytes.TrimSpace(msg))}
+	}
+	// drain the body so the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,85 @@
+package victoriametrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+func TestProcessorProcessBatch(t *testing.T) {
+	lines := []string{
+		"cpu,hostname=host_0 usage_user=1,usage_system=2 1000000",
+		"cpu,hostname=host_1 usage_user=3,usage_system=4 1000000",
+	}
+	cases := []struct {
+		api      string
+		wantPath string
+		wantBody string
+	}{
+		{
+			api:      apiWrite,
+			wantPath: "/write",
+			wantBody: lines[0] + "\n" + lines[1] + "\n",
+		},
+		{
+			api:      apiImport,
+			wantPath: "/api/v1/import",
+			wantBody: `{"metric":{"__name__":"cpu_usage_user","hostname":"host_0"},"values":[1],"timestamps":[1]}` + "\n" +
+				`{"metric":{"__name__":"cpu_usage_system","hostname":"host_0"},"values":[2],"timestamps":[1]}` + "\n" +
+				`{"metric":{"__name__":"cpu_usage_user","hostname":"host_1"},"values":[3],"timestamps":[1]}` + "\n" +
+				`{"metric":{"__name__":"cpu_usage_system","hostname":"host_1"},"values":[4],"timestamps":[1]}` + "\n",
+		},
+	}
+
+	for _, c := range cases {
+		var mu sync.Mutex
+		var gotPath string
+		var gotBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			mu.Lock()
+			gotPath, gotBody = r.URL.Path, body
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		config := &SpecificConfig{URLs: server.URL + "/", API: c.api, Timeout: time.Second}
+		p := &processor{config: config}
+		p.Init(0, true, false)
+
+		b := (&influx.BatchFactory{}).New()
+		for _, l := range lines {
+			b.Append(data.NewLoadedPoint([]byte(l)))
+		}
+		metrics, rows := p.ProcessBatch(b, true)
+		server.Close()
+
+		if metrics != 4 || rows != 2 {
+			t.Errorf("%s: got %d metrics %d rows, want 4 metrics 2 rows", c.api, metrics, rows)
+		}
+		mu.Lock()
+		if gotPath != c.wantPath {
+			t.Errorf("%s: incorrect path: got %s want %s", c.api, gotPath, c.wantPath)
+		}
+		if !bytes.Equal(gotBody, []byte(c.wantBody)) {
+			t.Errorf("%s: incorrect body\ngot:\n%s\nwant:\n%s", c.api, gotBody, c.wantBody)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestSpecificConfigEndpoints(t *testing.T) {
+	config := &SpecificConfig{URLs: "http://a:8428, http://b:8428/", API: apiWrite}
+	got := config.endpoints()
+	want := []string{"http://a:8428/write", "http://b:8428/write"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("incorrect endpoints: got %v want %v", got, want)
+	}
+}